@@ -0,0 +1,56 @@
+package groute
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Accepts restricts the request content types the route accepts, e.g.
+// "application/json" or "text/*". Requests with a body of any other type
+// are rejected with 415 Unsupported Media Type before middlewares and the
+// handler run, and the response lists the supported types in its Accept header.
+func (rt *Route) Accepts(contentTypes ...string) *Route {
+	normalized := make([]string, len(contentTypes))
+	for i, ct := range contentTypes {
+		normalized[i] = strings.ToLower(strings.TrimSpace(ct))
+	}
+	rt.update(func(info *routeInfo) {
+		info.accepts = normalized
+	})
+	return rt
+}
+
+// AcceptedTypes returns the content types set with Accepts.
+func (rt *Route) AcceptedTypes() []string {
+	return append([]string(nil), rt.info.Load().accepts...)
+}
+
+// acceptsContentType reports whether the request's Content-Type is allowed.
+// Requests without a body are always allowed.
+func acceptsContentType(accepts []string, r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return r.ContentLength == 0 || r.Body == nil || r.Body == http.NoBody
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, accepted := range accepts {
+		if accepted == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(accepted, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// rejectContentType responds with 415 and the list of supported types.
+func rejectContentType(w http.ResponseWriter, r *http.Request, accepts []string) {
+	supported := strings.Join(accepts, ", ")
+	w.Header().Set("Accept", supported)
+	Error(w, r, NewStatusError(http.StatusUnsupportedMediaType, "unsupported media type, supported: "+supported))
+}
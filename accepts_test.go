@@ -0,0 +1,75 @@
+package groute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRouteAccepts(t *testing.T) {
+	tests := []struct {
+		name           string
+		contentType    string
+		body           string
+		expectedStatus int
+	}{
+		{"exact match", "application/json", `{}`, http.StatusOK},
+		{"match with parameters", "application/json; charset=utf-8", `{}`, http.StatusOK},
+		{"case insensitive", "Application/JSON", `{}`, http.StatusOK},
+		{"wildcard subtype", "text/csv", "a,b", http.StatusOK},
+		{"unsupported type", "application/xml", "<a/>", http.StatusUnsupportedMediaType},
+		{"missing type with body", "", "data", http.StatusUnsupportedMediaType},
+		{"missing type without body", "", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewRouter()
+			middlewareCalled := false
+			g.Use(func(next http.HandlerFunc) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					middlewareCalled = true
+					next(w, r)
+				}
+			})
+			g.Post("/items", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}).Accepts("application/json", "text/*")
+
+			req := httptest.NewRequest("POST", "/items", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedStatus == http.StatusUnsupportedMediaType {
+				if middlewareCalled {
+					t.Error("middleware should not run for unsupported media type")
+				}
+				if accept := w.Header().Get("Accept"); accept != "application/json, text/*" {
+					t.Errorf("expected Accept header to list supported types, got %q", accept)
+				}
+				if !strings.Contains(w.Body.String(), "application/json") {
+					t.Errorf("expected body to list supported types, got %q", w.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRouteAcceptedTypes(t *testing.T) {
+	g := NewRouter()
+	route := g.Post("/items", func(w http.ResponseWriter, r *http.Request) {})
+	if len(route.AcceptedTypes()) != 0 {
+		t.Error("expected no accepted types by default")
+	}
+	route.Accepts("application/json")
+	if types := route.AcceptedTypes(); len(types) != 1 || types[0] != "application/json" {
+		t.Errorf("unexpected accepted types: %v", types)
+	}
+}
@@ -0,0 +1,84 @@
+package groute
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// LogLevel overrides the access-log level for the route's non-5xx
+// responses, e.g. slog.LevelDebug for noisy endpoints or slog.LevelWarn for
+// sensitive ones. Server errors are still logged at error level.
+func (rt *Route) LogLevel(level slog.Level) *Route {
+	rt.update(func(info *routeInfo) {
+		info.logLevel = &level
+	})
+	return rt
+}
+
+// NoLog silences access logging for the route entirely, e.g. for health checks.
+func (rt *Route) NoLog() *Route {
+	rt.update(func(info *routeInfo) {
+		info.noLog = true
+	})
+	return rt
+}
+
+// AccessLog returns a middleware that logs one record per request.
+//
+// Responses with a 5xx status are logged at error level and everything else
+// at info level, unless the matched route overrides it with LogLevel or
+// NoLog. Requests abandoned by the client are logged with status 499 and
+// client_closed=true rather than as server errors.
+// If logger is nil, slog.Default() is used.
+func AccessLog(logger *slog.Logger) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			start := Now(r)
+			rw := WrapResponseWriter(w)
+			next(rw, r)
+
+			status := ResponseStatus(rw, r)
+			level := slog.LevelInfo
+			route, pattern := matchedRoute(r)
+			if route != nil {
+				info := route.info.Load()
+				if info.noLog {
+					return
+				}
+				if info.logLevel != nil {
+					level = *info.logLevel
+				}
+			}
+			if status >= 500 {
+				level = max(level, slog.LevelError)
+			}
+			if !logger.Enabled(r.Context(), level) {
+				return
+			}
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("pattern", pattern),
+				slog.Int("status", status),
+				slog.Int64("bytes", rw.Size()),
+				slog.Duration("duration", Now(r).Sub(start)),
+			}
+			if owner := route.RouteOwner(); owner != "" {
+				attrs = append(attrs, slog.String("owner", owner))
+			}
+			if routeAttrs := route.Attributes(); len(routeAttrs) > 0 {
+				attrs = append(attrs, slog.Attr{Key: "attributes", Value: slog.GroupValue(routeAttrs...)})
+			}
+			if Soft404(r) {
+				attrs = append(attrs, slog.Bool("soft_404", true))
+			}
+			if status == StatusClientClosedRequest {
+				attrs = append(attrs, slog.Bool("client_closed", true))
+			}
+			logger.LogAttrs(r.Context(), level, "request", attrs...)
+		}
+	}
+}
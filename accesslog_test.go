@@ -0,0 +1,135 @@
+package groute
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func decodeLogRecord(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("failed to decode log record %q: %v", buf.String(), err)
+	}
+	return record
+}
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	g := NewRouter()
+	g.Use(AccessLog(newTestLogger(&buf)))
+	g.Get("/user/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	})
+
+	req := httptest.NewRequest("GET", "/user/1", nil)
+	g.ServeHTTP(httptest.NewRecorder(), req)
+
+	record := decodeLogRecord(t, &buf)
+	if record["level"] != "INFO" {
+		t.Errorf("expected INFO level, got %v", record["level"])
+	}
+	if record["pattern"] != "GET /user/{id}" {
+		t.Errorf("expected matched pattern, got %v", record["pattern"])
+	}
+	if record["status"] != float64(200) {
+		t.Errorf("expected status 200, got %v", record["status"])
+	}
+	if record["bytes"] != float64(5) {
+		t.Errorf("expected 5 bytes, got %v", record["bytes"])
+	}
+}
+
+func TestAccessLogServerError(t *testing.T) {
+	var buf bytes.Buffer
+	g := NewRouter()
+	g.Use(AccessLog(newTestLogger(&buf)))
+	g.Get("/fail", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/fail", nil))
+
+	record := decodeLogRecord(t, &buf)
+	if record["level"] != "ERROR" {
+		t.Errorf("expected ERROR level, got %v", record["level"])
+	}
+}
+
+func TestAccessLogClientClosed(t *testing.T) {
+	var buf bytes.Buffer
+	g := NewRouter()
+	g.Use(AccessLog(newTestLogger(&buf)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g.Get("/slow", func(w http.ResponseWriter, r *http.Request) {
+		cancel() // client goes away mid-request
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest("GET", "/slow", nil).WithContext(ctx)
+	g.ServeHTTP(httptest.NewRecorder(), req)
+
+	record := decodeLogRecord(t, &buf)
+	if record["status"] != float64(StatusClientClosedRequest) {
+		t.Errorf("expected status 499, got %v", record["status"])
+	}
+	if record["client_closed"] != true {
+		t.Errorf("expected client_closed=true, got %v", record["client_closed"])
+	}
+	if record["level"] != "INFO" {
+		t.Errorf("client aborts should not be logged as errors, got %v", record["level"])
+	}
+}
+
+func TestAccessLogRouteLevel(t *testing.T) {
+	tests := []struct {
+		name          string
+		configure     func(*Route)
+		status        int
+		loggerLevel   slog.Level
+		expectedLevel string
+	}{
+		{"silenced", func(rt *Route) { rt.NoLog() }, http.StatusOK, slog.LevelDebug, ""},
+		{"silenced error", func(rt *Route) { rt.NoLog() }, http.StatusInternalServerError, slog.LevelDebug, ""},
+		{"debug", func(rt *Route) { rt.LogLevel(slog.LevelDebug) }, http.StatusOK, slog.LevelDebug, "DEBUG"},
+		{"debug filtered", func(rt *Route) { rt.LogLevel(slog.LevelDebug) }, http.StatusOK, slog.LevelInfo, ""},
+		{"raised", func(rt *Route) { rt.LogLevel(slog.LevelWarn) }, http.StatusOK, slog.LevelInfo, "WARN"},
+		{"errors stay errors", func(rt *Route) { rt.LogLevel(slog.LevelDebug) }, http.StatusBadGateway, slog.LevelInfo, "ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: tt.loggerLevel}))
+			g := NewRouter()
+			g.Use(AccessLog(logger))
+			route := g.Get("/x", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			})
+			tt.configure(route)
+
+			g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/x", nil))
+
+			if tt.expectedLevel == "" {
+				if buf.Len() != 0 {
+					t.Errorf("expected no log record, got %q", buf.String())
+				}
+				return
+			}
+			record := decodeLogRecord(t, &buf)
+			if record["level"] != tt.expectedLevel {
+				t.Errorf("expected level %s, got %v", tt.expectedLevel, record["level"])
+			}
+		})
+	}
+}
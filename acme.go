@@ -0,0 +1,81 @@
+package groute
+
+import (
+	"net/http"
+	"sync"
+)
+
+// ACMETokenStore provides the key authorizations of pending ACME HTTP-01
+// challenges. Implement it on top of your certificate manager's storage.
+type ACMETokenStore interface {
+	// KeyAuthorization returns the key authorization for token.
+	KeyAuthorization(token string) (string, bool)
+}
+
+// MemoryACMEStore is an in-memory ACMETokenStore, safe for concurrent use.
+type MemoryACMEStore struct {
+	mu     sync.RWMutex
+	tokens map[string]string
+}
+
+// NewMemoryACMEStore creates an empty MemoryACMEStore.
+func NewMemoryACMEStore() *MemoryACMEStore {
+	return &MemoryACMEStore{tokens: make(map[string]string)}
+}
+
+// Put records the key authorization for a pending challenge token.
+func (s *MemoryACMEStore) Put(token, keyAuthorization string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[token] = keyAuthorization
+}
+
+// Delete removes a token once its challenge is complete.
+func (s *MemoryACMEStore) Delete(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, token)
+}
+
+// KeyAuthorization implements ACMETokenStore.
+func (s *MemoryACMEStore) KeyAuthorization(token string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keyAuth, ok := s.tokens[token]
+	return keyAuth, ok
+}
+
+// ACMEChallenge registers GET /.well-known/acme-challenge/{token}, answering
+// HTTP-01 challenges from store. This lets external certificate managers
+// complete issuance through the application's own listener.
+// Unknown or malformed tokens receive 404 Not Found.
+func (g *Router) ACMEChallenge(store ACMETokenStore) *Route {
+	return g.WellKnown("acme-challenge/{token}", func(w http.ResponseWriter, r *http.Request) {
+		token := r.PathValue("token")
+		keyAuth, ok := "", validACMEToken(token)
+		if ok {
+			keyAuth, ok = store.KeyAuthorization(token)
+		}
+		if !ok {
+			Error(w, r, NewStatusError(http.StatusNotFound, "404 page not found"))
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(keyAuth))
+	})
+}
+
+// validACMEToken reports whether token uses the base64url alphabet (RFC 8555).
+func validACMEToken(token string) bool {
+	if token == "" {
+		return false
+	}
+	for _, c := range token {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+package groute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestACMEChallenge(t *testing.T) {
+	store := NewMemoryACMEStore()
+	store.Put("tok_en-1", "tok_en-1.thumbprint")
+
+	g := NewRouter()
+	g.Get("/{pathname...}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	g.ACMEChallenge(store)
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"known token", "/.well-known/acme-challenge/tok_en-1", http.StatusOK, "tok_en-1.thumbprint"},
+		{"unknown token", "/.well-known/acme-challenge/missing", http.StatusNotFound, ""},
+		{"malformed token", "/.well-known/acme-challenge/a.b", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedBody != "" && w.Body.String() != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+
+	store.Delete("tok_en-1")
+	req := httptest.NewRequest("GET", "/.well-known/acme-challenge/tok_en-1", nil)
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 after delete, got %d", w.Code)
+	}
+}
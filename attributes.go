@@ -0,0 +1,92 @@
+package groute
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// MetaAttributes is the metadata key of a route's observability attributes,
+// as a map[string]string; see Route.Attribute.
+const MetaAttributes = "attributes"
+
+// Attribute declares an observability attribute of the route, such as
+// api.tier=public or domain=billing. Attributes are added to access log
+// records, grouped under "attributes", and to ErrorEvents. They are listed
+// in RouteStats and exposed by Metrics as the labels of groute_route_info,
+// so dimensions are declared next to the routes they describe:
+//
+//	r.Post("/invoices", createInvoice).
+//		Attribute("domain", "billing").
+//		Attribute("api.tier", "public")
+//
+// Tracing middlewares can read them with Route.Attributes.
+func (rt *Route) Attribute(key, value string) *Route {
+	rt.update(func(info *routeInfo) {
+		old, _ := info.meta[MetaAttributes].(map[string]string)
+		attrs := make(map[string]string, len(old)+1)
+		maps.Copy(attrs, old)
+		attrs[key] = value
+		meta := make(map[string]any, len(info.meta)+1)
+		maps.Copy(meta, info.meta)
+		meta[MetaAttributes] = attrs
+		info.meta = meta
+	})
+	return rt
+}
+
+// Attributes returns the route's attributes sorted by key. It is safe to
+// call on a nil Route.
+func (rt *Route) Attributes() []slog.Attr {
+	attrs := rt.attributes()
+	list := make([]slog.Attr, 0, len(attrs))
+	for _, key := range slices.Sorted(maps.Keys(attrs)) {
+		list = append(list, slog.String(key, attrs[key]))
+	}
+	return list
+}
+
+// attributes returns the route's attribute map, which must not be modified.
+func (rt *Route) attributes() map[string]string {
+	v, _ := rt.Value(MetaAttributes)
+	attrs, _ := v.(map[string]string)
+	return attrs
+}
+
+// lintAttributes warns about attribute keys that map to the same Prometheus
+// label name, of which groute_route_info only exposes the first by key.
+func (rt *Route) lintAttributes() []string {
+	attrs := rt.attributes()
+	byName := map[string]string{"pattern": ""}
+	var warnings []string
+	for _, key := range slices.Sorted(maps.Keys(attrs)) {
+		name := promLabelName(key)
+		first, ok := byName[name]
+		switch {
+		case ok && first == "":
+			warnings = append(warnings, fmt.Sprintf("groute: attribute %q of route %q maps to the reserved metrics label %q; Metrics does not export it", key, rt.Pattern(), name))
+		case ok:
+			warnings = append(warnings, fmt.Sprintf("groute: attribute %q of route %q maps to metrics label %q like %q; Metrics does not export it", key, rt.Pattern(), name, first))
+		default:
+			byName[name] = key
+		}
+	}
+	return warnings
+}
+
+// promLabelName turns an attribute key into a Prometheus label name,
+// replacing characters other than letters, digits and underscores.
+func promLabelName(key string) string {
+	name := strings.Map(func(c rune) rune {
+		if c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' {
+			return c
+		}
+		return '_'
+	}, key)
+	if name == "" || '0' <= name[0] && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
@@ -0,0 +1,120 @@
+package groute
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRouteAttributes(t *testing.T) {
+	metrics := NewMetrics()
+	var logs bytes.Buffer
+	var events []ErrorEvent
+	g := NewRouter()
+	g.SetErrorReporter(ErrorReporterFunc(func(r *http.Request, event ErrorEvent) {
+		events = append(events, event)
+	}))
+	g.Use(metrics.Middleware(), AccessLog(slog.New(slog.NewTextHandler(&logs, nil))))
+	invoices := g.Post("/invoices", func(w http.ResponseWriter, r *http.Request) {
+		Error(w, r, errors.New("ledger down"))
+	}).Attribute("domain", "billing").Attribute("api.tier", "public").Attribute("status", "beta")
+	g.Get("/health", func(w http.ResponseWriter, r *http.Request) {})
+
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/invoices", nil))
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/health", nil))
+
+	attrs := invoices.Attributes()
+	if len(attrs) != 3 || attrs[0].String() != "api.tier=public" || attrs[1].String() != "domain=billing" {
+		t.Errorf("Attributes() = %v, want [api.tier=public domain=billing status=beta]", attrs)
+	}
+	if got := (*Route)(nil).Attributes(); len(got) != 0 {
+		t.Errorf("nil route Attributes() = %v, want none", got)
+	}
+	if len(events) != 1 || events[0].Attributes["domain"] != "billing" {
+		t.Fatalf("events = %+v, want one with domain=billing", events)
+	}
+	if !strings.Contains(logs.String(), "status=500 bytes=") || !strings.Contains(logs.String(), "attributes.api.tier=public attributes.domain=billing attributes.status=beta") {
+		t.Errorf("access log missing the attributes:\n%s", logs.String())
+	}
+
+	// Callers get copies of the route's attributes.
+	events[0].Attributes["domain"] = "changed"
+	for _, s := range metrics.Snapshot() {
+		if s.Attributes != nil {
+			s.Attributes["domain"] = "changed"
+		}
+	}
+	for _, route := range g.Manifest().Routes {
+		if attrs, ok := route.Meta[MetaAttributes].(map[string]string); ok {
+			attrs["domain"] = "changed"
+		}
+	}
+	if got := invoices.attributes()["domain"]; got != "billing" {
+		t.Errorf("domain = %q after editing copies, want billing", got)
+	}
+
+	for _, s := range metrics.Snapshot() {
+		if want := s.Pattern == "POST /invoices"; (s.Attributes["api.tier"] == "public") != want {
+			t.Errorf("stats of %s have attributes %v", s.Pattern, s.Attributes)
+		}
+	}
+	var b strings.Builder
+	metrics.WritePrometheus(&b)
+	if line := `groute_route_info{pattern="POST /invoices",api_tier="public",domain="billing",status="beta"} 1`; !strings.Contains(b.String(), line) {
+		t.Errorf("expected output to contain %q, got:\n%s", line, b.String())
+	}
+	if strings.Contains(b.String(), `groute_route_info{pattern="GET /health"`) {
+		t.Errorf("route without attributes exposed in groute_route_info")
+	}
+}
+
+func TestPromLabelName(t *testing.T) {
+	tests := map[string]string{
+		"domain":   "domain",
+		"api.tier": "api_tier",
+		"http-1":   "http_1",
+		"1st":      "_1st",
+		"":         "_",
+	}
+	for key, want := range tests {
+		if got := promLabelName(key); got != want {
+			t.Errorf("promLabelName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestAttributeConcurrent(t *testing.T) {
+	route := NewRouter().Get("/x", func(w http.ResponseWriter, r *http.Request) {})
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Go(func() { route.Attribute(fmt.Sprint("k", i), "v") })
+	}
+	wg.Wait()
+	if n := len(route.Attributes()); n != 50 {
+		t.Errorf("got %d attributes, want 50", n)
+	}
+}
+
+func TestLintAttributeLabels(t *testing.T) {
+	g := NewRouter()
+	g.Get("/x", func(w http.ResponseWriter, r *http.Request) {}).
+		Attribute("api.tier", "public").
+		Attribute("api_tier", "internal").
+		Attribute("pattern", "p").
+		Attribute("domain", "billing")
+
+	warnings := g.Lint()
+	if len(warnings) != 2 {
+		t.Fatalf("Lint() = %q, want 2 warnings", warnings)
+	}
+	if !strings.Contains(warnings[0], `"api_tier" of route "GET /x" maps to metrics label "api_tier" like "api.tier"`) ||
+		!strings.Contains(warnings[1], `"pattern" of route "GET /x" maps to the reserved metrics label "pattern"`) {
+		t.Errorf("Lint() = %q", warnings)
+	}
+}
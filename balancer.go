@@ -0,0 +1,206 @@
+package groute
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BalanceMode selects how a ReverseProxy spreads requests over its
+// upstreams.
+type BalanceMode int
+
+const (
+	// RoundRobin sends requests to each upstream in turn.
+	RoundRobin BalanceMode = iota
+	// LeastConnections sends requests to the upstream with the fewest
+	// requests in flight.
+	LeastConnections
+)
+
+// defaultFailTimeout is how long upstreams are ejected when
+// ReverseProxy.FailTimeout is unset.
+const defaultFailTimeout = 10 * time.Second
+
+// UpstreamStats is a snapshot of an upstream's metrics.
+type UpstreamStats struct {
+	URL string `json:"url"`
+	// Requests and Failures count the requests sent to the upstream and
+	// those that failed to connect, timed out or got a 502, 503 or 504.
+	Requests uint64 `json:"requests"`
+	Failures uint64 `json:"failures"`
+	// Active is the number of requests in flight, including responses
+	// still being streamed.
+	Active int64 `json:"active"`
+	// Ejections counts how often the upstream was ejected, and Ejected
+	// whether it currently is.
+	Ejections uint64 `json:"ejections"`
+	Ejected   bool   `json:"ejected"`
+}
+
+// upstream is one target of a ReverseProxy.
+type upstream struct {
+	index  int
+	target *url.URL
+
+	requests  atomic.Uint64
+	failures  atomic.Uint64
+	active    atomic.Int64
+	ejections atomic.Uint64
+
+	mu           sync.Mutex
+	fails        int // consecutive
+	ejectedUntil time.Time
+}
+
+// ejected reports whether u is ejected at now.
+func (u *upstream) ejected(now time.Time) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return now.Before(u.ejectedUntil)
+}
+
+// Upstreams returns the metrics of the proxy's upstreams, in the order they
+// were passed to Proxy.
+func (p *ReverseProxy) Upstreams() []UpstreamStats {
+	now := p.now()
+	stats := make([]UpstreamStats, len(p.upstreams))
+	for i, u := range p.upstreams {
+		stats[i] = UpstreamStats{
+			URL:       u.target.String(),
+			Requests:  u.requests.Load(),
+			Failures:  u.failures.Load(),
+			Active:    u.active.Load(),
+			Ejections: u.ejections.Load(),
+			Ejected:   u.ejected(now),
+		}
+	}
+	return stats
+}
+
+// StatsHandler returns a handler serving Upstreams as JSON, for mounting on
+// an internal debug route.
+func (p *ReverseProxy) StatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = JSON(w, r, http.StatusOK, p.Upstreams())
+	})
+}
+
+// pick selects the upstream for the next attempt of a request, scanning from
+// the request's turn in the rotation. Upstreams not tried yet are preferred
+// over healthy ones, so retries move on; when every upstream is ejected the
+// proxy keeps sending to them rather than failing outright.
+func (p *ReverseProxy) pick(turn uint64, tried []bool) *upstream {
+	now := p.now()
+	n := uint64(len(p.upstreams))
+	start := turn % n
+	var best *upstream
+	bestRank := -1
+	for i := uint64(0); i < n; i++ {
+		u := p.upstreams[(start+i)%n]
+		rank := 0
+		if !tried[u.index] {
+			rank += 2
+		}
+		if !u.ejected(now) {
+			rank++
+		}
+		if rank > bestRank || rank == bestRank && p.Balance == LeastConnections && u.active.Load() < best.active.Load() {
+			best, bestRank = u, rank
+		}
+	}
+	return best
+}
+
+// send sends req to u and records the outcome.
+func (p *ReverseProxy) send(transport http.RoundTripper, u *upstream, req *http.Request) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	(&httputil.ProxyRequest{Out: out}).SetURL(u.target)
+
+	u.requests.Add(1)
+	u.active.Add(1)
+	resp, err := transport.RoundTrip(out)
+	switch {
+	case err != nil:
+		u.active.Add(-1)
+		// A client going away says nothing about the upstream.
+		if context.Cause(req.Context()) != context.Canceled {
+			p.record(u, false)
+		}
+		return nil, err
+	case resp.StatusCode == http.StatusBadGateway,
+		resp.StatusCode == http.StatusServiceUnavailable,
+		resp.StatusCode == http.StatusGatewayTimeout:
+		p.record(u, false)
+	default:
+		p.record(u, true)
+	}
+
+	body := &upstreamBody{ReadCloser: resp.Body, done: func() { u.active.Add(-1) }}
+	if _, ok := resp.Body.(io.ReadWriteCloser); ok && resp.StatusCode == http.StatusSwitchingProtocols {
+		resp.Body = upstreamConn{body}
+	} else {
+		resp.Body = body
+	}
+	return resp, nil
+}
+
+// record updates u's health after a request.
+func (p *ReverseProxy) record(u *upstream, ok bool) {
+	if !ok {
+		u.failures.Add(1)
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if ok {
+		u.fails = 0
+		return
+	}
+	u.fails++
+	if p.MaxFails > 0 && u.fails >= p.MaxFails {
+		timeout := p.FailTimeout
+		if timeout <= 0 {
+			timeout = defaultFailTimeout
+		}
+		u.fails = 0
+		u.ejectedUntil = p.now().Add(timeout)
+		u.ejections.Add(1)
+	}
+}
+
+// now returns the current time according to p.Clock.
+func (p *ReverseProxy) now() time.Time {
+	if p.Clock != nil {
+		return p.Clock.Now()
+	}
+	return time.Now()
+}
+
+// upstreamBody ends an upstream request's active period when the response
+// body is closed.
+type upstreamBody struct {
+	io.ReadCloser
+	done func()
+	once sync.Once
+}
+
+func (b *upstreamBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
+
+// upstreamConn is the body of a 101 Switching Protocols response, which the
+// proxy also writes to.
+type upstreamConn struct {
+	*upstreamBody
+}
+
+func (c upstreamConn) Write(p []byte) (int, error) {
+	return c.ReadCloser.(io.Writer).Write(p)
+}
@@ -0,0 +1,180 @@
+package groute
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// namedUpstream starts a server answering with its name in X-Upstream.
+func namedUpstream(t *testing.T, name string, status int) *httptest.Server {
+	t.Helper()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Upstream", name)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// proxyGet sends a GET through p and returns the responding upstream.
+func proxyGet(p *ReverseProxy) (string, int) {
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	return w.Header().Get("X-Upstream"), w.Code
+}
+
+func TestProxyNoTargets(t *testing.T) {
+	if _, err := Proxy(); err == nil {
+		t.Error("Proxy() should fail")
+	}
+}
+
+func TestProxyRoundRobin(t *testing.T) {
+	a := namedUpstream(t, "a", http.StatusOK)
+	b := namedUpstream(t, "b", http.StatusOK)
+	c := namedUpstream(t, "c", http.StatusOK)
+	p, err := Proxy(a.URL, b.URL, c.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for i := 0; i < 6; i++ {
+		name, _ := proxyGet(p)
+		got = append(got, name)
+	}
+	if strings.Join(got, "") != "abcabc" {
+		t.Errorf("upstreams = %v, want a b c a b c", got)
+	}
+	for _, s := range p.Upstreams() {
+		if s.Requests != 2 || s.Active != 0 {
+			t.Errorf("%s: requests = %d, active = %d, want 2, 0", s.URL, s.Requests, s.Active)
+		}
+	}
+}
+
+func TestProxyLeastConnections(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Upstream", "slow")
+		w.WriteHeader(http.StatusOK)
+		http.NewResponseController(w).Flush()
+		close(started)
+		<-release
+	}))
+	defer slow.Close()
+	fast := namedUpstream(t, "fast", http.StatusOK)
+
+	p, err := Proxy(slow.URL, fast.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Balance = LeastConnections
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		proxyGet(p)
+	}()
+	<-started
+
+	for i := 0; i < 3; i++ {
+		if name, _ := proxyGet(p); name != "fast" {
+			t.Errorf("request %d went to %q, want fast", i, name)
+		}
+	}
+	if active := p.Upstreams()[0].Active; active != 1 {
+		t.Errorf("slow active = %d, want 1", active)
+	}
+	close(release)
+	<-done
+	if active := p.Upstreams()[0].Active; active != 0 {
+		t.Errorf("slow active after release = %d, want 0", active)
+	}
+}
+
+func TestProxyEjection(t *testing.T) {
+	bad := namedUpstream(t, "bad", http.StatusServiceUnavailable)
+	good := namedUpstream(t, "good", http.StatusOK)
+	clock := newFakeClock()
+	p, err := Proxy(bad.URL, good.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.MaxFails = 2
+	p.FailTimeout = time.Minute
+	p.Clock = clock
+
+	for i := 0; i < 4; i++ {
+		proxyGet(p)
+	}
+	stats := p.Upstreams()[0]
+	if !stats.Ejected || stats.Failures != 2 || stats.Ejections != 1 {
+		t.Errorf("bad upstream = %+v, want ejected after 2 failures", stats)
+	}
+	for i := 0; i < 4; i++ {
+		if name, code := proxyGet(p); name != "good" || code != http.StatusOK {
+			t.Errorf("ejected: got %q %d, want good 200", name, code)
+		}
+	}
+
+	clock.Advance(time.Minute)
+	seen := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		name, _ := proxyGet(p)
+		seen[name] = true
+	}
+	if !seen["bad"] {
+		t.Error("upstream not restored after FailTimeout")
+	}
+}
+
+func TestProxyRetryOtherUpstream(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	down := "http://" + ln.Addr().String()
+	ln.Close()
+	good := namedUpstream(t, "good", http.StatusOK)
+
+	p, err := Proxy(down, good.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.ConnectRetries = 1
+
+	for i := 0; i < 4; i++ {
+		if name, code := proxyGet(p); name != "good" || code != http.StatusOK {
+			t.Errorf("request %d: got %q %d, want good 200", i, name, code)
+		}
+	}
+	if failures := p.Upstreams()[0].Failures; failures != 2 {
+		t.Errorf("down failures = %d, want 2", failures)
+	}
+}
+
+func TestProxyStatsHandler(t *testing.T) {
+	a := namedUpstream(t, "a", http.StatusOK)
+	p, err := Proxy(a.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxyGet(p)
+
+	w := httptest.NewRecorder()
+	p.StatsHandler().ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	var stats []UpstreamStats
+	if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 1 || stats[0].URL != a.URL || stats[0].Requests != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
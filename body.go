@@ -0,0 +1,137 @@
+package groute
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+)
+
+// ErrBodyNotBuffered is returned by RewindBody and BodyBytes when the request
+// body was not buffered by the BufferBody middleware.
+var ErrBodyNotBuffered = errors.New("groute: request body is not buffered")
+
+// BufferBody returns a middleware that buffers request bodies so several
+// middlewares (signature verification, auditing, binding) and the handler
+// can each read them.
+//
+// Bodies up to memLimit bytes are kept in memory; larger bodies spill to a
+// temporary file that is removed when the request completes. Bodies larger
+// than maxBytes are rejected with 413 Request Entity Too Large; a maxBytes
+// of zero or less means no limit.
+//
+// After reading, call RewindBody to let the next reader start from the
+// beginning, or use BodyBytes which rewinds automatically.
+func BufferBody(memLimit, maxBytes int64) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Body == nil || r.Body == http.NoBody {
+				next(w, r)
+				return
+			}
+			body, err := newBufferedBody(r.Body, memLimit, maxBytes)
+			if err != nil {
+				Error(w, r, err)
+				return
+			}
+			defer body.cleanup()
+
+			r.Body = body
+			r.GetBody = body.reopen
+			next(w, r)
+		}
+	}
+}
+
+// RewindBody resets a buffered request body to its beginning.
+func RewindBody(r *http.Request) error {
+	body, ok := r.Body.(*bufferedBody)
+	if !ok {
+		return ErrBodyNotBuffered
+	}
+	_, err := body.Seek(0, io.SeekStart)
+	return err
+}
+
+// BodyBytes reads the whole buffered request body and rewinds it, leaving
+// r.Body ready for the next reader.
+func BodyBytes(r *http.Request) ([]byte, error) {
+	if err := RewindBody(r); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	return data, RewindBody(r)
+}
+
+// bufferedBody is a rewindable request body held in memory or a temp file.
+type bufferedBody struct {
+	io.ReadSeeker
+	data []byte
+	file *os.File
+}
+
+// newBufferedBody reads src fully, spilling to a temporary file past memLimit.
+func newBufferedBody(src io.ReadCloser, memLimit, maxBytes int64) (*bufferedBody, error) {
+	defer src.Close()
+	tooLarge := NewStatusError(http.StatusRequestEntityTooLarge, "request body too large")
+
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, io.LimitReader(src, memLimit+1))
+	if err != nil {
+		return nil, err
+	}
+	if maxBytes > 0 && n > maxBytes {
+		return nil, tooLarge
+	}
+	if n <= memLimit {
+		return &bufferedBody{ReadSeeker: bytes.NewReader(buf.Bytes()), data: buf.Bytes()}, nil
+	}
+
+	f, err := os.CreateTemp("", "groute-body-*")
+	if err != nil {
+		return nil, err
+	}
+	body := &bufferedBody{ReadSeeker: f, file: f}
+	rest := io.Reader(src)
+	if maxBytes > 0 {
+		rest = io.LimitReader(src, maxBytes-n+1)
+	}
+	m, err := io.Copy(f, io.MultiReader(&buf, rest))
+	if err == nil && maxBytes > 0 && m > maxBytes {
+		err = tooLarge
+	}
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		body.cleanup()
+		return nil, err
+	}
+	return body, nil
+}
+
+// Close implements io.Closer. The buffer stays readable so later readers can
+// rewind; resources are released when the request completes.
+func (b *bufferedBody) Close() error {
+	return nil
+}
+
+// reopen returns an independent reader over the body, for http.Request.GetBody.
+func (b *bufferedBody) reopen() (io.ReadCloser, error) {
+	if b.file == nil {
+		return io.NopCloser(bytes.NewReader(b.data)), nil
+	}
+	return os.Open(b.file.Name())
+}
+
+// cleanup removes the temporary file, if any.
+func (b *bufferedBody) cleanup() {
+	if b.file != nil {
+		_ = b.file.Close()
+		_ = os.Remove(b.file.Name())
+	}
+}
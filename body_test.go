@@ -0,0 +1,103 @@
+package groute
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestBufferBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		memLimit int64
+		body     string
+	}{
+		{"in memory", 1024, "hello world"},
+		{"spilled to file", 4, "hello world"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewRouter()
+			var audited, handled, reopened string
+			var tempFile string
+
+			g.Use(BufferBody(tt.memLimit, 1024))
+			g.Use(func(next http.HandlerFunc) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					data, err := BodyBytes(r)
+					if err != nil {
+						t.Errorf("BodyBytes failed: %v", err)
+					}
+					audited = string(data)
+					if f, ok := r.Body.(*bufferedBody); ok && f.file != nil {
+						tempFile = f.file.Name()
+					}
+					next(w, r)
+				}
+			})
+			g.Post("/upload", func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				handled = string(data)
+				body, err := r.GetBody()
+				if err != nil {
+					t.Errorf("GetBody failed: %v", err)
+					return
+				}
+				defer body.Close()
+				data, _ = io.ReadAll(body)
+				reopened = string(data)
+			})
+
+			req := httptest.NewRequest("POST", "/upload", strings.NewReader(tt.body))
+			g.ServeHTTP(httptest.NewRecorder(), req)
+
+			if audited != tt.body {
+				t.Errorf("expected middleware to read %q, got %q", tt.body, audited)
+			}
+			if handled != tt.body {
+				t.Errorf("expected handler to read %q, got %q", tt.body, handled)
+			}
+			if reopened != tt.body {
+				t.Errorf("expected GetBody to read %q, got %q", tt.body, reopened)
+			}
+			if tempFile != "" {
+				if _, err := os.Stat(tempFile); !os.IsNotExist(err) {
+					t.Errorf("expected temp file %s to be removed", tempFile)
+				}
+			}
+		})
+	}
+}
+
+func TestBufferBodyTooLarge(t *testing.T) {
+	for _, memLimit := range []int64{4, 1024} {
+		g := NewRouter()
+		called := false
+		g.Use(BufferBody(memLimit, 8))
+		g.Post("/upload", func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		})
+
+		req := httptest.NewRequest("POST", "/upload", strings.NewReader("0123456789"))
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, req)
+
+		if called {
+			t.Errorf("memLimit %d: handler should not be called for oversized body", memLimit)
+		}
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("memLimit %d: expected status 413, got %d", memLimit, w.Code)
+		}
+	}
+}
+
+func TestRewindBodyNotBuffered(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", strings.NewReader("x"))
+	if err := RewindBody(req); err != ErrBodyNotBuffered {
+		t.Errorf("expected ErrBodyNotBuffered, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package groute
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Breaker configures a route's circuit breaker. The circuit opens when the
+// share of failed requests, those answered with a 5xx status, reaches
+// Threshold; while open, requests get the Fallback instead of the handler.
+type Breaker struct {
+	// Threshold is the failure ratio, between 0 and 1, that opens the
+	// circuit. Zero never opens it.
+	Threshold float64
+	// MinRequests is how many requests a window needs before the circuit
+	// may open. Defaults to 10.
+	MinRequests int
+	// Window is the period failures are counted over. Defaults to 10 seconds.
+	Window time.Duration
+	// Cooldown is how long the circuit stays open before a trial request
+	// is let through. Defaults to 30 seconds.
+	Cooldown time.Duration
+	// Fallback answers requests while the circuit is open, e.g. with a
+	// cached response. Route middlewares do not run for it. Defaults to
+	// 503 Service Unavailable through the route's error handler.
+	Fallback http.HandlerFunc
+}
+
+// BreakerState is the state of a circuit breaker.
+type BreakerState int
+
+const (
+	// BreakerClosed lets requests through.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen serves the fallback.
+	BreakerOpen
+	// BreakerHalfOpen lets a single trial request through; its outcome
+	// closes or reopens the circuit.
+	BreakerHalfOpen
+)
+
+// String returns the name of the state.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// CircuitBreaker guards the route with a circuit breaker tripping on its
+// own error rate, so a failing handler sheds load instead of piling up
+// errors:
+//
+//	r.Get("/recommendations", recommend).CircuitBreaker(groute.Breaker{
+//		Threshold: 0.5,
+//		Fallback:  serveDefaultRecommendations,
+//	})
+//
+// Requests abandoned by the client are not counted. Time is read from the
+// router's Clock.
+func (rt *Route) CircuitBreaker(b Breaker) *Route {
+	if b.MinRequests <= 0 {
+		b.MinRequests = 10
+	}
+	if b.Window <= 0 {
+		b.Window = 10 * time.Second
+	}
+	if b.Cooldown <= 0 {
+		b.Cooldown = 30 * time.Second
+	}
+	cb := &circuitBreaker{config: b}
+	rt.update(func(info *routeInfo) {
+		info.breaker = cb
+	})
+	return rt
+}
+
+// BreakerState returns the state of the route's circuit breaker, or
+// BreakerClosed if it has none.
+func (rt *Route) BreakerState() BreakerState {
+	cb := rt.info.Load().breaker
+	if cb == nil {
+		return BreakerClosed
+	}
+	return cb.state(rt.now())
+}
+
+// circuitBreaker is the state of a route's breaker.
+type circuitBreaker struct {
+	config Breaker
+
+	mu          sync.Mutex
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	open        bool
+	trial       bool
+}
+
+// serve handles r with next, or with the fallback while the circuit is open.
+func (cb *circuitBreaker) serve(w http.ResponseWriter, r *http.Request, next http.Handler, now func() time.Time) {
+	trial, ok := cb.allow(now())
+	if !ok {
+		if cb.config.Fallback != nil {
+			cb.config.Fallback(w, r)
+			return
+		}
+		Error(w, r, NewStatusError(http.StatusServiceUnavailable, "circuit open"))
+		return
+	}
+	rw := WrapResponseWriter(w)
+	completed := false
+	defer func() {
+		// A panicking handler counts as failed, so a trial cannot stay
+		// pending; the panic carries on to Recovery or net/http.
+		if !completed {
+			cb.record(true, trial, now())
+		}
+	}()
+	next.ServeHTTP(rw, r)
+	completed = true
+	if status := ResponseStatus(rw, r); status != StatusClientClosedRequest {
+		cb.record(status >= 500, trial, now())
+	} else if trial {
+		cb.mu.Lock()
+		cb.trial = false
+		cb.mu.Unlock()
+	}
+}
+
+// allow reports whether a request may go through at now, and whether it is
+// the trial request of a half-open circuit.
+func (cb *circuitBreaker) allow(now time.Time) (trial, ok bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if !cb.open {
+		return false, true
+	}
+	if now.Sub(cb.openedAt) < cb.config.Cooldown || cb.trial {
+		return false, false
+	}
+	cb.trial = true
+	return true, true
+}
+
+// record counts the outcome of a request that went through.
+func (cb *circuitBreaker) record(failed, trial bool, now time.Time) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if trial {
+		cb.trial = false
+		if failed {
+			cb.openedAt = now
+		} else {
+			cb.open = false
+			cb.windowStart, cb.requests, cb.failures = now, 0, 0
+		}
+		return
+	}
+	if cb.open {
+		return
+	}
+	if now.Sub(cb.windowStart) >= cb.config.Window {
+		cb.windowStart, cb.requests, cb.failures = now, 0, 0
+	}
+	cb.requests++
+	if failed {
+		cb.failures++
+	}
+	if cb.config.Threshold > 0 && cb.requests >= cb.config.MinRequests && float64(cb.failures) >= cb.config.Threshold*float64(cb.requests) {
+		cb.open = true
+		cb.openedAt = now
+	}
+}
+
+// state returns the breaker's state at now.
+func (cb *circuitBreaker) state(now time.Time) BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	switch {
+	case !cb.open:
+		return BreakerClosed
+	case cb.trial || now.Sub(cb.openedAt) >= cb.config.Cooldown:
+		return BreakerHalfOpen
+	}
+	return BreakerOpen
+}
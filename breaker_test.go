@@ -0,0 +1,131 @@
+package groute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	clock := newFakeClock()
+	failing := true
+	calls := 0
+	g := NewRouter()
+	g.SetClock(clock)
+	route := g.Get("/recommendations", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if failing {
+			Error(w, r, NewStatusError(http.StatusInternalServerError, "backend down"))
+			return
+		}
+		w.Write([]byte("fresh"))
+	}).CircuitBreaker(Breaker{
+		Threshold:   0.5,
+		MinRequests: 4,
+		Cooldown:    time.Minute,
+		Fallback: func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("cached"))
+		},
+	})
+
+	get := func() (int, string) {
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, httptest.NewRequest("GET", "/recommendations", nil))
+		return w.Code, w.Body.String()
+	}
+
+	for range 4 {
+		if code, _ := get(); code != http.StatusInternalServerError {
+			t.Fatalf("closed circuit: status %d, want 500", code)
+		}
+	}
+	if s := route.BreakerState(); s != BreakerOpen {
+		t.Fatalf("state = %v, want open", s)
+	}
+	if code, body := get(); code != http.StatusOK || body != "cached" || calls != 4 {
+		t.Errorf("open circuit: %d %q after %d calls, want the fallback", code, body, calls)
+	}
+
+	// A failed trial reopens the circuit.
+	clock.Advance(time.Minute)
+	if s := route.BreakerState(); s != BreakerHalfOpen {
+		t.Errorf("state = %v, want half-open", s)
+	}
+	if code, _ := get(); code != http.StatusInternalServerError {
+		t.Errorf("trial: status %d, want 500", code)
+	}
+	if _, body := get(); body != "cached" {
+		t.Errorf("after failed trial: %q, want the fallback", body)
+	}
+
+	// A successful trial closes it.
+	failing = false
+	clock.Advance(time.Minute)
+	if _, body := get(); body != "fresh" {
+		t.Errorf("trial: %q, want the handler", body)
+	}
+	if s := route.BreakerState(); s != BreakerClosed {
+		t.Errorf("state = %v, want closed", s)
+	}
+}
+
+func TestCircuitBreakerDefaultFallback(t *testing.T) {
+	g := NewRouter()
+	g.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}).CircuitBreaker(Breaker{Threshold: 1, MinRequests: 1})
+
+	codes := make([]int, 2)
+	for i := range codes {
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		codes[i] = w.Code
+	}
+	if codes[0] != http.StatusBadGateway || codes[1] != http.StatusServiceUnavailable {
+		t.Errorf("codes = %v, want [502 503]", codes)
+	}
+}
+
+func TestCircuitBreakerPanickingTrial(t *testing.T) {
+	clock := newFakeClock()
+	panicking := true
+	g := NewRouter()
+	g.SetClock(clock)
+	route := g.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		if panicking {
+			panic("boom")
+		}
+		w.Write([]byte("ok"))
+	}).CircuitBreaker(Breaker{Threshold: 1, MinRequests: 1, Cooldown: time.Minute})
+
+	get := func() (code int) {
+		defer func() {
+			if recover() != nil {
+				code = http.StatusInternalServerError
+			}
+		}()
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		return w.Code
+	}
+
+	get()
+	if s := route.BreakerState(); s != BreakerOpen {
+		t.Fatalf("after panic: state = %v, want open", s)
+	}
+	clock.Advance(time.Minute)
+	get() // the trial panics too
+	if s := route.BreakerState(); s != BreakerOpen {
+		t.Errorf("after panicking trial: state = %v, want open", s)
+	}
+
+	panicking = false
+	clock.Advance(time.Minute)
+	if code := get(); code != http.StatusOK {
+		t.Errorf("next trial: status %d, want 200", code)
+	}
+	if s := route.BreakerState(); s != BreakerClosed {
+		t.Errorf("state = %v, want closed", s)
+	}
+}
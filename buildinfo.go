@@ -0,0 +1,90 @@
+package groute
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BuildInfo describes the running binary, as recorded by the Go toolchain.
+type BuildInfo struct {
+	// Module is the main module path.
+	Module string `json:"module,omitempty"`
+	// Version is the main module version, "(devel)" for local builds.
+	Version string `json:"version,omitempty"`
+	// Revision is the VCS revision the binary was built from.
+	Revision string `json:"revision,omitempty"`
+	// Time is the time of that revision, which stands in for the build
+	// time since the toolchain does not record one.
+	Time time.Time `json:"time,omitzero"`
+	// Modified reports a build from a working tree with local changes.
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// readBuildInfo caches the build info of the binary.
+var readBuildInfo = sync.OnceValue(func() BuildInfo {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return BuildInfo{}
+	}
+	return newBuildInfo(info)
+})
+
+// ReadBuildInfo returns the build info of the running binary. Fields are
+// empty when the binary was built without module or VCS information.
+func ReadBuildInfo() BuildInfo {
+	return readBuildInfo()
+}
+
+// newBuildInfo extracts a BuildInfo from the toolchain's record.
+func newBuildInfo(info *debug.BuildInfo) BuildInfo {
+	b := BuildInfo{
+		Module:    info.Main.Path,
+		Version:   info.Main.Version,
+		GoVersion: info.GoVersion,
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			b.Revision = s.Value
+		case "vcs.time":
+			b.Time, _ = time.Parse(time.RFC3339, s.Value)
+		case "vcs.modified":
+			b.Modified = s.Value == "true"
+		}
+	}
+	return b
+}
+
+// Attr returns the build info as a "build" log attribute group, for adding
+// to every record of a logger:
+//
+//	logger := slog.Default().With(groute.ReadBuildInfo().Attr())
+func (b BuildInfo) Attr() slog.Attr {
+	return slog.Group("build",
+		slog.String("version", b.Version),
+		slog.String("revision", b.Revision),
+		slog.String("go_version", b.GoVersion),
+	)
+}
+
+// VersionEndpoint registers GET pattern, relative to the group, serving the
+// binary's BuildInfo as JSON.
+func (g *Router) VersionEndpoint(pattern string) *Route {
+	return g.Get(pattern, func(w http.ResponseWriter, r *http.Request) {
+		_ = JSON(w, r, http.StatusOK, ReadBuildInfo())
+	})
+}
+
+// writeBuildInfo writes b in the Prometheus text format as the constant
+// labels of a groute_build_info gauge, which can be joined onto other series.
+func writeBuildInfo(w *strings.Builder, b BuildInfo) {
+	w.WriteString("# HELP groute_build_info Build information of the binary.\n# TYPE groute_build_info gauge\n")
+	fmt.Fprintf(w, "groute_build_info{module=\"%s\",version=\"%s\",revision=\"%s\",go_version=\"%s\"} 1\n",
+		escapeLabel(b.Module), escapeLabel(b.Version), escapeLabel(b.Revision), escapeLabel(b.GoVersion))
+}
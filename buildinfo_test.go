@@ -0,0 +1,71 @@
+package groute
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewBuildInfo(t *testing.T) {
+	b := newBuildInfo(&debug.BuildInfo{
+		GoVersion: "go1.24.0",
+		Main:      debug.Module{Path: "example.com/app", Version: "v1.2.3"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs", Value: "git"},
+			{Key: "vcs.revision", Value: "0123abcd"},
+			{Key: "vcs.time", Value: "2024-05-06T07:08:09Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	})
+	want := BuildInfo{
+		Module:    "example.com/app",
+		Version:   "v1.2.3",
+		Revision:  "0123abcd",
+		Time:      time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+		Modified:  true,
+		GoVersion: "go1.24.0",
+	}
+	if b != want {
+		t.Errorf("newBuildInfo = %+v, want %+v", b, want)
+	}
+
+	var logs strings.Builder
+	slog.New(slog.NewTextHandler(&logs, nil)).Info("start", b.Attr())
+	if !strings.Contains(logs.String(), "build.version=v1.2.3 build.revision=0123abcd build.go_version=go1.24.0") {
+		t.Errorf("log = %q", logs.String())
+	}
+}
+
+func TestVersionEndpoint(t *testing.T) {
+	g := NewRouter()
+	g.Group("/internal").VersionEndpoint("/version")
+
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/internal/version", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var b BuildInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &b); err != nil {
+		t.Fatal(err)
+	}
+	if b != ReadBuildInfo() || b.GoVersion == "" {
+		t.Errorf("body = %+v, want %+v", b, ReadBuildInfo())
+	}
+}
+
+func TestMetricsBuildInfo(t *testing.T) {
+	var b strings.Builder
+	if err := NewMetrics().WritePrometheus(&b); err != nil {
+		t.Fatal(err)
+	}
+	line := `groute_build_info{module="` + ReadBuildInfo().Module + `"`
+	if !strings.Contains(b.String(), line) || !strings.Contains(b.String(), `go_version="`+ReadBuildInfo().GoVersion+`"} 1`) {
+		t.Errorf("output missing build info:\n%s", b.String())
+	}
+}
@@ -0,0 +1,97 @@
+package groute
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Batch collects routes for bulk registration with Router.Bulk.
+type Batch struct {
+	entries []batchEntry
+}
+
+type batchEntry struct {
+	pattern string
+	handler http.Handler
+}
+
+// Handle adds a route with any HTTP method to the batch.
+func (b *Batch) Handle(pattern string, handler http.Handler) {
+	b.entries = append(b.entries, batchEntry{pattern: pattern, handler: handler})
+}
+
+// HandleFunc adds a route handler function to the batch.
+func (b *Batch) HandleFunc(pattern string, handler http.HandlerFunc) {
+	b.Handle(pattern, handler)
+}
+
+// Get adds a GET route to the batch.
+func (b *Batch) Get(pattern string, handler http.HandlerFunc) {
+	b.HandleFunc("GET "+pattern, handler)
+}
+
+// Post adds a POST route to the batch.
+func (b *Batch) Post(pattern string, handler http.HandlerFunc) {
+	b.HandleFunc("POST "+pattern, handler)
+}
+
+// Put adds a PUT route to the batch.
+func (b *Batch) Put(pattern string, handler http.HandlerFunc) {
+	b.HandleFunc("PUT "+pattern, handler)
+}
+
+// Delete adds a DELETE route to the batch.
+func (b *Batch) Delete(pattern string, handler http.HandlerFunc) {
+	b.HandleFunc("DELETE "+pattern, handler)
+}
+
+// Patch adds a PATCH route to the batch.
+func (b *Batch) Patch(pattern string, handler http.HandlerFunc) {
+	b.HandleFunc("PATCH "+pattern, handler)
+}
+
+// Len returns the number of routes in the batch.
+func (b *Batch) Len() int {
+	return len(b.entries)
+}
+
+// Bulk registers the routes collected by fn, e.g. one route per tenant, with
+// the router's prefix and middlewares. Duplicate patterns within the batch
+// and conflicts reported by http.ServeMux are returned as errors instead of
+// panicking, so one bad entry does not abort the rest of the batch.
+//
+// Bulk is about error handling, not speed: routes are registered one by
+// one, and ServeMux indexes patterns so that registration cost grows
+// linearly with the table. BenchmarkRegister measures both ways.
+func (g *Router) Bulk(fn func(b *Batch)) error {
+	b := &Batch{}
+	fn(b)
+
+	var errs []error
+	seen := make(map[string]struct{}, len(b.entries))
+	for _, e := range b.entries {
+		fullPattern := joinPath(g.prefix, e.pattern)
+		if _, ok := seen[fullPattern]; ok {
+			errs = append(errs, fmt.Errorf("groute: duplicate pattern %q in batch", fullPattern))
+			continue
+		}
+		seen[fullPattern] = struct{}{}
+
+		if err := g.tryHandle(e.pattern, e.handler); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// tryHandle registers a route, converting http.ServeMux panics into errors.
+func (g *Router) tryHandle(pattern string, handler http.Handler) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("groute: %v", v)
+		}
+	}()
+	g.Handle(pattern, handler)
+	return nil
+}
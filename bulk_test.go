@@ -0,0 +1,130 @@
+package groute
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBulk(t *testing.T) {
+	g := NewRouter()
+	api := g.Group("/api")
+	var captured string
+
+	err := api.Bulk(func(b *Batch) {
+		for i := 0; i < 100; i++ {
+			tenant := fmt.Sprintf("tenant%d", i)
+			b.Get("/"+tenant+"/users", func(w http.ResponseWriter, r *http.Request) {
+				captured = tenant
+				w.WriteHeader(http.StatusOK)
+			})
+		}
+		if b.Len() != 100 {
+			t.Errorf("expected 100 routes in batch, got %d", b.Len())
+		}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/api/tenant42/users", nil)
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", w.Code)
+	}
+	if captured != "tenant42" {
+		t.Errorf("expected tenant42 handler, got %q", captured)
+	}
+}
+
+func TestBulkAppliesMiddlewares(t *testing.T) {
+	g := NewRouter()
+	called := false
+	g.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			next(w, r)
+		}
+	})
+
+	err := g.Bulk(func(b *Batch) {
+		b.Post("/items", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/items", nil)
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+
+	if !called {
+		t.Error("middleware was not called")
+	}
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected status 201, got %d", w.Code)
+	}
+}
+
+func TestBulkErrors(t *testing.T) {
+	g := NewRouter()
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	err := g.Bulk(func(b *Batch) {
+		b.Get("/a", handler)
+		b.Get("/a", handler)
+		b.Get("/{x}", handler)
+		b.Get("/{y}", handler)
+		b.Get("/b", handler)
+	})
+	if err == nil {
+		t.Fatal("expected an error for duplicate and conflicting patterns")
+	}
+	if !strings.Contains(err.Error(), "duplicate pattern") {
+		t.Errorf("expected duplicate pattern error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "conflicts") {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+
+	// Valid routes in the batch are still registered.
+	req := httptest.NewRequest("GET", "/b", nil)
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", w.Code)
+	}
+}
+
+func BenchmarkRegister(b *testing.B) {
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+
+	for _, n := range []int{1000, 10000} {
+		b.Run(fmt.Sprintf("Get/%d", n), func(b *testing.B) {
+			for b.Loop() {
+				g := NewRouter()
+				for j := 0; j < n; j++ {
+					g.Get(fmt.Sprintf("/tenant%d/users/{id}", j), handler)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("Bulk/%d", n), func(b *testing.B) {
+			for b.Loop() {
+				g := NewRouter()
+				_ = g.Bulk(func(batch *Batch) {
+					for j := 0; j < n; j++ {
+						batch.Get(fmt.Sprintf("/tenant%d/users/{id}", j), handler)
+					}
+				})
+			}
+		})
+	}
+}
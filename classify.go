@@ -0,0 +1,79 @@
+package groute
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+)
+
+// RequestClassifier labels a request, e.g. with the region it comes from.
+// An empty label means the request could not be classified.
+type RequestClassifier func(r *http.Request) string
+
+// annotationKey is the context key for the annotation with the given name.
+type annotationKey struct{ name string }
+
+// Classify adds a pre-routing middleware annotating every request with the
+// label classifier gives it under name. Route conditions made with
+// Annotated then choose between routes sharing a pattern:
+//
+//	r.Classify("region", groute.GeoRegion(geoDB))
+//	r.When(groute.Annotated("region", "eu")).Get("/pricing", pricingEU)
+//	r.Get("/pricing", pricing)
+//
+// Handlers read annotations with Annotation.
+func (g *Router) Classify(name string, classifier RequestClassifier) {
+	g.Pre(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if label := classifier(r); label != "" {
+				r = r.WithContext(context.WithValue(r.Context(), annotationKey{name}, label))
+			}
+			next(w, r)
+		}
+	})
+}
+
+// Annotation returns the label a classifier gave r under name, or "".
+func Annotation(r *http.Request, name string) string {
+	label, _ := r.Context().Value(annotationKey{name}).(string)
+	return label
+}
+
+// Annotated returns a condition holding for requests annotated under name
+// with one of values.
+func Annotated(name string, values ...string) Condition {
+	return func(r *http.Request) bool {
+		label := Annotation(r, name)
+		return label != "" && slices.Contains(values, label)
+	}
+}
+
+// GeoIPLookup resolves IP addresses to regions, typically backed by a GeoIP
+// database.
+type GeoIPLookup interface {
+	Region(addr netip.Addr) (string, error)
+}
+
+// GeoRegion returns a classifier labeling requests with the region of the
+// client address. It uses r.RemoteAddr; behind a proxy, install a
+// pre-routing middleware setting it from the trusted forwarding header
+// first. Lookup failures leave the request unclassified.
+func GeoRegion(lookup GeoIPLookup) RequestClassifier {
+	return func(r *http.Request) string {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		addr, err := netip.ParseAddr(host)
+		if err != nil {
+			return ""
+		}
+		region, err := lookup.Region(addr.Unmap())
+		if err != nil {
+			return ""
+		}
+		return region
+	}
+}
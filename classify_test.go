@@ -0,0 +1,56 @@
+package groute
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+// fakeGeoIP maps addresses to regions.
+type fakeGeoIP map[netip.Addr]string
+
+func (f fakeGeoIP) Region(addr netip.Addr) (string, error) {
+	if region, ok := f[addr]; ok {
+		return region, nil
+	}
+	return "", errors.New("unknown address")
+}
+
+func TestClassifyRegion(t *testing.T) {
+	geo := fakeGeoIP{
+		netip.MustParseAddr("192.0.2.1"):    "eu",
+		netip.MustParseAddr("198.51.100.1"): "us",
+		netip.MustParseAddr("2001:db8::1"):  "eu",
+	}
+	g := NewRouter()
+	g.Classify("region", GeoRegion(geo))
+	g.Get("/pricing", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("eu " + Annotation(r, "region")))
+	}).When(Annotated("region", "eu", "uk"))
+	g.Get("/pricing", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("default " + Annotation(r, "region")))
+	})
+
+	tests := []struct {
+		remote string
+		body   string
+	}{
+		{"192.0.2.1:1234", "eu eu"},
+		{"[2001:db8::1]:443", "eu eu"},
+		{"[::ffff:192.0.2.1]:80", "eu eu"},
+		{"198.51.100.1:1234", "default us"},
+		{"203.0.113.9:1234", "default "},
+		{"garbage", "default "},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/pricing", nil)
+		req.RemoteAddr = tt.remote
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, req)
+		if w.Body.String() != tt.body {
+			t.Errorf("%s: body = %q, want %q", tt.remote, w.Body.String(), tt.body)
+		}
+	}
+}
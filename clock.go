@@ -0,0 +1,55 @@
+package groute
+
+import (
+	"net/http"
+	"time"
+)
+
+// Clock tells the current time. Tests can install a controllable clock
+// with SetClock instead of sleeping.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts an ordinary function to the Clock interface:
+//
+//	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+//	r.SetClock(groute.ClockFunc(func() time.Time { return now }))
+type ClockFunc func() time.Time
+
+// Now returns f().
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// systemClock is the real clock.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// SetClock sets the clock used for durations and timestamps by the router
+// and its middlewares, such as AccessLog, Metrics and Server-Timing. A nil
+// clock restores the real one. It applies to the whole router, even when
+// set on a group, and should be set before the router starts serving.
+func (g *Router) SetClock(clock Clock) {
+	g.root().clock = clock
+}
+
+// Clock returns the router's clock.
+func (g *Router) Clock() Clock {
+	if clock := g.root().clock; clock != nil {
+		return clock
+	}
+	return systemClock{}
+}
+
+// Now returns the current time according to the clock of the router that
+// dispatched r. Handlers and middlewares use it instead of time.Now so that
+// tests control time. Outside a matched route, e.g. in pre-routing
+// middlewares, it returns time.Now().
+func Now(r *http.Request) time.Time {
+	if route := CurrentRoute(r); route != nil && route.group != nil {
+		return route.group.Clock().Now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,103 @@
+package groute
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// fakeClock is a Clock that only moves when advanced.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestRouterClock(t *testing.T) {
+	clock := newFakeClock()
+	var buf bytes.Buffer
+	metrics := NewMetrics()
+	g := NewRouter()
+	api := g.Group("/api")
+	api.SetClock(clock)
+	g.Use(AccessLog(newTestLogger(&buf)), metrics.Middleware())
+	g.Get("/slow", func(w http.ResponseWriter, r *http.Request) {
+		if !Now(r).Equal(clock.Now()) {
+			t.Errorf("expected Now to use the router clock")
+		}
+		clock.Advance(250 * time.Millisecond)
+	}).SLO(SLO{Latency: 200 * time.Millisecond, Objective: 0.99})
+
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow", nil))
+
+	if record := decodeLogRecord(t, &buf); record["duration"] != float64(250*time.Millisecond) {
+		t.Errorf("expected logged duration of 250ms, got %v", record["duration"])
+	}
+	stats := metrics.Snapshot()
+	if len(stats) != 1 || stats[0].Duration != 250*time.Millisecond || stats[0].Bad != 1 {
+		t.Errorf("expected one slow request of 250ms, got %+v", stats)
+	}
+
+	g.SetClock(nil)
+	if _, ok := g.Clock().(systemClock); !ok {
+		t.Error("expected nil clock to restore the real clock")
+	}
+}
+
+func TestServerTimingClock(t *testing.T) {
+	clock := newFakeClock()
+	g := NewRouter()
+	g.SetClock(clock)
+	g.SetServerTiming(true)
+	g.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		stop := Timing(r).Start("db")
+		clock.Advance(2 * time.Millisecond)
+		stop()
+		clock.Advance(time.Millisecond)
+	})
+
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	expected := "match;dur=0.000, middleware;dur=0.000, handler;dur=3.000, db;dur=2.000"
+	if got := w.Header().Get("Server-Timing"); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestFileCacheClock(t *testing.T) {
+	clock := newFakeClock()
+	fsys := fstest.MapFS{
+		"app.js": {Data: []byte("v1"), ModTime: time.Unix(1, 0)},
+	}
+	c := NewFileCache(fsys, 1024)
+	c.Clock = clock
+	readCached(t, c, "app.js")
+
+	fsys["app.js"] = &fstest.MapFile{Data: []byte("v2!"), ModTime: time.Unix(2, 0)}
+	if got := readCached(t, c, "app.js"); got != "v1" {
+		t.Errorf("expected cached entry to be trusted until revalidation, got %q", got)
+	}
+	clock.Advance(c.Revalidate)
+	if got := readCached(t, c, "app.js"); got != "v2!" {
+		t.Errorf("expected changed file to be reloaded, got %q", got)
+	}
+}
@@ -0,0 +1,83 @@
+package groute
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Codec encodes and decodes JSON for the JSON helpers and BindJSON.
+// Implementations must be safe for concurrent use.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// SetCodec sets the codec used by JSON, Created, BindJSON and
+// ProblemErrorHandler for requests dispatched by the router. It defaults to
+// encoding/json; high-throughput services can plug in a faster
+// implementation:
+//
+//	r.SetCodec(sonicCodec{})
+//
+// A nil codec restores the default. It applies to the whole router, even
+// when set on a group, and should be set before the router starts serving.
+func (g *Router) SetCodec(codec Codec) {
+	g.root().codec = codec
+}
+
+// Codec returns the router's codec.
+func (g *Router) Codec() Codec {
+	if codec := g.root().codec; codec != nil {
+		return codec
+	}
+	return stdJSONCodec{}
+}
+
+// codecFor returns the codec of the router that dispatched r, or the
+// encoding/json codec outside a matched route.
+func codecFor(r *http.Request) Codec {
+	if route := CurrentRoute(r); route != nil && route.group != nil {
+		return route.group.Codec()
+	}
+	return stdJSONCodec{}
+}
+
+// stdJSONCodec is the encoding/json Codec.
+type stdJSONCodec struct{}
+
+func (stdJSONCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (stdJSONCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// BindJSON decodes the JSON request body into v with the codec of the
+// router that dispatched r.
+//
+// Malformed or empty bodies are reported as a 400 Bad Request StatusError
+// and bodies cut off by http.MaxBytesReader as 413 Request Entity Too
+// Large, so the error can be passed to Error as is. A body buffered by
+// BufferBody is rewound afterwards for the next reader.
+func BindJSON(r *http.Request, v any) error {
+	var data []byte
+	var err error
+	if _, ok := r.Body.(*bufferedBody); ok {
+		data, err = BodyBytes(r)
+	} else if r.Body != nil {
+		data, err = io.ReadAll(r.Body)
+	}
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return &StatusError{Code: http.StatusRequestEntityTooLarge, Err: err}
+		}
+		return err
+	}
+	if len(data) == 0 {
+		return NewStatusError(http.StatusBadRequest, "empty request body")
+	}
+	if err := codecFor(r).Unmarshal(data, v); err != nil {
+		return &StatusError{Code: http.StatusBadRequest, Err: fmt.Errorf("invalid JSON body: %w", err)}
+	}
+	return nil
+}
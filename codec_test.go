@@ -0,0 +1,138 @@
+package groute
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// countingCodec wraps the standard codec and counts calls.
+type countingCodec struct {
+	marshals, unmarshals int
+}
+
+func (c *countingCodec) Marshal(v any) ([]byte, error) {
+	c.marshals++
+	return json.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v any) error {
+	c.unmarshals++
+	return json.Unmarshal(data, v)
+}
+
+func TestRouterCodec(t *testing.T) {
+	codec := &countingCodec{}
+	g := NewRouter(WithCodec(codec))
+	g.Post("/", func(w http.ResponseWriter, r *http.Request) {
+		var v struct{ N int }
+		if err := BindJSON(r, &v); err != nil || v.N != 2 {
+			t.Errorf("expected n=2, got %+v (%v)", v, err)
+		}
+		_ = JSON(w, r, http.StatusOK, v)
+	})
+	g.Get("/fail", func(w http.ResponseWriter, r *http.Request) {
+		ProblemErrorHandler(w, r, NewStatusError(http.StatusBadRequest, "bad"))
+	})
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(`{"n":2}`)))
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/fail", nil))
+	if codec.marshals != 2 || codec.unmarshals != 1 {
+		t.Errorf("expected the router's codec to be used, got %+v", codec)
+	}
+
+	// Other routers keep the default codec.
+	other := NewRouter()
+	other.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		_ = JSON(w, r, http.StatusOK, 1)
+	})
+	other.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if codec.marshals != 2 {
+		t.Errorf("expected other routers not to use the codec, got %+v", codec)
+	}
+
+	g.Group("/api").SetCodec(nil)
+	if _, ok := g.Codec().(stdJSONCodec); !ok {
+		t.Errorf("expected SetCodec(nil) to restore the default, got %T", g.Codec())
+	}
+}
+
+func TestBindJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		limit  int64
+		status int
+	}{
+		{"valid", `{"name":"ada"}`, 0, 0},
+		{"malformed", `{"name":`, 0, http.StatusBadRequest},
+		{"empty", ``, 0, http.StatusBadRequest},
+		{"too large", `{"name":"ada lovelace"}`, 8, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			if tt.limit > 0 {
+				req.Body = http.MaxBytesReader(httptest.NewRecorder(), req.Body, tt.limit)
+			}
+			var v struct{ Name string }
+			err := BindJSON(req, &v)
+			if tt.status == 0 {
+				if err != nil || v.Name != "ada" {
+					t.Errorf("expected name ada, got %+v (%v)", v, err)
+				}
+				return
+			}
+			if got := ErrorStatus(err); got != tt.status {
+				t.Errorf("expected status %d, got %d (%v)", tt.status, got, err)
+			}
+		})
+	}
+}
+
+func TestBindJSONBufferedBody(t *testing.T) {
+	g := NewRouter()
+	g.Use(BufferBody(1024, 0))
+	g.Post("/", func(w http.ResponseWriter, r *http.Request) {
+		var v struct{ N int }
+		if err := BindJSON(r, &v); err != nil || v.N != 3 {
+			t.Errorf("expected n=3, got %+v (%v)", v, err)
+		}
+		if data, _ := BodyBytes(r); string(data) != `{"n":3}` {
+			t.Errorf("expected body to be readable again, got %q", data)
+		}
+	})
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(`{"n":3}`)))
+}
+
+type benchmarkPayload struct {
+	ID    int      `json:"id"`
+	Name  string   `json:"name"`
+	Email string   `json:"email"`
+	Tags  []string `json:"tags"`
+}
+
+func BenchmarkJSON(b *testing.B) {
+	v := benchmarkPayload{ID: 42, Name: "Ada Lovelace", Email: "ada@example.com", Tags: []string{"admin", "math"}}
+	r := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	b.ReportAllocs()
+	for b.Loop() {
+		w.Body.Reset()
+		_ = JSON(w, r, http.StatusOK, v)
+	}
+}
+
+func BenchmarkBindJSON(b *testing.B) {
+	body := []byte(`{"id":42,"name":"Ada Lovelace","email":"ada@example.com","tags":["admin","math"]}`)
+	req := httptest.NewRequest("POST", "/", nil)
+	b.ReportAllocs()
+	for b.Loop() {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		var v benchmarkPayload
+		_ = BindJSON(req, &v)
+	}
+}
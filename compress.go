@@ -0,0 +1,210 @@
+package groute
+
+import (
+	"bufio"
+	"compress/gzip"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// NoCompress disables response compression for the route, e.g. for
+// endpoints streaming through a format the Compress middleware does not
+// recognize on its own.
+func (rt *Route) NoCompress() *Route {
+	rt.update(func(info *routeInfo) {
+		info.noCompress = true
+	})
+	return rt
+}
+
+// Compress returns a middleware gzip-compressing responses for clients that
+// accept it, at the given compress/gzip level.
+//
+// Compression is decided when the handler writes the header, so streaming
+// endpoints registered next to compressed ones keep working: websocket
+// upgrades, server-sent events (text/event-stream), responses that already
+// carry a Content-Encoding, bodyless responses and routes marked NoCompress
+// are passed through untouched. Flush flushes the compressor as well, so
+// compressed streams are delivered incrementally.
+//
+// Strong ETags of compressed responses, including proxied ones, are
+// weakened, and Accept-Encoding is merged into any Vary header the handler
+// sets.
+//
+// Compress panics if level is not a valid gzip level.
+func Compress(level int) Middleware {
+	if _, err := gzip.NewWriterLevel(nil, level); err != nil {
+		panic("groute: " + err.Error())
+	}
+	pool := &sync.Pool{New: func() any {
+		zw, _ := gzip.NewWriterLevel(nil, level)
+		return zw
+	}}
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead || isUpgrade(r) || !acceptsGzip(r) {
+				next(w, r)
+				return
+			}
+			if route := CurrentRoute(r); route != nil && route.info.Load().noCompress {
+				next(w, r)
+				return
+			}
+			cw := &compressWriter{ResponseWriter: w, pool: pool}
+			defer cw.close()
+			next(cw, r)
+		}
+	}
+}
+
+// compressWriter gzips the response body once the header allows it.
+type compressWriter struct {
+	http.ResponseWriter
+	pool        *sync.Pool
+	zw          *gzip.Writer
+	wroteHeader bool
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *compressWriter) WriteHeader(code int) {
+	if w.wroteHeader || code < 200 && code != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.wroteHeader = true
+	h := w.Header()
+	// Added here rather than up front so that handlers and proxied
+	// upstreams setting Vary themselves neither drop nor duplicate it.
+	addVary(h, "Accept-Encoding")
+	switch {
+	case shouldCompress(code, h):
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		weakenETag(h)
+		w.zw = w.pool.Get().(*gzip.Writer)
+		w.zw.Reset(w.ResponseWriter)
+	case code == http.StatusNotModified && h.Get("Content-Encoding") == "":
+		// Revalidations must report the ETag of the compressed response.
+		weakenETag(h)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// weakenETag marks a strong ETag as weak, since the compressed body is no
+// longer byte-for-byte the representation it was computed for.
+func weakenETag(h http.Header) {
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		h.Set("ETag", "W/"+etag)
+	}
+}
+
+// addVary adds field to the Vary header unless it is already listed.
+func addVary(h http.Header, field string) {
+	for _, v := range h.Values("Vary") {
+		for token := range strings.SplitSeq(v, ",") {
+			if token = strings.TrimSpace(token); token == "*" || strings.EqualFold(token, field) {
+				return
+			}
+		}
+	}
+	h.Add("Vary", field)
+}
+
+// Write implements http.ResponseWriter.
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.zw != nil {
+		return w.zw.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher, flushing buffered compressed data first.
+func (w *compressWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.zw != nil {
+		_ = w.zw.Flush()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker for websocket upgrades.
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap returns the underlying http.ResponseWriter.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// close finishes the gzip stream and returns the compressor to the pool.
+func (w *compressWriter) close() {
+	if w.zw == nil {
+		return
+	}
+	_ = w.zw.Close()
+	w.zw.Reset(nil)
+	w.pool.Put(w.zw)
+	w.zw = nil
+}
+
+// shouldCompress reports whether a response with the given status and
+// header may be gzipped.
+func shouldCompress(code int, h http.Header) bool {
+	switch {
+	case code < 200, code == http.StatusNoContent, code == http.StatusNotModified:
+		return false
+	case h.Get("Content-Encoding") != "", h.Get("Content-Range") != "":
+		return false
+	}
+	return !isEventStream(h)
+}
+
+// isEventStream reports whether h describes a server-sent events stream.
+func isEventStream(h http.Header) bool {
+	mediaType, _, _ := strings.Cut(h.Get("Content-Type"), ";")
+	return strings.EqualFold(strings.TrimSpace(mediaType), "text/event-stream")
+}
+
+// isUpgrade reports whether r asks to switch protocols, e.g. to websocket.
+func isUpgrade(r *http.Request) bool {
+	for _, v := range r.Header.Values("Connection") {
+		for token := range strings.SplitSeq(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "upgrade") {
+				return true
+			}
+		}
+	}
+	return r.Header.Get("Upgrade") != ""
+}
+
+// acceptsGzip reports whether the client accepts gzip content encoding.
+func acceptsGzip(r *http.Request) bool {
+	for _, v := range r.Header.Values("Accept-Encoding") {
+		for part := range strings.SplitSeq(v, ",") {
+			coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			coding = strings.TrimSpace(coding)
+			if !strings.EqualFold(coding, "gzip") && coding != "*" {
+				continue
+			}
+			if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+					continue
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,247 @@
+package groute
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompress(t *testing.T) {
+	g := NewRouter()
+	g.Use(Compress(gzip.DefaultCompression))
+	g.Get("/text", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "11")
+		_, _ = w.Write([]byte("hello world"))
+	})
+
+	req := httptest.NewRequest("GET", "/text", nil)
+	req.Header.Set("Accept-Encoding", "br, gzip")
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected gzip encoding, got %q", rec.Header().Get("Content-Encoding"))
+	}
+	if rec.Header().Get("Content-Length") != "" {
+		t.Error("expected Content-Length to be removed")
+	}
+	if rec.Header().Get("Vary") != "Accept-Encoding" {
+		t.Errorf("expected Vary: Accept-Encoding, got %q", rec.Header().Get("Vary"))
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(zr)
+	if string(body) != "hello world" {
+		t.Errorf("expected decompressed body, got %q", body)
+	}
+}
+
+func TestCompressBypass(t *testing.T) {
+	g := NewRouter()
+	g.Use(Compress(gzip.BestSpeed))
+	g.Get("/events", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+		_, _ = w.Write([]byte("data: hi\n\n"))
+	})
+	g.Get("/encoded", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "br")
+		_, _ = w.Write([]byte("raw"))
+	})
+	g.Get("/ws", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("upgrade"))
+	})
+	g.Get("/empty", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	g.Get("/plain", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("plain"))
+	}).NoCompress()
+
+	tests := []struct {
+		path     string
+		header   http.Header
+		encoding string
+		body     string
+	}{
+		{"/events", nil, "", "data: hi\n\n"},
+		{"/encoded", nil, "br", "raw"},
+		{"/ws", http.Header{"Connection": {"keep-alive, Upgrade"}, "Upgrade": {"websocket"}}, "", "upgrade"},
+		{"/empty", nil, "", ""},
+		{"/plain", nil, "", "plain"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		for k, v := range tt.header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("Content-Encoding"); got != tt.encoding {
+			t.Errorf("%s: expected encoding %q, got %q", tt.path, tt.encoding, got)
+		}
+		if rec.Body.String() != tt.body {
+			t.Errorf("%s: expected body %q, got %q", tt.path, tt.body, rec.Body.String())
+		}
+	}
+}
+
+func TestCompressNotAccepted(t *testing.T) {
+	g := NewRouter()
+	g.Use(Compress(gzip.DefaultCompression))
+	g.Get("/text", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	})
+
+	for _, accept := range []string{"", "identity", "gzip;q=0", "deflate, br"} {
+		req := httptest.NewRequest("GET", "/text", nil)
+		req.Header.Set("Accept-Encoding", accept)
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+
+		if rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != "hello" {
+			t.Errorf("Accept-Encoding %q: expected uncompressed response, got %q", accept, rec.Body.String())
+		}
+	}
+}
+
+func TestCompressFlush(t *testing.T) {
+	g := NewRouter()
+	g.Use(Compress(gzip.DefaultCompression))
+	flushed := make(chan string, 1)
+	g.Get("/stream", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("chunk"))
+		w.(http.Flusher).Flush()
+		rec := w.(interface{ Unwrap() http.ResponseWriter }).Unwrap().(*httptest.ResponseRecorder)
+		zr, err := gzip.NewReader(strings.NewReader(rec.Body.String()))
+		if err != nil {
+			flushed <- err.Error()
+			return
+		}
+		buf := make([]byte, 5)
+		_, err = io.ReadFull(zr, buf)
+		if err != nil {
+			flushed <- err.Error()
+			return
+		}
+		flushed <- string(buf)
+	})
+
+	req := httptest.NewRequest("GET", "/stream", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	g.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got := <-flushed; got != "chunk" {
+		t.Errorf("expected flushed data to be readable, got %q", got)
+	}
+}
+
+func TestCompressInvalidLevel(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for invalid level")
+		}
+	}()
+	Compress(42)
+}
+
+func TestCompressProxied(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		switch r.URL.Path {
+		case "/plain":
+			w.Header().Set("Vary", "Origin")
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("Content-Length", "11")
+			_, _ = w.Write([]byte("hello world"))
+		case "/vary":
+			w.Header().Set("Vary", "accept-encoding")
+			_, _ = w.Write([]byte("hello world"))
+		case "/cached":
+			w.WriteHeader(http.StatusNotModified)
+		case "/encoded":
+			w.Header().Set("Content-Encoding", "br")
+			_, _ = w.Write([]byte("raw"))
+		}
+	}))
+	defer upstream.Close()
+
+	p, err := Proxy(upstream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewRouter()
+	g.Use(Compress(gzip.DefaultCompression))
+	g.Handle("/", p)
+
+	tests := []struct {
+		path     string
+		status   int
+		encoding string
+		etag     string
+		vary     []string
+	}{
+		{"/plain", http.StatusOK, "gzip", `W/"v1"`, []string{"Origin", "Accept-Encoding"}},
+		{"/vary", http.StatusOK, "gzip", `W/"v1"`, []string{"accept-encoding"}},
+		{"/cached", http.StatusNotModified, "", `W/"v1"`, []string{"Accept-Encoding"}},
+		{"/encoded", http.StatusOK, "br", `"v1"`, []string{"Accept-Encoding"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			rec := httptest.NewRecorder()
+			g.ServeHTTP(rec, req)
+
+			h := rec.Header()
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if got := h.Get("Content-Encoding"); got != tt.encoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.encoding)
+			}
+			if got := h.Get("ETag"); got != tt.etag {
+				t.Errorf("ETag = %q, want %q", got, tt.etag)
+			}
+			if got := h.Values("Vary"); strings.Join(got, ",") != strings.Join(tt.vary, ",") {
+				t.Errorf("Vary = %q, want %q", got, tt.vary)
+			}
+			if tt.encoding == "gzip" {
+				if h.Get("Content-Length") != "" {
+					t.Error("expected Content-Length to be removed")
+				}
+				zr, err := gzip.NewReader(rec.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if body, _ := io.ReadAll(zr); string(body) != "hello world" {
+					t.Errorf("body = %q, want hello world", body)
+				}
+			}
+		})
+	}
+}
+
+func TestCompressHandlerVary(t *testing.T) {
+	g := NewRouter()
+	g.Use(Compress(gzip.DefaultCompression))
+	g.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Vary", "Origin")
+		_, _ = w.Write([]byte("hello"))
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	if got := rec.Header().Values("Vary"); len(got) != 2 || got[1] != "Accept-Encoding" {
+		t.Errorf("Vary = %q, want Origin and Accept-Encoding", got)
+	}
+}
@@ -0,0 +1,156 @@
+package groute
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Condition reports whether a route should handle a request, in addition
+// to its pattern matching.
+type Condition func(r *http.Request) bool
+
+// When returns a view of g whose routes are restricted to requests meeting
+// all conditions, in addition to those of g.
+//
+// Several routes may be registered with the same pattern when they have
+// conditions, e.g. serving region-specific handlers on one path. Requests go
+// to the first route, in registration order, whose conditions all hold;
+// failing that, to the first route without conditions; failing that, they
+// get 404 Not Found:
+//
+//	r.When(groute.Annotated("region", "eu")).Get("/pricing", pricingEU)
+//	r.Get("/pricing", pricing)
+//
+// Registering a pattern a second time without conditions panics, like
+// ServeMux does for duplicate patterns.
+func (g *Router) When(conditions ...Condition) *Router {
+	predicates := make([]Predicate, len(conditions))
+	for i, c := range conditions {
+		predicates[i] = c
+	}
+	return g.Where(predicates...)
+}
+
+// When restricts the route to requests meeting all conditions. To register
+// several routes with one pattern, give the conditions at registration with
+// Router.When instead, so a mistaken duplicate can be told from an
+// alternative.
+func (rt *Route) When(conditions ...Condition) *Route {
+	predicates := make([]Predicate, len(conditions))
+	for i, c := range conditions {
+		predicates[i] = c
+	}
+	return rt.Where(predicates...)
+}
+
+// register adds h to the mux, or to the alternatives of the route already
+// registered with the same pattern. It panics if neither h nor one of those
+// routes has conditions.
+func (t *routeTable) register(mux *http.ServeMux, pattern string, h *routeHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if primary, ok := t.handlers[pattern]; ok {
+		if len(h.route.info.Load().conditions) == 0 && primary.hasFallback() {
+			panic(fmt.Sprintf("groute: pattern %q registered twice without conditions; use Router.When to register alternatives", pattern))
+		}
+		var alts []*routeHandler
+		if p := primary.alternatives.Load(); p != nil {
+			alts = append(alts, *p...)
+		}
+		alts = append(alts, h)
+		primary.alternatives.Store(&alts)
+		return
+	}
+	if t.handlers == nil {
+		t.handlers = make(map[string]*routeHandler)
+	}
+	mux.Handle(pattern, h)
+	t.handlers[pattern] = h
+}
+
+// selectRoute returns the handler of the route that should serve r among h
+// and its alternatives, or nil if none should.
+func (h *routeHandler) selectRoute(r *http.Request) *routeHandler {
+	alts := h.alternatives.Load()
+	if alts == nil {
+		if conds := h.route.info.Load().conditions; len(conds) > 0 && !allConditions(conds, r, h.route) {
+			return nil
+		}
+		return h
+	}
+
+	var fallback *routeHandler
+	for i := -1; i < len(*alts); i++ {
+		candidate := h
+		if i >= 0 {
+			candidate = (*alts)[i]
+		}
+		info := candidate.route.info.Load()
+		switch {
+		case info.disabled:
+		case len(info.conditions) == 0:
+			if fallback == nil {
+				fallback = candidate
+			}
+		case allConditions(info.conditions, r, candidate.route):
+			return candidate
+		}
+	}
+	return fallback
+}
+
+// hasFallback reports whether h or one of its alternatives has no
+// conditions.
+func (h *routeHandler) hasFallback() bool {
+	if len(h.route.info.Load().conditions) == 0 {
+		return true
+	}
+	if alts := h.alternatives.Load(); alts != nil {
+		for _, alt := range *alts {
+			if len(alt.route.info.Load().conditions) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lintAlternatives returns warnings about routes sharing h's pattern that
+// can never serve a request: routes with the same described conditions as
+// an earlier one.
+func (h *routeHandler) lintAlternatives() []string {
+	alts := h.alternatives.Load()
+	if alts == nil {
+		return nil
+	}
+	var warnings []string
+	seen := make(map[string]bool)
+	for _, candidate := range append([]*routeHandler{h}, *alts...) {
+		conditions := candidate.route.Conditions()
+		if len(conditions) == 0 {
+			continue
+		}
+		if slices.ContainsFunc(candidate.route.info.Load().conditions, isOpaque) {
+			continue // opaque conditions may differ
+		}
+		key := strings.Join(conditions, " && ")
+		if seen[key] {
+			warnings = append(warnings, fmt.Sprintf("groute: pattern %q registered twice with conditions %s; only the first is used", h.route.pattern, key))
+		}
+		seen[key] = true
+	}
+	return warnings
+}
+
+// allConditions reports whether r, being routed to rt, meets every
+// condition.
+func allConditions(conditions []Predicate, r *http.Request, rt *Route) bool {
+	for _, cond := range conditions {
+		if !matchRoute(cond, r, rt) {
+			return false
+		}
+	}
+	return true
+}
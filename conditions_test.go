@@ -0,0 +1,118 @@
+package groute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWhen(t *testing.T) {
+	header := func(value string) Condition {
+		return func(r *http.Request) bool { return r.Header.Get("X-Variant") == value }
+	}
+	handler := func(name string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(name)) }
+	}
+
+	g := NewRouter()
+	g.Get("/page", handler("a")).When(header("a"))
+	g.Get("/page", handler("fallback"))
+	b := g.When(header("b")).Get("/page", handler("b"))
+	g.Get("/only", handler("only")).When(header("a"))
+
+	tests := []struct {
+		path    string
+		variant string
+		status  int
+		body    string
+	}{
+		{"/page", "a", 200, "a"},
+		{"/page", "b", 200, "b"},
+		{"/page", "", 200, "fallback"},
+		{"/only", "a", 200, "only"},
+		{"/only", "b", 404, "404 page not found\n"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		req.Header.Set("X-Variant", tt.variant)
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, req)
+		if w.Code != tt.status || w.Body.String() != tt.body {
+			t.Errorf("%s %q: got %d %q, want %d %q", tt.path, tt.variant, w.Code, w.Body.String(), tt.status, tt.body)
+		}
+	}
+
+	// Disabled alternatives are skipped.
+	b.SetEnabled(false)
+	req := httptest.NewRequest("GET", "/page", nil)
+	req.Header.Set("X-Variant", "b")
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+	if w.Body.String() != "fallback" {
+		t.Errorf("disabled alternative: body = %q, want fallback", w.Body.String())
+	}
+	if b.Hits() != 1 {
+		t.Errorf("hits = %d, want 1", b.Hits())
+	}
+}
+
+func TestDuplicatePattern(t *testing.T) {
+	h := func(w http.ResponseWriter, r *http.Request) {}
+	always := func(r *http.Request) bool { return true }
+	tests := []struct {
+		name     string
+		register func(g *Router)
+		panics   bool
+	}{
+		{"twice", func(g *Router) { g.Get("/a", h); g.Get("/a", h) }, true},
+		{"conditions after registration", func(g *Router) { g.Get("/a", h); g.Get("/a", h).When(always) }, true},
+		{"alternative", func(g *Router) { g.Get("/a", h); g.When(always).Get("/a", h) }, false},
+		{"fallback", func(g *Router) { g.Get("/a", h).When(always); g.Get("/a", h) }, false},
+		{"two fallbacks", func(g *Router) { g.When(always).Get("/a", h); g.Get("/a", h); g.Get("/a", h) }, true},
+		{"group", func(g *Router) { g.Group("/v1").When(always).Get("/a", h); g.Get("/v1/a", h) }, false},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if v := recover(); (v != nil) != tt.panics {
+					t.Errorf("%s: recovered %v, want panic %v", tt.name, v, tt.panics)
+				}
+			}()
+			tt.register(NewRouter())
+		}()
+	}
+}
+
+func TestRouterWhen(t *testing.T) {
+	header := func(name string) Condition {
+		return func(r *http.Request) bool { return r.Header.Get(name) != "" }
+	}
+	g := NewRouter()
+	beta := g.When(header("X-Beta"))
+	beta.Get("/page", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("beta")) })
+	beta.When(header("X-Internal")).Get("/page", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("internal")) })
+	g.Get("/page", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("default")) })
+
+	tests := []struct {
+		headers []string
+		body    string
+	}{
+		{nil, "default"},
+		{[]string{"X-Beta"}, "beta"},
+		{[]string{"X-Internal"}, "default"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/page", nil)
+		for _, name := range tt.headers {
+			req.Header.Set(name, "1")
+		}
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, req)
+		if w.Body.String() != tt.body {
+			t.Errorf("%v: body = %q, want %q", tt.headers, w.Body.String(), tt.body)
+		}
+	}
+	if routes := g.Routes(); len(routes) != 3 || len(routes[1].Conditions()) != 2 {
+		t.Errorf("routes = %v, want the second with 2 conditions", routes)
+	}
+}
@@ -0,0 +1,230 @@
+package groute
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// RouteDiff is the difference between two routers, returned by Diff.
+type RouteDiff struct {
+	// Added and Removed list the routes only in the new or the old router,
+	// in registration order.
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	// Changed lists the routes in both routers whose setup differs.
+	Changed []RouteChange `json:"changed,omitempty"`
+	// Risks describes changes likely to break clients: traffic moving to
+	// other routes, removed route names and new Lint warnings.
+	Risks []string `json:"risks,omitempty"`
+}
+
+// RouteChange describes how a route differs between two routers.
+type RouteChange struct {
+	Route   string   `json:"route"`
+	Changes []string `json:"changes"`
+}
+
+// Diff compares the routes of the routers before and after a change, for
+// CI checks on a pull request or to validate a router built from reloaded
+// config before swapping it in:
+//
+//	if d := groute.Diff(current, reloaded); len(d.Risks) > 0 {
+//		log.Printf("not reloading:\n%s", d)
+//		return
+//	}
+//
+// Routes are identified by pattern and conditions. A route changes when its
+// name, middlewares (by function name), accepted content types, owner,
+// attributes or enabled state differ. Risks are found by sending an example
+// request for each added and removed route through the other router; Lint
+// warnings are risks only if before did not have them already.
+func Diff(before, after *Router) RouteDiff {
+	var d RouteDiff
+	oldRoutes, newRoutes := diffIndex(before), diffIndex(after)
+
+	for _, key := range newRoutes.keys {
+		if _, ok := oldRoutes.routes[key]; !ok {
+			d.Added = append(d.Added, key)
+			route := newRoutes.routes[key]
+			if prev := servingRoute(before, route); prev != nil {
+				if _, kept := newRoutes.routes[diffKey(prev)]; kept {
+					d.Risks = append(d.Risks, fmt.Sprintf("added %s takes requests such as %s from %s", key, examplePath(route), diffKey(prev)))
+				}
+			}
+		}
+	}
+	for _, key := range oldRoutes.keys {
+		route := oldRoutes.routes[key]
+		next, ok := newRoutes.routes[key]
+		if !ok {
+			d.Removed = append(d.Removed, key)
+			if now := servingRoute(after, route); now != nil {
+				d.Risks = append(d.Risks, fmt.Sprintf("requests to removed %s such as %s now go to %s", key, examplePath(route), diffKey(now)))
+			}
+			if name := route.RouteName(); name != "" && after.RouteByName(name) == nil {
+				d.Risks = append(d.Risks, fmt.Sprintf("route name %q removed with %s", name, key))
+			}
+			continue
+		}
+		if changes := routeChanges(route, next); len(changes) > 0 {
+			d.Changed = append(d.Changed, RouteChange{Route: key, Changes: changes})
+		}
+	}
+	known := before.Lint()
+	for _, warning := range after.Lint() {
+		if !slices.Contains(known, warning) {
+			d.Risks = append(d.Risks, warning)
+		}
+	}
+	return d
+}
+
+// String formats the diff with one line per route or risk, prefixed by
+// "+" for added, "-" for removed, "~" for changed routes and "!" for risks.
+func (d RouteDiff) String() string {
+	var b strings.Builder
+	for _, key := range d.Added {
+		fmt.Fprintf(&b, "+ %s\n", key)
+	}
+	for _, key := range d.Removed {
+		fmt.Fprintf(&b, "- %s\n", key)
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(&b, "~ %s: %s\n", c.Route, strings.Join(c.Changes, "; "))
+	}
+	for _, risk := range d.Risks {
+		fmt.Fprintf(&b, "! %s\n", risk)
+	}
+	return b.String()
+}
+
+// Empty reports whether the routers have the same routes and setup and the
+// diff found no risks.
+func (d RouteDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 && len(d.Risks) == 0
+}
+
+// routeIndex maps the diff keys of a router's routes to the routes.
+type routeIndex struct {
+	keys   []string
+	routes map[string]*Route
+}
+
+// diffIndex indexes the routes of g, mounted routers included.
+func diffIndex(g *Router) routeIndex {
+	idx := routeIndex{routes: make(map[string]*Route)}
+	for _, route := range g.Routes() {
+		key := diffKey(route)
+		if _, ok := idx.routes[key]; ok {
+			continue // shadowed; reported by Lint
+		}
+		idx.keys = append(idx.keys, key)
+		idx.routes[key] = route
+	}
+	return idx
+}
+
+// diffKey identifies route across routers: its pattern and conditions.
+func diffKey(route *Route) string {
+	key := route.Pattern()
+	if conditions := route.Conditions(); len(conditions) > 0 {
+		key += " where " + strings.Join(conditions, ",")
+	}
+	return key
+}
+
+// routeChanges describes how next differs from route.
+func routeChanges(route, next *Route) []string {
+	var changes []string
+	compare := func(what string, a, b any) {
+		if fa, fb := fmt.Sprint(a), fmt.Sprint(b); fa != fb {
+			changes = append(changes, fmt.Sprintf("%s %s -> %s", what, fa, fb))
+		}
+	}
+	compare("name", fmt.Sprintf("%q", route.RouteName()), fmt.Sprintf("%q", next.RouteName()))
+	compare("middlewares", routeMiddlewares(route), routeMiddlewares(next))
+	compare("enabled", route.Enabled(), next.Enabled())
+	compare("accepts", route.info.Load().accepts, next.info.Load().accepts)
+	compare("owner", fmt.Sprintf("%q", route.RouteOwner()), fmt.Sprintf("%q", next.RouteOwner()))
+	if a, b := route.attributes(), next.attributes(); !maps.Equal(a, b) {
+		compare("attributes", route.Attributes(), next.Attributes())
+	}
+	return changes
+}
+
+// routeMiddlewares returns the names of the middlewares wrapping route,
+// outermost first, including those of the routers it is mounted on.
+func routeMiddlewares(route *Route) []string {
+	var names []string
+	for g := route.group; g != nil; g = g.root().mountedOn {
+		var own []string
+		for _, mw := range g.middlewares {
+			own = append(own, funcName(mw))
+		}
+		names = append(own, names...)
+	}
+	return names
+}
+
+// servingRoute returns the route of g other than route that would serve an
+// example request to route, or nil.
+func servingRoute(g *Router, route *Route) *Route {
+	path := examplePath(route)
+	if !strings.HasPrefix(path, "/") {
+		return nil // host patterns
+	}
+	method := route.method
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequest(method, path, nil)
+	if err != nil {
+		return nil
+	}
+	match := g.root().lookupRoute(req)
+	if match == nil || diffKey(match) == diffKey(route) {
+		return nil
+	}
+	return match
+}
+
+// lookupRoute returns the route of g that serves req, descending into
+// mounted routers, or nil.
+func (g *Router) lookupRoute(req *http.Request) *Route {
+	_, pattern := g.mux.Handler(req)
+	t := g.routes
+	t.mu.Lock()
+	h := t.handlers[pattern]
+	t.mu.Unlock()
+	if h == nil {
+		return nil
+	}
+	if sub := h.route.mounted; sub != nil {
+		prefix := strings.TrimSuffix(h.route.path, "/")
+		stripped := *req.URL
+		stripped.Path = "/" + strings.TrimPrefix(strings.TrimPrefix(req.URL.Path, prefix), "/")
+		r2 := *req
+		r2.URL = &stripped
+		if match := sub.lookupRoute(&r2); match != nil {
+			return match
+		}
+	}
+	return h.route
+}
+
+// examplePath returns a path route matches, with wildcards replaced by "x".
+func examplePath(route *Route) string {
+	segments := strings.Split(route.Path(), "/")
+	for i, seg := range segments {
+		switch {
+		case seg == "{$}":
+			segments[i] = ""
+		case strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}"):
+			segments[i] = "x"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,111 @@
+package groute
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	build := func(v2 bool) *Router {
+		g := NewRouter()
+		g.Use(RequestID())
+		g.Get("/healthz", ok)
+		api := g.Group("/api")
+		if v2 {
+			api.Use(auth)
+			api.Get("/users/me", ok)
+			api.Get("/{path...}", ok)
+			api.Get("/search", ok).Owner("discovery").Where(Header("X-Beta", "1"))
+		} else {
+			api.Get("/orders/{id}", ok).Name("order")
+		}
+		api.Get("/users/{id}", ok).Name("user")
+		api.Get("/search", ok)
+		return g
+	}
+	before, after := build(false), build(true)
+
+	d := Diff(before, after)
+	if want := []string{"GET /api/users/me", "GET /api/{path...}", "GET /api/search where header(X-Beta=1)"}; !slices.Equal(d.Added, want) {
+		t.Errorf("Added = %q, want %q", d.Added, want)
+	}
+	if want := []string{"GET /api/orders/{id}"}; !slices.Equal(d.Removed, want) {
+		t.Errorf("Removed = %q, want %q", d.Removed, want)
+	}
+	var changed []string
+	for _, c := range d.Changed {
+		changed = append(changed, c.Route+": "+strings.Join(c.Changes, "; "))
+	}
+	if want := []string{
+		"GET /api/users/{id}: middlewares [grouter.RequestID] -> [grouter.RequestID grouter.auth]",
+		"GET /api/search: middlewares [grouter.RequestID] -> [grouter.RequestID grouter.auth]",
+	}; !slices.Equal(changed, want) {
+		t.Errorf("Changed = %q, want %q", changed, want)
+	}
+	if want := []string{
+		"added GET /api/users/me takes requests such as /api/users/me from GET /api/users/{id}",
+		"added GET /api/search where header(X-Beta=1) takes requests such as /api/search from GET /api/search",
+		"requests to removed GET /api/orders/{id} such as /api/orders/x now go to GET /api/{path...}",
+		`route name "order" removed with GET /api/orders/{id}`,
+	}; !slices.Equal(d.Risks, want) {
+		t.Errorf("Risks = %q, want %q", d.Risks, want)
+	}
+	if out := d.String(); !strings.Contains(out, "+ GET /api/users/me\n") || !strings.Contains(out, "- GET /api/orders/{id}\n") || !strings.Contains(out, "! route name") {
+		t.Errorf("String() =\n%s", out)
+	}
+
+	if d := Diff(before, build(false)); !d.Empty() {
+		t.Errorf("Diff of identical routers =\n%s", d)
+	}
+
+	// Lint warnings count as risks only when they are new.
+	lint := func(dup bool) *Router {
+		g := NewRouter()
+		g.Group("/a").Get("/x", ok)
+		g.Group("/a").Get("/y", ok)
+		if dup {
+			g.Group("/b").Get("/x", ok)
+			g.Group("/b").Get("/y", ok)
+		}
+		return g
+	}
+	if d := Diff(lint(false), lint(false)); !d.Empty() {
+		t.Errorf("Diff with existing Lint warnings =\n%s", d)
+	}
+	if d := Diff(lint(false), lint(true)); len(d.Risks) != 1 || !strings.Contains(d.Risks[0], `"/b"`) {
+		t.Errorf("Risks = %q, want the new Lint warning", d.Risks)
+	}
+}
+
+func TestDiffMounted(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	build := func(withStatus bool) *Router {
+		sub := NewRouter()
+		if withStatus {
+			sub.Get("/status", ok).SetEnabled(false)
+		} else {
+			sub.Get("/status", ok)
+		}
+		sub.Get("/{$}", ok)
+		g := NewRouter()
+		g.Mount("/legacy", sub)
+		return g
+	}
+	d := Diff(build(false), build(true))
+	if len(d.Changed) != 1 || d.Changed[0].Route != "GET /legacy/status" || d.Changed[0].Changes[0] != "enabled true -> false" {
+		t.Errorf("Changed = %+v, want GET /legacy/status disabled", d.Changed)
+	}
+
+	// Requests to a removed mounted route fall to the mount's other routes.
+	g := NewRouter()
+	sub := NewRouter()
+	sub.Get("/{page}", ok)
+	g.Mount("/legacy", sub)
+	d = Diff(build(false), g)
+	if !slices.Contains(d.Risks, "requests to removed GET /legacy/status such as /legacy/status now go to GET /legacy/{page}") {
+		t.Errorf("Risks = %q", d.Risks)
+	}
+}
@@ -0,0 +1,80 @@
+package groute
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+)
+
+// Response is the result of an in-process Dispatch.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Dispatch runs a request through the router in-process, without a network
+// hop, and returns the recorded response. It is meant for batch endpoints,
+// tests and internal service calls.
+//
+// path may include a query string; it may also be an absolute URL, in which
+// case its host is used as the request host. Rewrites, middlewares and error
+// handlers apply as for any other request. Header keys are canonicalized,
+// so header may be built by hand, as with http.Header{"x-api-key": ...}.
+// The request carries ctx, so cancellation propagates to the handler.
+func (g *Router) Dispatch(ctx context.Context, method, path string, body io.Reader, header http.Header) (*Response, error) {
+	r, err := http.NewRequestWithContext(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	if r.Host == "" {
+		r.Host = "localhost"
+	}
+	r.RequestURI = r.URL.RequestURI()
+	r.RemoteAddr = "127.0.0.1:0"
+	for key, values := range header {
+		for _, v := range values {
+			r.Header.Add(key, v)
+		}
+	}
+
+	w := &dispatchRecorder{header: make(http.Header)}
+	g.ServeHTTP(w, r)
+
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return &Response{
+		StatusCode: w.status,
+		Header:     w.header,
+		Body:       w.body.Bytes(),
+	}, nil
+}
+
+// dispatchRecorder is the http.ResponseWriter used by Dispatch.
+type dispatchRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *dispatchRecorder) Header() http.Header {
+	return w.header
+}
+
+func (w *dispatchRecorder) WriteHeader(code int) {
+	if w.status == 0 && code >= 200 {
+		w.status = code
+	}
+}
+
+func (w *dispatchRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+// Flush implements http.Flusher; the body is already fully buffered.
+func (w *dispatchRecorder) Flush() {}
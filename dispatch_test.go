@@ -0,0 +1,71 @@
+package groute
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDispatch(t *testing.T) {
+	g := NewRouter()
+	g.Use(RequestID())
+	g.Post("/echo/{name}", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Name", r.PathValue("name"))
+		w.Header().Set("X-Token", r.Header.Get("Authorization"))
+		w.Header().Set("X-Query", r.URL.Query().Get("q"))
+		w.Header().Set("X-Key", r.Header.Get("X-Api-Key"))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(body)
+	})
+
+	header := http.Header{"Authorization": {"Bearer t"}, "x-api-key": {"k1"}}
+	resp, err := g.Dispatch(context.Background(), "POST", "/echo/bob?q=1", strings.NewReader("hello"), header)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected status 201, got %d", resp.StatusCode)
+	}
+	if string(resp.Body) != "hello" {
+		t.Errorf("expected body 'hello', got %q", resp.Body)
+	}
+	if resp.Header.Get("X-Name") != "bob" || resp.Header.Get("X-Token") != "Bearer t" || resp.Header.Get("X-Query") != "1" || resp.Header.Get("X-Key") != "k1" {
+		t.Errorf("unexpected headers: %v", resp.Header)
+	}
+	if resp.Header.Get(RequestIDHeader) == "" {
+		t.Error("expected middlewares to run")
+	}
+}
+
+func TestDispatchNotFoundAndContext(t *testing.T) {
+	g := NewRouter()
+	var ctxValue any
+	type key struct{}
+	g.Get("/ctx", func(w http.ResponseWriter, r *http.Request) {
+		ctxValue = r.Context().Value(key{})
+	})
+
+	resp, err := g.Dispatch(context.Background(), "GET", "/missing", nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", resp.StatusCode)
+	}
+
+	ctx := context.WithValue(context.Background(), key{}, "v")
+	resp, _ = g.Dispatch(ctx, "GET", "/ctx", nil, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected implicit status 200, got %d", resp.StatusCode)
+	}
+	if ctxValue != "v" {
+		t.Errorf("expected context to propagate, got %v", ctxValue)
+	}
+
+	if _, err := g.Dispatch(context.Background(), "BAD METHOD", "/", nil, nil); err == nil {
+		t.Error("expected error for invalid method")
+	}
+}
@@ -0,0 +1,118 @@
+package groute
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Drain signals long-lived streams, such as websockets and server-sent
+// events, that the server is shutting down. Handlers watching Draining send
+// their final event or close frame and return; streams passed through a
+// ReverseProxy are ended. Drain applies to the whole router, even when
+// called on a group, and is meant to run when shutdown starts:
+//
+//	srv.RegisterOnShutdown(r.Drain)
+//	srv.Shutdown(ctx)
+//	r.WaitStreams(ctx) // Shutdown does not wait for hijacked connections
+func (g *Router) Drain() {
+	root := g.root()
+	root.drain()
+	for _, sub := range root.mountedRouters() {
+		sub.Drain()
+	}
+}
+
+// Draining returns a channel closed when the router that dispatched r starts
+// draining. Outside a matched route it returns nil, which never fires.
+//
+//	for {
+//		select {
+//		case <-groute.Draining(r):
+//			fmt.Fprint(w, "event: shutdown\ndata: reconnect\n\n")
+//			return
+//		case msg := <-messages:
+//			...
+//		}
+//	}
+func Draining(r *http.Request) <-chan struct{} {
+	route := CurrentRoute(r)
+	if route == nil || route.group == nil {
+		return nil
+	}
+	return route.group.root().draining.Done()
+}
+
+// TrackStream counts r as an open stream in ActiveStreams until done is
+// called. Streams proxied by a ReverseProxy are tracked automatically.
+func TrackStream(r *http.Request) (done func()) {
+	route := CurrentRoute(r)
+	if route == nil || route.group == nil {
+		return func() {}
+	}
+	return route.group.root().trackStream()
+}
+
+// ActiveStreams returns the number of streams being tracked, e.g. for
+// reporting how many connections are left to drain.
+func (g *Router) ActiveStreams() int {
+	root := g.root()
+	n := int(root.streams.Load())
+	for _, sub := range root.mountedRouters() {
+		n += sub.ActiveStreams()
+	}
+	return n
+}
+
+// WaitStreams waits until no stream is being tracked or ctx is done, in
+// which case it returns the context's error.
+func (g *Router) WaitStreams(ctx context.Context) error {
+	root := g.root()
+	interval := 10 * time.Millisecond
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for root.ActiveStreams() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			interval = min(2*interval, 500*time.Millisecond)
+			timer.Reset(interval)
+		}
+	}
+	return nil
+}
+
+// trackStream counts a stream until the returned function is called.
+func (g *Router) trackStream() func() {
+	g.streams.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() { g.streams.Add(-1) })
+	}
+}
+
+// drainingBody ends a proxied event stream cleanly once the router drains,
+// instead of failing the copy to the client.
+type drainingBody struct {
+	io.ReadCloser
+	drained atomic.Bool
+}
+
+// Read implements io.Reader.
+func (b *drainingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if b.drained.Load() {
+		return n, io.EOF
+	}
+	return n, err
+}
+
+// drain ends the body, unblocking a pending Read.
+func (b *drainingBody) drain() {
+	b.drained.Store(true)
+	_ = b.ReadCloser.Close()
+}
@@ -0,0 +1,173 @@
+package groute
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDrainEventStream(t *testing.T) {
+	g := NewRouter()
+	api := g.Group("/api")
+	api.Get("/events", func(w http.ResponseWriter, r *http.Request) {
+		defer TrackStream(r)()
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: hello\n\n")
+		http.NewResponseController(w).Flush()
+		select {
+		case <-Draining(r):
+			io.WriteString(w, "event: shutdown\n\n")
+		case <-r.Context().Done():
+		}
+	})
+	front := httptest.NewServer(g)
+	defer front.Close()
+
+	resp, err := http.Get(front.URL + "/api/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	br := bufio.NewReader(resp.Body)
+	if line, err := br.ReadString('\n'); err != nil || line != "data: hello\n" {
+		t.Fatalf("first line = %q, %v", line, err)
+	}
+	if n := g.ActiveStreams(); n != 1 {
+		t.Errorf("ActiveStreams = %d, want 1", n)
+	}
+
+	api.Drain()
+	rest, err := io.ReadAll(br)
+	if err != nil || string(rest) != "\nevent: shutdown\n\n" {
+		t.Errorf("rest = %q, %v; want the shutdown event", rest, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := g.WaitStreams(ctx); err != nil {
+		t.Errorf("WaitStreams = %v", err)
+	}
+}
+
+func TestDrainOutsideRoute(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	if Draining(r) != nil {
+		t.Error("Draining outside a route should be nil")
+	}
+	TrackStream(r)()
+}
+
+func TestWaitStreamsTimeout(t *testing.T) {
+	g := NewRouter()
+	g.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		TrackStream(r)
+	})
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := g.WaitStreams(ctx); err != context.DeadlineExceeded {
+		t.Errorf("WaitStreams = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestDrainProxiedEventStream(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: first\n\n")
+		http.NewResponseController(w).Flush()
+		<-r.Context().Done()
+	}))
+	defer upstream.Close()
+
+	p, err := Proxy(upstream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewRouter()
+	g.Handle("/events", p)
+	front := httptest.NewServer(g)
+	defer front.Close()
+
+	resp, err := http.Get(front.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	br := bufio.NewReader(resp.Body)
+	if line, err := br.ReadString('\n'); err != nil || line != "data: first\n" {
+		t.Fatalf("first line = %q, %v", line, err)
+	}
+	if n := g.ActiveStreams(); n != 1 {
+		t.Errorf("ActiveStreams = %d, want 1", n)
+	}
+
+	// The stream ends cleanly rather than being aborted.
+	g.Drain()
+	rest, err := io.ReadAll(br)
+	if err != nil || string(rest) != "\n" {
+		t.Errorf("rest = %q, %v; want a clean end", rest, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := g.WaitStreams(ctx); err != nil {
+		t.Errorf("WaitStreams = %v", err)
+	}
+}
+
+func TestDrainProxiedUpgrade(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+		rw.Flush()
+		io.Copy(conn, rw)
+	}))
+	defer upstream.Close()
+
+	p, err := Proxy(upstream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewRouter()
+	g.Handle("/ws", p)
+	front := httptest.NewServer(g)
+	defer front.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(front.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("GET /ws HTTP/1.1\r\nHost: example.com\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n"))
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusSwitchingProtocols)
+	}
+	if n := g.ActiveStreams(); n != 1 {
+		t.Errorf("ActiveStreams = %d, want 1", n)
+	}
+
+	g.Drain()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := br.ReadByte(); err != io.EOF {
+		t.Errorf("read after drain = %v, want EOF", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := g.WaitStreams(ctx); err != nil {
+		t.Errorf("WaitStreams = %v", err)
+	}
+}
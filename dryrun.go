@@ -0,0 +1,98 @@
+package groute
+
+import (
+	"context"
+	"net/http"
+)
+
+// defaultExampleValue fills path parameters without an example value.
+const defaultExampleValue = "example"
+
+// Example sets the value used for the path parameter param when Examples
+// enumerates URLs for the route, e.g. an ID that exists in a test fixture.
+// Parameters without an example are filled with "example".
+func (rt *Route) Example(param, value string) *Route {
+	rt.update(func(info *routeInfo) {
+		examples := make(map[string]string, len(info.examples)+1)
+		for k, v := range info.examples {
+			examples[k] = v
+		}
+		examples[param] = value
+		info.examples = examples
+	})
+	return rt
+}
+
+// ExampleRequest is a concrete request matching a route. Host is set for
+// host-qualified patterns such as "GET api.example.com/users".
+type ExampleRequest struct {
+	Method  string `json:"method"`
+	Host    string `json:"host,omitempty"`
+	Path    string `json:"path"`
+	Pattern string `json:"pattern"`
+}
+
+// Examples returns one example request for every enabled route, with path
+// parameters filled in, for DAST scanners and smoke tests to crawl. Routes
+// registered without a method are listed as GET.
+func (g *Router) Examples() []ExampleRequest {
+	var examples []ExampleRequest
+	for _, route := range g.Routes() {
+		info := route.info.Load()
+		if info.disabled {
+			continue
+		}
+		host, pattern := splitHostPath(route.Path())
+		var params []string
+		for _, name := range paramNames(pattern) {
+			value, ok := info.examples[name]
+			if !ok {
+				value = defaultExampleValue
+			}
+			params = append(params, name, value)
+		}
+		path, err := buildPath(pattern, params)
+		if err != nil {
+			continue
+		}
+		method := route.Method()
+		if method == "" {
+			method = http.MethodGet
+		}
+		examples = append(examples, ExampleRequest{Method: method, Host: host, Path: path, Pattern: route.Pattern()})
+	}
+	return examples
+}
+
+// ProbeResult is the outcome of probing an example request.
+type ProbeResult struct {
+	ExampleRequest
+	StatusCode int
+	Err        error
+}
+
+// Probe sends a HEAD request for every GET or HEAD example through
+// Dispatch, without a network hop, and returns the responses. Routes with
+// other methods are skipped, since requests to them may not be safe to
+// repeat. Examples of host-qualified patterns are sent to their host. Use it as a smoke test that every readable route answers.
+func (g *Router) Probe(ctx context.Context) []ProbeResult {
+	var results []ProbeResult
+	for _, example := range g.Examples() {
+		if example.Method != http.MethodGet && example.Method != http.MethodHead {
+			continue
+		}
+		result := ProbeResult{ExampleRequest: example}
+		target := example.Path
+		if example.Host != "" {
+			target = "http://" + example.Host + target
+		}
+		resp, err := g.Dispatch(ctx, http.MethodHead, target, nil, nil)
+		if err != nil {
+			result.Err = err
+		} else {
+			result.StatusCode = resp.StatusCode
+		}
+		results = append(results, result)
+	}
+	return results
+}
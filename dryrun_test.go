@@ -0,0 +1,88 @@
+package groute
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestExamples(t *testing.T) {
+	h := func(w http.ResponseWriter, r *http.Request) {}
+	g := NewRouter()
+	api := g.Group("/api")
+	api.Get("/users/{id}", h).Example("id", "42")
+	api.Delete("/users/{id}/sessions/{sid}", h).Example("sid", "a b")
+	g.Handle("/files/{path...}", http.HandlerFunc(h)).Example("path", "docs/readme.md")
+	g.Get("/{$}", h)
+	g.Get("/hidden", h).SetEnabled(false)
+
+	expected := []ExampleRequest{
+		{"GET", "", "/api/users/42", "GET /api/users/{id}"},
+		{"DELETE", "", "/api/users/example/sessions/a%20b", "DELETE /api/users/{id}/sessions/{sid}"},
+		{"GET", "", "/files/docs/readme.md", "/files/{path...}"},
+		{"GET", "", "/", "GET /{$}"},
+	}
+	examples := g.Examples()
+	if len(examples) != len(expected) {
+		t.Fatalf("expected %d examples, got %+v", len(expected), examples)
+	}
+	for i := range expected {
+		if examples[i] != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], examples[i])
+		}
+	}
+}
+
+func TestProbe(t *testing.T) {
+	var deleted bool
+	g := NewRouter()
+	g.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") != "7" {
+			http.NotFound(w, r)
+		}
+	}).Example("id", "7")
+	g.Get("/broken", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	g.Delete("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		deleted = true
+	})
+
+	results := g.Probe(context.Background())
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %+v", results)
+	}
+	if results[0].Path != "/users/7" || results[0].StatusCode != http.StatusOK || results[0].Err != nil {
+		t.Errorf("unexpected result %+v", results[0])
+	}
+	if results[1].Path != "/broken" || results[1].StatusCode != http.StatusInternalServerError {
+		t.Errorf("unexpected result %+v", results[1])
+	}
+	if deleted {
+		t.Error("expected DELETE routes not to be probed")
+	}
+}
+
+func TestExamplesHostPattern(t *testing.T) {
+	g := NewRouter()
+	// Handle joins patterns to the group prefix, so add a host-qualified
+	// route to the table and the mux directly.
+	route := newRoute("GET example.com/users/{id}")
+	route.group = g
+	g.routes.routes = append(g.routes.routes, route)
+	g.mux.HandleFunc(route.Pattern(), func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") != "7" {
+			http.NotFound(w, r)
+		}
+	})
+	route.Example("id", "7")
+
+	want := ExampleRequest{"GET", "example.com", "/users/7", "GET example.com/users/{id}"}
+	if examples := g.Examples(); len(examples) != 1 || examples[0] != want {
+		t.Fatalf("Examples() = %+v, want [%+v]", examples, want)
+	}
+	results := g.Probe(context.Background())
+	if len(results) != 1 || results[0].StatusCode != http.StatusOK || results[0].Err != nil {
+		t.Errorf("Probe() = %+v, want 200 from the host route", results)
+	}
+}
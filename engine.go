@@ -0,0 +1,37 @@
+package groute
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Routes are matched by net/http's ServeMux, which knows only {name} and
+// {name...} wildcards and the {$} anchor. Wildcard syntax other routers
+// accept is detected when a route is registered, so it fails with an error
+// naming the feature instead of panicking deep inside the mux. Segments
+// such as ":id" or "*" are valid literal segments for ServeMux and are
+// registered as such.
+
+// checkPattern reports pattern syntax the ServeMux engine does not support.
+func checkPattern(pattern string) error {
+	_, path, ok := strings.Cut(pattern, " ")
+	if !ok {
+		path = pattern
+	}
+	for seg := range strings.SplitSeq(path, "/") {
+		var feature string
+		switch {
+		case !strings.HasPrefix(seg, "{") || !strings.HasSuffix(seg, "}"):
+			continue
+		case strings.Contains(seg, ":"):
+			name, _, _ := strings.Cut(seg[1:], ":")
+			feature = fmt.Sprintf("parameter constraints; write {%s} and validate the value in the handler", name)
+		case strings.HasSuffix(seg, "?}"):
+			feature = "optional parameters; register the route with and without the parameter"
+		default:
+			continue
+		}
+		return fmt.Errorf("groute: pattern %q: segment %q uses %s (the ServeMux engine is the only one available)", pattern, seg, feature)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package groute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheckPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		err     string
+	}{
+		{"GET /users/{id}", ""},
+		{"/files/{path...}", ""},
+		{"GET /{$}", ""},
+		{"GET /users/:id", ""},
+		{"/static/*", ""},
+		{"GET /users/{id:[0-9]+}", "parameter constraints; write {id}"},
+		{"GET /users/{id:int}/posts", `segment "{id:int}"`},
+		{"GET /docs/{page?}", "optional parameters"},
+	}
+	for _, tt := range tests {
+		err := checkPattern(tt.pattern)
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("checkPattern(%q) = %v, want nil", tt.pattern, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("checkPattern(%q) = %v, want error containing %q", tt.pattern, err, tt.err)
+		}
+	}
+}
+
+func TestHandleUnsupportedPattern(t *testing.T) {
+	defer func() {
+		v := recover()
+		err, ok := v.(error)
+		if !ok || !strings.Contains(err.Error(), "ServeMux engine") {
+			t.Errorf("recovered %v, want an error naming the engine", v)
+		}
+	}()
+	g := NewRouter()
+	g.Group("/api").Get("/users/{id:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {})
+}
+
+func TestLiteralColonAndStar(t *testing.T) {
+	g := NewRouter()
+	g.Get("/users/:id", func(w http.ResponseWriter, r *http.Request) {})
+	g.Get("/static/*", func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/users/:id", http.StatusOK},
+		{"/users/42", http.StatusNotFound},
+		{"/static/*", http.StatusOK},
+		{"/static/app.js", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Code != tt.code {
+			t.Errorf("%s: status = %d, want %d", tt.path, w.Code, tt.code)
+		}
+	}
+}
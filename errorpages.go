@@ -0,0 +1,216 @@
+package groute
+
+import (
+	"bytes"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrorPages is an error handler rendering HTML error pages from templates,
+// in the language of the request. Create it with NewErrorPages and install
+// its HandleError method on the groups serving HTML:
+//
+//	pages, err := groute.NewErrorPages(os.DirFS("templates/errors"))
+//	r.SetErrorHandler(pages.HandleError)
+//	api.SetErrorHandler(groute.ProblemErrorHandler)
+//
+// Templates are looked up by path, most specific first: "de-CH/404.html",
+// "de/404.html", "404.html", then the same with "error.html" in place of the
+// status code. The directory is the locale; templates outside one are the
+// default. Templates are executed with an ErrorPage. If none matches or
+// execution fails, DefaultErrorHandler answers instead.
+type ErrorPages struct {
+	// Locale returns the locale of r, such as "de" or "pt-BR". Defaults to
+	// the locale of the templates best matching the Accept-Language header.
+	Locale func(r *http.Request) string
+
+	templates *template.Template
+	locales   []string
+}
+
+// ErrorPage is the data error page templates are executed with.
+type ErrorPage struct {
+	Code int
+	// Status is the status text, e.g. "Not Found".
+	Status string
+	// Message is the error message for client errors. It is empty for
+	// server errors, so internal details are not leaked.
+	Message string
+	Locale  string
+	Path    string
+	// RequestID is the ID assigned by the RequestID middleware, for users
+	// to quote when reporting the error.
+	RequestID string
+}
+
+// NewErrorPages parses the .html files in fsys as error page templates.
+// They are parsed into one set, so pages can share blocks defined in any of
+// them.
+func NewErrorPages(fsys fs.FS) (*ErrorPages, error) {
+	p := &ErrorPages{templates: template.New("")}
+	seen := make(map[string]bool)
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(name) != ".html" {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		if _, err := p.templates.New(name).Parse(string(data)); err != nil {
+			return err
+		}
+		if locale, _, ok := strings.Cut(name, "/"); ok && !seen[locale] {
+			seen[locale] = true
+			p.locales = append(p.locales, locale)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(p.locales)
+	return p, nil
+}
+
+// HandleError is an ErrorHandler writing the error page for err.
+func (p *ErrorPages) HandleError(w http.ResponseWriter, r *http.Request, err error) {
+	code := ErrorStatus(err)
+	if code == StatusClientClosedRequest {
+		w.WriteHeader(code)
+		return
+	}
+	locale := p.locale(r)
+	t, pageLocale := p.lookup(locale, strconv.Itoa(code))
+	if t == nil {
+		DefaultErrorHandler(w, r, err)
+		return
+	}
+
+	page := ErrorPage{Code: code, Status: http.StatusText(code), Locale: locale, Path: r.URL.Path, RequestID: GetRequestID(r)}
+	if code < 500 {
+		page.Message = err.Error()
+	}
+	var buf bytes.Buffer
+	if t.Execute(&buf, page) != nil {
+		DefaultErrorHandler(w, r, err)
+		return
+	}
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", "text/html; charset=utf-8")
+	h.Set("X-Content-Type-Options", "nosniff")
+	if pageLocale != "" {
+		h.Set("Content-Language", pageLocale)
+	}
+	w.WriteHeader(code)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// lookup returns the most specific template for the locale and status
+// code, and the locale it was written for.
+func (p *ErrorPages) lookup(locale, code string) (*template.Template, string) {
+	locales := []string{locale}
+	if base, _, ok := strings.Cut(locale, "-"); ok {
+		locales = append(locales, base)
+	}
+	locales = append(locales, "")
+	for _, name := range []string{code, "error"} {
+		for _, l := range locales {
+			full := name + ".html"
+			if l != "" {
+				full = l + "/" + full
+			}
+			if t := p.templates.Lookup(full); t != nil {
+				return t, l
+			}
+		}
+	}
+	return nil, ""
+}
+
+// locale returns the locale for r.
+func (p *ErrorPages) locale(r *http.Request) string {
+	if p.Locale != nil {
+		return p.Locale(r)
+	}
+	return matchLanguage(r.Header.Get("Accept-Language"), p.locales)
+}
+
+// matchLanguage returns the locale of available best matching an
+// Accept-Language header, or "" if none does.
+func matchLanguage(header string, available []string) string {
+	type tag struct {
+		lang string
+		q    float64
+	}
+	var tags []tag
+	for part := range strings.SplitSeq(header, ",") {
+		lang, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if lang = strings.TrimSpace(lang); lang != "" && q > 0 {
+			tags = append(tags, tag{lang, q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	for _, t := range tags {
+		base, _, _ := strings.Cut(t.lang, "-")
+		for _, want := range []string{t.lang, base} {
+			for _, locale := range available {
+				if strings.EqualFold(locale, want) {
+					return locale
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// problem is an RFC 9457 problem details object.
+type problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// RequestID is an extension member carrying the request's ID.
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// ProblemErrorHandler is an ErrorHandler answering with RFC 9457 problem
+// details (application/problem+json), for API groups. Like
+// DefaultErrorHandler, it only includes the error message for client errors.
+// The request ID, if any, is included as the "request_id" member.
+func ProblemErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	code := ErrorStatus(err)
+	if code == StatusClientClosedRequest {
+		w.WriteHeader(code)
+		return
+	}
+	p := problem{Type: "about:blank", Title: http.StatusText(code), Status: code, Instance: r.URL.Path, RequestID: GetRequestID(r)}
+	if code < 500 {
+		p.Detail = err.Error()
+	}
+	data, merr := codecFor(r).Marshal(p)
+	if merr != nil {
+		plainErrorHandler(w, r, err)
+		return
+	}
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", "application/problem+json")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	_, _ = w.Write(append(data, '\n'))
+}
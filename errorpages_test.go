@@ -0,0 +1,247 @@
+package groute
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func newTestErrorPages(t *testing.T) *ErrorPages {
+	t.Helper()
+	pages, err := NewErrorPages(fstest.MapFS{
+		"layout.html":    {Data: []byte(`{{define "layout"}}<h1>{{.Code}}</h1><p>{{.Message}}</p>{{end}}`)},
+		"404.html":       {Data: []byte(`not found: {{template "layout" .}}`)},
+		"error.html":     {Data: []byte(`error: {{template "layout" .}}`)},
+		"de/404.html":    {Data: []byte(`nicht gefunden: {{.Path}}`)},
+		"pt-BR/404.html": {Data: []byte(`não encontrado`)},
+		"notes.txt":      {Data: []byte(`{{`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pages
+}
+
+func TestErrorPages(t *testing.T) {
+	g := NewRouter()
+	g.SetErrorHandler(newTestErrorPages(t).HandleError)
+	g.Get("/fail", func(w http.ResponseWriter, r *http.Request) {
+		Error(w, r, errors.New("database password is hunter2"))
+	})
+	g.Get("/bad", func(w http.ResponseWriter, r *http.Request) {
+		Error(w, r, NewStatusError(http.StatusBadRequest, "<missing> id"))
+	})
+
+	tests := []struct {
+		path     string
+		language string
+		status   int
+		body     string
+		lang     string
+	}{
+		{"/missing", "", 404, "not found: <h1>404</h1><p>404 page not found</p>", ""},
+		{"/missing", "fr, de;q=0.8", 404, "nicht gefunden: /missing", "de"},
+		{"/missing", "de-AT", 404, "nicht gefunden: /missing", "de"},
+		{"/missing", "pt-br", 404, "não encontrado", "pt-BR"},
+		{"/missing", "de;q=0, en", 404, "not found: <h1>404</h1><p>404 page not found</p>", ""},
+		{"/fail", "de", 500, "error: <h1>500</h1><p></p>", ""},
+		{"/bad", "", 400, "error: <h1>400</h1><p>&lt;missing&gt; id</p>", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path+" "+tt.language, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header.Set("Accept-Language", tt.language)
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if w.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.body)
+			}
+			if got := w.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			if got := w.Header().Get("Content-Language"); got != tt.lang {
+				t.Errorf("Content-Language = %q, want %q", got, tt.lang)
+			}
+		})
+	}
+}
+
+func TestErrorPagesLocaleHook(t *testing.T) {
+	pages := newTestErrorPages(t)
+	pages.Locale = func(r *http.Request) string { return r.URL.Query().Get("lang") }
+
+	w := httptest.NewRecorder()
+	pages.HandleError(w, httptest.NewRequest("GET", "/x?lang=de-CH", nil), NewStatusError(http.StatusNotFound, "gone"))
+	if w.Body.String() != "nicht gefunden: /x" {
+		t.Errorf("body = %q", w.Body.String())
+	}
+}
+
+func TestErrorPagesInvalidTemplate(t *testing.T) {
+	if _, err := NewErrorPages(fstest.MapFS{"404.html": {Data: []byte(`{{`)}}); err == nil {
+		t.Error("expected a parse error")
+	}
+}
+
+func TestProblemErrorHandler(t *testing.T) {
+	g := NewRouter()
+	g.SetErrorHandler(newTestErrorPages(t).HandleError)
+	g.Get("/page", func(w http.ResponseWriter, r *http.Request) {})
+	api := g.Group("/api/{version}")
+	api.SetErrorHandler(ProblemErrorHandler)
+	api.Get("/users", func(w http.ResponseWriter, r *http.Request) {})
+	api.Get("/fail", func(w http.ResponseWriter, r *http.Request) {
+		Error(w, r, errors.New("secret"))
+	})
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+		detail string
+		allow  string
+	}{
+		{"GET", "/api/v1/missing", 404, "404 page not found", ""},
+		{"POST", "/api/v1/users", 405, "Method Not Allowed", "GET, HEAD"},
+		{"GET", "/api/v1/fail", 500, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if got := w.Header().Get("Content-Type"); got != "application/problem+json" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			if got := w.Header().Get("Allow"); got != tt.allow {
+				t.Errorf("Allow = %q, want %q", got, tt.allow)
+			}
+			var p map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+				t.Fatal(err)
+			}
+			if p["status"] != float64(tt.status) || p["title"] != http.StatusText(tt.status) || p["instance"] != tt.path {
+				t.Errorf("problem = %v", p)
+			}
+			if detail, _ := p["detail"].(string); detail != tt.detail {
+				t.Errorf("detail = %q, want %q", detail, tt.detail)
+			}
+		})
+	}
+
+	// Paths outside the API group get the site's HTML pages.
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/apix", nil))
+	if !strings.HasPrefix(w.Body.String(), "not found:") {
+		t.Errorf("body = %q, want the HTML page", w.Body.String())
+	}
+}
+
+func TestUnmatchedWithoutErrorHandler(t *testing.T) {
+	g := NewRouter()
+	g.Get("/users", func(w http.ResponseWriter, r *http.Request) {})
+
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/missing", nil))
+	if w.Code != http.StatusNotFound || w.Body.String() != "404 page not found\n" {
+		t.Errorf("got %d %q", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("DELETE", "/users", nil))
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "GET, HEAD" {
+		t.Errorf("got %d, Allow %q", w.Code, w.Header().Get("Allow"))
+	}
+}
+
+func TestUnmatchedRedirect(t *testing.T) {
+	g := NewRouter()
+	g.SetErrorHandler(ProblemErrorHandler)
+	g.Get("/docs/", func(w http.ResponseWriter, r *http.Request) {})
+
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/docs", nil))
+	if w.Code/100 != 3 || w.Header().Get("Location") != "/docs/" {
+		t.Errorf("got %d, Location %q; want the mux's redirect", w.Code, w.Header().Get("Location"))
+	}
+}
+
+func TestErrorRequestID(t *testing.T) {
+	pages, err := NewErrorPages(fstest.MapFS{
+		"error.html": {Data: []byte(`{{.Code}} ({{.RequestID}})`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fail := func(w http.ResponseWriter, r *http.Request) {
+		Error(w, r, errors.New("secret"))
+	}
+	g := NewRouter()
+	g.Pre(RequestID())
+	g.Get("/users", func(w http.ResponseWriter, r *http.Request) {})
+	g.Get("/fail", fail)
+	g.Get("/off", fail).SetEnabled(false)
+	g.Get("/beta", fail).When(func(r *http.Request) bool { return false })
+	g.Get("/later", fail).ActiveBetween(time.Now().Add(time.Hour), time.Time{})
+	api := g.Group("/api")
+	api.SetErrorHandler(ProblemErrorHandler)
+	api.Get("/fail", fail)
+	site := g.Group("/site")
+	site.SetErrorHandler(pages.HandleError)
+	site.Get("/fail", fail)
+	site.Get("/off", fail).SetEnabled(false)
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+		body   string
+	}{
+		{"GET", "/missing", 404, `"status":404,"detail":"404 page not found","instance":"/missing","request_id":"req-1"}`},
+		{"POST", "/users", 405, `"status":405,"detail":"Method Not Allowed","instance":"/users","request_id":"req-1"}`},
+		{"GET", "/fail", 500, `"status":500,"instance":"/fail","request_id":"req-1"}`},
+		{"GET", "/off", 404, `"status":404,"detail":"404 page not found","instance":"/off","request_id":"req-1"}`},
+		{"GET", "/beta", 404, `"request_id":"req-1"`},
+		{"GET", "/later", 404, `"request_id":"req-1"`},
+		{"GET", "/api/fail", 500, `"request_id":"req-1"`},
+		{"GET", "/api/missing", 404, `"request_id":"req-1"`},
+		{"GET", "/site/fail", 500, "500 (req-1)"},
+		{"GET", "/site/off", 404, "404 (req-1)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set(RequestIDHeader, "req-1")
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, req)
+			if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.body) {
+				t.Errorf("got %d %q, want %d %q", w.Code, w.Body.String(), tt.status, tt.body)
+			}
+		})
+	}
+}
+
+func TestUnmatchedRequestID(t *testing.T) {
+	g := NewRouter()
+	g.Pre(RequestID())
+	g.Get("/users", func(w http.ResponseWriter, r *http.Request) {})
+
+	req := httptest.NewRequest("DELETE", "/users", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "GET, HEAD" || !strings.Contains(w.Body.String(), `"request_id":"req-1"`) {
+		t.Errorf("got %d, Allow %q, body %q", w.Code, w.Header().Get("Allow"), w.Body.String())
+	}
+}
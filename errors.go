@@ -0,0 +1,194 @@
+package groute
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// StatusClientClosedRequest is the nginx-style status recorded for requests
+// the client abandoned before a response was delivered.
+const StatusClientClosedRequest = 499
+
+// ErrClientClosedRequest reports that the client disconnected before the
+// response was complete.
+var ErrClientClosedRequest = errors.New("groute: client closed request")
+
+// ErrorHandler handles an error reported by a handler through Error.
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+
+// StatusError is an error carrying the HTTP status code to respond with.
+type StatusError struct {
+	Code int
+	Err  error
+}
+
+// NewStatusError returns an error responding with code and the given message.
+func NewStatusError(code int, message string) *StatusError {
+	return &StatusError{Code: code, Err: errors.New(message)}
+}
+
+// Error implements the error interface.
+func (e *StatusError) Error() string {
+	if e.Err == nil {
+		return http.StatusText(e.Code)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// SetErrorHandler sets the handler used by Error for routes registered on
+// this router and its groups. A group may set its own handler to override
+// the one inherited from its parent.
+//
+// Once an error handler is set, requests no route matches are answered
+// through it as well, with a 404 Not Found or 405 Method Not Allowed
+// StatusError: the handler of the deepest group whose prefix covers the
+// request path is used, so an API group can answer with JSON while the
+// rest of the site serves HTML pages.
+func (g *Router) SetErrorHandler(handler ErrorHandler) {
+	g.errorHandler = handler
+	g.routes.errorHandlers.Store(true)
+}
+
+// lookupErrorHandler returns the closest error handler set on g or its parents.
+func (g *Router) lookupErrorHandler() ErrorHandler {
+	for r := g; r != nil; r = r.parent {
+		if r.errorHandler != nil {
+			return r.errorHandler
+		}
+	}
+	return DefaultErrorHandler
+}
+
+// errorHandlerForPath returns the error handler for a request to path that
+// no route matched: that of the deepest group whose prefix covers path.
+func (g *Router) errorHandlerForPath(path string) ErrorHandler {
+	t := g.routes
+	t.mu.Lock()
+	groups := t.groups
+	t.mu.Unlock()
+
+	best, bestDepth := g.root(), 0
+	for _, group := range groups {
+		depth, ok := prefixDepth(group.prefix, path)
+		if !ok {
+			continue
+		}
+		// Of several groups with the same prefix, prefer one with a handler.
+		if depth > bestDepth || depth == bestDepth && best.errorHandler == nil && group.errorHandler != nil {
+			best, bestDepth = group, depth
+		}
+	}
+	return best.lookupErrorHandler()
+}
+
+// prefixDepth reports whether the group prefix covers path and how many
+// segments it has. Wildcard segments in the prefix match any segment.
+func prefixDepth(prefix, path string) (int, bool) {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return 0, true
+	}
+	segments := strings.Split(prefix, "/")
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "...}") {
+			return len(segments), true
+		}
+		if i >= len(parts) || seg != parts[i] && !strings.HasPrefix(seg, "{") {
+			return 0, false
+		}
+	}
+	return len(segments), true
+}
+
+// Error reports err for the request, dispatching it to the error handler of
+// the group the matched route was registered on. Server errors (5xx) are
+// also passed to the router's ErrorReporter, if one is set.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	if ErrorStatus(err) >= 500 {
+		reportError(r, err, nil)
+	}
+	errorHandlerFor(r)(w, r, err)
+}
+
+// errorHandlerFor returns the error handler for the route matched for r.
+func errorHandlerFor(r *http.Request) ErrorHandler {
+	if route := CurrentRoute(r); route != nil && route.group != nil {
+		return route.group.lookupErrorHandler()
+	}
+	return DefaultErrorHandler
+}
+
+// ErrorStatus returns the HTTP status code for err: the code of a wrapped
+// StatusError, StatusClientClosedRequest for client disconnects, and 500
+// Internal Server Error otherwise.
+func ErrorStatus(err error) int {
+	var se *StatusError
+	switch {
+	case errors.As(err, &se):
+		return se.Code
+	case errors.Is(err, ErrClientClosedRequest), errors.Is(err, context.Canceled):
+		return StatusClientClosedRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// DefaultErrorHandler writes the status from ErrorStatus with a plain-text body.
+// Client errors carry the error message; server errors only the status text,
+// so internal details are not leaked. For client disconnects only the status
+// is recorded, since nobody is listening for the body.
+//
+// Requests with a request ID are answered like ProblemErrorHandler does
+// instead, so every router-generated error carries the ID in the same
+// "request_id" member for users to quote.
+func DefaultErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	if GetRequestID(r) != "" {
+		ProblemErrorHandler(w, r, err)
+		return
+	}
+	plainErrorHandler(w, r, err)
+}
+
+// plainErrorHandler is DefaultErrorHandler for requests without an ID.
+func plainErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	code := ErrorStatus(err)
+	if code == StatusClientClosedRequest {
+		w.WriteHeader(code)
+		return
+	}
+	msg := http.StatusText(code)
+	if code < 500 {
+		msg = err.Error()
+	}
+	http.Error(w, msg, code)
+}
+
+// Disconnected returns ErrClientClosedRequest if the client has gone away,
+// and nil otherwise. Long-running handlers can poll it between steps:
+//
+//	if err := groute.Disconnected(r); err != nil {
+//		groute.Error(w, r, err)
+//		return
+//	}
+func Disconnected(r *http.Request) error {
+	if errors.Is(r.Context().Err(), context.Canceled) {
+		return ErrClientClosedRequest
+	}
+	return nil
+}
+
+// OnDisconnect arranges for fn to run in its own goroutine as soon as the
+// request context is canceled, typically because the client disconnected.
+// Calling stop unregisters fn; it reports whether fn was stopped before it ran.
+// Handlers should defer stop so fn does not fire once the response is complete.
+func OnDisconnect(r *http.Request, fn func()) (stop func() bool) {
+	return context.AfterFunc(r.Context(), fn)
+}
@@ -0,0 +1,132 @@
+package groute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"status error", NewStatusError(http.StatusBadRequest, "bad"), http.StatusBadRequest},
+		{"wrapped status error", fmt.Errorf("binding: %w", NewStatusError(http.StatusConflict, "dup")), http.StatusConflict},
+		{"client closed", ErrClientClosedRequest, StatusClientClosedRequest},
+		{"context canceled", context.Canceled, StatusClientClosedRequest},
+		{"other", errors.New("boom"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorStatus(tt.err); got != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestErrorUsesGroupHandler(t *testing.T) {
+	g := NewRouter()
+	var handledBy string
+
+	g.SetErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		handledBy = "root"
+		w.WriteHeader(ErrorStatus(err))
+	})
+	api := g.Group("/api")
+	admin := g.Group("/admin")
+	admin.SetErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		handledBy = "admin"
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	failing := func(w http.ResponseWriter, r *http.Request) {
+		Error(w, r, NewStatusError(http.StatusBadRequest, "bad input"))
+	}
+	api.Get("/x", failing)
+	admin.Get("/x", failing)
+
+	tests := []struct {
+		path           string
+		expectedBy     string
+		expectedStatus int
+	}{
+		{"/api/x", "root", http.StatusBadRequest},
+		{"/admin/x", "admin", http.StatusTeapot},
+	}
+	for _, tt := range tests {
+		handledBy = ""
+		req := httptest.NewRequest("GET", tt.path, nil)
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, req)
+
+		if handledBy != tt.expectedBy {
+			t.Errorf("%s: expected handler %q, got %q", tt.path, tt.expectedBy, handledBy)
+		}
+		if w.Code != tt.expectedStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.expectedStatus, w.Code)
+		}
+	}
+}
+
+func TestErrorDefaultHandler(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	Error(w, req, errors.New("boom"))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", w.Code)
+	}
+}
+
+func TestDisconnected(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+
+	if err := Disconnected(req); err != nil {
+		t.Errorf("expected nil error before disconnect, got %v", err)
+	}
+	cancel()
+	if err := Disconnected(req); !errors.Is(err, ErrClientClosedRequest) {
+		t.Errorf("expected ErrClientClosedRequest, got %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	Error(w, req, Disconnected(req))
+	if w.Code != StatusClientClosedRequest {
+		t.Errorf("expected status 499, got %d", w.Code)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("expected empty body, got %q", w.Body.String())
+	}
+}
+
+func TestOnDisconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+
+	fired := make(chan struct{})
+	OnDisconnect(req, func() { close(fired) })
+	cancel()
+
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		t.Fatal("disconnect callback was not called")
+	}
+
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	req2 := httptest.NewRequest("GET", "/", nil).WithContext(ctx2)
+	stop := OnDisconnect(req2, func() { t.Error("stopped callback should not run") })
+	if !stop() {
+		t.Error("expected stop to report the callback was unregistered")
+	}
+}
@@ -0,0 +1,288 @@
+package groute
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Metadata keys read by the edge config exporters.
+const (
+	// MetaHost is the host name the route is served on, as a string.
+	MetaHost = "host"
+	// MetaTimeout is the upstream timeout for the route, as a time.Duration.
+	MetaTimeout = "timeout"
+	// MetaAuth is the authentication tag of the route, e.g. "jwt", as a string.
+	MetaAuth = "auth"
+)
+
+// ExportOptions configures the edge config exporters.
+type ExportOptions struct {
+	// Name names the generated resources. Defaults to "app".
+	Name string
+	// Namespace is the Kubernetes namespace of the generated resources.
+	Namespace string
+	// Gateway is the Gateway the HTTPRoutes attach to.
+	Gateway string
+	// Service and Port are the backend Service of the HTTPRoutes.
+	// Service defaults to Name.
+	Service string
+	Port    int
+	// Upstream is the nginx proxy_pass target, e.g. "http://app".
+	Upstream string
+	// AuthRequest maps auth tags to nginx auth_request URIs.
+	AuthRequest map[string]string
+}
+
+// edgeRoute is a route as seen by an edge proxy.
+type edgeRoute struct {
+	host    string
+	match   string // "Exact" or "PathPrefix"
+	path    string
+	methods []string
+	timeout time.Duration
+	auth    string
+}
+
+// edgeRoutes converts the route table into edge routes sorted by host and
+// path. Routes with wildcards are exported as a prefix match on their
+// literal part; the router still does the exact matching behind the proxy.
+// The host of a host-qualified pattern takes precedence over MetaHost.
+// Routes sharing a host, path and auth tag are merged, keeping the longest
+// timeout; routes with different auth tags stay apart.
+func (g *Router) edgeRoutes() []*edgeRoute {
+	var routes []*edgeRoute
+	index := make(map[[4]string]*edgeRoute)
+	for _, route := range g.Routes() {
+		host, _ := route.Value(MetaHost)
+		timeout, _ := route.Value(MetaTimeout)
+		auth, _ := route.Value(MetaAuth)
+		er := &edgeRoute{}
+		er.host, _ = host.(string)
+		er.auth, _ = auth.(string)
+		patternHost, path := splitHostPath(route.Path())
+		if patternHost != "" {
+			er.host = patternHost
+		}
+		er.match, er.path = edgeMatch(path)
+		key := [4]string{er.host, er.match, er.path, er.auth}
+		if prev, ok := index[key]; ok {
+			er = prev
+		} else {
+			index[key] = er
+			routes = append(routes, er)
+		}
+		if route.Method() != "" {
+			er.methods = append(er.methods, route.Method())
+		}
+		if d, ok := timeout.(time.Duration); ok && d > er.timeout {
+			er.timeout = d
+		}
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].host != routes[j].host {
+			return routes[i].host < routes[j].host
+		}
+		return routes[i].path < routes[j].path
+	})
+	return routes
+}
+
+// edgeMatch returns the match type and path for a ServeMux path pattern.
+func edgeMatch(pattern string) (match, path string) {
+	if i := strings.IndexByte(pattern, '{'); i >= 0 {
+		if rest := pattern[i:]; rest == "{$}" {
+			return "Exact", pattern[:i]
+		}
+		return "PathPrefix", pattern[:i]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		return "PathPrefix", pattern
+	}
+	return "Exact", pattern
+}
+
+// edgeDuration formats d in a form both nginx and the Gateway API accept.
+func edgeDuration(d time.Duration) string {
+	if d%time.Second == 0 {
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
+
+// ExportHTTPRoute writes Kubernetes Gateway API HTTPRoute resources for the
+// router's routes, one per host, so edge config can be generated from the
+// application and kept in sync with it. Routes read their host, timeout and
+// auth tag from the MetaHost, MetaTimeout and MetaAuth metadata:
+//
+//	r.Get("/users/{id}", showUser).Meta(groute.MetaHost, "api.example.com").
+//		Meta(groute.MetaTimeout, 5*time.Second)
+//
+// Routes with the same timeout and auth tag share a rule; auth tags are
+// written as comments for policy tooling to pick up.
+func (g *Router) ExportHTTPRoute(w io.Writer, opts ExportOptions) error {
+	opts = opts.withDefaults()
+	var b strings.Builder
+	routes := g.edgeRoutes()
+	for i := 0; i < len(routes); {
+		host := routes[i].host
+		j := i
+		for j < len(routes) && routes[j].host == host {
+			j++
+		}
+		if b.Len() > 0 {
+			b.WriteString("---\n")
+		}
+		writeHTTPRoute(&b, opts, host, routes[i:j])
+		i = j
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeHTTPRoute writes one HTTPRoute for the routes of host.
+func writeHTTPRoute(b *strings.Builder, opts ExportOptions, host string, routes []*edgeRoute) {
+	name := opts.Name
+	if host != "" {
+		name += "-" + strings.ReplaceAll(host, "*", "wildcard")
+	}
+	name = dnsName(name)
+	fmt.Fprintf(b, "apiVersion: gateway.networking.k8s.io/v1\nkind: HTTPRoute\nmetadata:\n  name: %s\n", name)
+	if opts.Namespace != "" {
+		fmt.Fprintf(b, "  namespace: %s\n", opts.Namespace)
+	}
+	b.WriteString("spec:\n")
+	if opts.Gateway != "" {
+		fmt.Fprintf(b, "  parentRefs:\n  - name: %s\n", opts.Gateway)
+	}
+	if host != "" {
+		// Gateway API hostnames are lower case and carry no port.
+		fmt.Fprintf(b, "  hostnames:\n  - %q\n", strings.ToLower(hostWithoutPort(host)))
+	}
+	b.WriteString("  rules:\n")
+
+	type ruleKey struct {
+		timeout time.Duration
+		auth    string
+	}
+	var keys []ruleKey
+	rules := make(map[ruleKey][]*edgeRoute)
+	for _, er := range routes {
+		key := ruleKey{er.timeout, er.auth}
+		if _, ok := rules[key]; !ok {
+			keys = append(keys, key)
+		}
+		rules[key] = append(rules[key], er)
+	}
+	for _, key := range keys {
+		if key.auth != "" {
+			fmt.Fprintf(b, "  # auth: %s\n", key.auth)
+		}
+		b.WriteString("  - matches:\n")
+		for _, er := range rules[key] {
+			methods := er.methods
+			if len(methods) == 0 {
+				methods = []string{""}
+			}
+			for _, method := range methods {
+				fmt.Fprintf(b, "    - path:\n        type: %s\n        value: %q\n", er.match, er.path)
+				if method != "" {
+					fmt.Fprintf(b, "      method: %s\n", method)
+				}
+			}
+		}
+		fmt.Fprintf(b, "    backendRefs:\n    - name: %s\n", opts.Service)
+		if opts.Port != 0 {
+			fmt.Fprintf(b, "      port: %d\n", opts.Port)
+		}
+		if key.timeout > 0 {
+			fmt.Fprintf(b, "    timeouts:\n      request: %s\n", edgeDuration(key.timeout))
+		}
+	}
+}
+
+// ExportNginx writes nginx location blocks for the router's routes, grouped
+// by host, to be included in the matching server blocks. Hosts, timeouts
+// and auth tags come from route metadata as for ExportHTTPRoute; auth tags
+// listed in opts.AuthRequest become auth_request directives.
+//
+// A location applies to every method, so ExportNginx fails if routes on
+// the same path have different auth tags.
+func (g *Router) ExportNginx(w io.Writer, opts ExportOptions) error {
+	opts = opts.withDefaults()
+	var b strings.Builder
+	host := ""
+	routes := g.edgeRoutes()
+	for i, er := range routes {
+		if i > 0 {
+			if prev := routes[i-1]; prev.host == er.host && prev.match == er.match && prev.path == er.path {
+				return fmt.Errorf("groute: routes for %s%s have auth tags %q and %q; one nginx location cannot apply them per method", er.host, er.path, prev.auth, er.auth)
+			}
+		}
+		if i == 0 || er.host != host {
+			host = er.host
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			if host != "" {
+				fmt.Fprintf(&b, "# server_name %s\n", host)
+			}
+		}
+		modifier := "="
+		if er.match == "PathPrefix" {
+			modifier = "^~"
+		}
+		fmt.Fprintf(&b, "location %s %s {\n", modifier, er.path)
+		if len(er.methods) > 0 {
+			fmt.Fprintf(&b, "    # methods: %s\n", strings.Join(er.methods, ", "))
+		}
+		if er.auth != "" {
+			fmt.Fprintf(&b, "    # auth: %s\n", er.auth)
+			if uri, ok := opts.AuthRequest[er.auth]; ok {
+				fmt.Fprintf(&b, "    auth_request %s;\n", uri)
+			}
+		}
+		if er.timeout > 0 {
+			fmt.Fprintf(&b, "    proxy_read_timeout %s;\n", edgeDuration(er.timeout))
+		}
+		if opts.Upstream != "" {
+			fmt.Fprintf(&b, "    proxy_pass %s;\n", opts.Upstream)
+		}
+		b.WriteString("}\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// withDefaults fills in the default resource and service names.
+func (o ExportOptions) withDefaults() ExportOptions {
+	if o.Name == "" {
+		o.Name = "app"
+	}
+	if o.Service == "" {
+		o.Service = o.Name
+	}
+	return o
+}
+
+// dnsName turns s into a DNS-1123 subdomain, as Kubernetes requires of
+// resource names: lower case letters, digits and dashes, starting and
+// ending with a letter or digit, at most 253 characters. Dots are replaced
+// too, so that hosts yield a single readable label.
+func dnsName(s string) string {
+	name := strings.Map(func(c rune) rune {
+		switch {
+		case 'a' <= c && c <= 'z', '0' <= c && c <= '9':
+			return c
+		case 'A' <= c && c <= 'Z':
+			return c + 'a' - 'A'
+		}
+		return '-'
+	}, s)
+	if len(name) > 253 {
+		name = name[:253]
+	}
+	return strings.Trim(name, "-")
+}
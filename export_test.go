@@ -0,0 +1,173 @@
+package groute
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newExportRouter() *Router {
+	h := func(w http.ResponseWriter, r *http.Request) {}
+	g := NewRouter()
+	g.Get("/healthz", h)
+	// Handle joins patterns to the group prefix, so add a host-qualified
+	// route to the table directly.
+	g.routes.add(newRoute("GET admin.example.com/stats"))
+	api := g.Group("/api")
+	api.Get("/users/{id}", h).Meta(MetaHost, "api.example.com").Meta(MetaTimeout, 5*time.Second).Name("users.show")
+	api.Delete("/users/{id}", h).Meta(MetaHost, "api.example.com").Meta(MetaAuth, "jwt")
+	api.Get("/reports/{$}", h).Meta(MetaHost, "api.example.com").Meta(MetaTimeout, 1500*time.Millisecond)
+	return g
+}
+
+func TestExportHTTPRoute(t *testing.T) {
+	var b strings.Builder
+	err := newExportRouter().ExportHTTPRoute(&b, ExportOptions{Name: "shop", Namespace: "prod", Gateway: "edge", Port: 8080})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: shop
+  namespace: prod
+spec:
+  parentRefs:
+  - name: edge
+  rules:
+  - matches:
+    - path:
+        type: Exact
+        value: "/healthz"
+      method: GET
+    backendRefs:
+    - name: shop
+      port: 8080
+---
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: shop-admin-example-com
+  namespace: prod
+spec:
+  parentRefs:
+  - name: edge
+  hostnames:
+  - "admin.example.com"
+  rules:
+  - matches:
+    - path:
+        type: Exact
+        value: "/stats"
+      method: GET
+    backendRefs:
+    - name: shop
+      port: 8080
+---
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: shop-api-example-com
+  namespace: prod
+spec:
+  parentRefs:
+  - name: edge
+  hostnames:
+  - "api.example.com"
+  rules:
+  - matches:
+    - path:
+        type: Exact
+        value: "/api/reports/"
+      method: GET
+    backendRefs:
+    - name: shop
+      port: 8080
+    timeouts:
+      request: 1500ms
+  - matches:
+    - path:
+        type: PathPrefix
+        value: "/api/users/"
+      method: GET
+    backendRefs:
+    - name: shop
+      port: 8080
+    timeouts:
+      request: 5s
+  # auth: jwt
+  - matches:
+    - path:
+        type: PathPrefix
+        value: "/api/users/"
+      method: DELETE
+    backendRefs:
+    - name: shop
+      port: 8080
+`
+	if b.String() != expected {
+		t.Errorf("unexpected output:\n%s", b.String())
+	}
+}
+
+func TestExportHTTPRouteHostPort(t *testing.T) {
+	g := NewRouter()
+	g.Get("/", func(w http.ResponseWriter, r *http.Request) {}).Meta(MetaHost, "Staging.Example.com:8443")
+	var b strings.Builder
+	if err := g.ExportHTTPRoute(&b, ExportOptions{Name: "Shop_App"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"  name: shop-app-staging-example-com-8443\n", "  hostnames:\n  - \"staging.example.com\"\n"} {
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("expected output to contain %q, got:\n%s", line, b.String())
+		}
+	}
+}
+
+func TestExportNginx(t *testing.T) {
+	g := newExportRouter()
+	var b strings.Builder
+	err := g.ExportNginx(&b, ExportOptions{}) // DELETE /api/users/ needs jwt, GET does not
+	if err == nil || !strings.Contains(err.Error(), "auth tags") {
+		t.Fatalf("expected an error about differing auth tags, got %v", err)
+	}
+	g.RouteByName("users.show").Meta(MetaAuth, "jwt")
+
+	b.Reset()
+	err = g.ExportNginx(&b, ExportOptions{
+		Upstream:    "http://shop",
+		AuthRequest: map[string]string{"jwt": "/_auth/jwt"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `location = /healthz {
+    # methods: GET
+    proxy_pass http://shop;
+}
+
+# server_name admin.example.com
+location = /stats {
+    # methods: GET
+    proxy_pass http://shop;
+}
+
+# server_name api.example.com
+location = /api/reports/ {
+    # methods: GET
+    proxy_read_timeout 1500ms;
+    proxy_pass http://shop;
+}
+location ^~ /api/users/ {
+    # methods: GET, DELETE
+    # auth: jwt
+    auth_request /_auth/jwt;
+    proxy_read_timeout 5s;
+    proxy_pass http://shop;
+}
+`
+	if b.String() != expected {
+		t.Errorf("unexpected output:\n%s", b.String())
+	}
+}
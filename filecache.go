@@ -0,0 +1,272 @@
+package groute
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"io/fs"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// FileCache is an fs.FS that keeps small, frequently served files in memory.
+//
+// Cached entries are revalidated against the underlying file system at most
+// once per Revalidate interval by comparing size and modification time, so hot
+// assets are served without a stat per request. Concurrent misses for the same
+// file are coalesced into a single read.
+//
+// Use it with http.FileServerFS or any other consumer of fs.FS:
+//
+//	cache := groute.NewFileCache(os.DirFS("public"), 32<<20)
+//	r.Handle("/static/", http.StripPrefix("/static/", http.FileServerFS(cache)))
+type FileCache struct {
+	// MaxFileSize is the largest file kept in memory. Larger files are
+	// always read from the underlying file system. Defaults to 1 MiB.
+	MaxFileSize int64
+	// Revalidate is how long a cached entry is trusted before its size
+	// and modification time are checked again. Defaults to one second.
+	Revalidate time.Duration
+	// Clock is used to expire entries. Defaults to the real clock.
+	Clock Clock
+
+	fsys     fs.FS
+	maxBytes int64
+
+	mu       sync.Mutex
+	entries  map[string]*list.Element
+	lru      *list.List
+	used     int64
+	inflight map[string]*fileCacheCall
+
+	hits     atomic.Uint64
+	misses   atomic.Uint64
+	bypasses atomic.Uint64
+}
+
+// FileCacheStats reports the effectiveness of a FileCache. Bypasses counts
+// opens of files that are never cached, such as directories and files
+// larger than MaxFileSize; they are not misses.
+type FileCacheStats struct {
+	Hits     uint64
+	Misses   uint64
+	Bypasses uint64
+	Entries  int
+	Bytes    int64
+}
+
+// HitRate returns the fraction of cacheable lookups served from memory.
+func (s FileCacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+type fileCacheEntry struct {
+	name      string
+	data      []byte
+	info      fs.FileInfo
+	checkedAt time.Time
+}
+
+type fileCacheCall struct {
+	wg    sync.WaitGroup
+	entry *fileCacheEntry
+	err   error
+}
+
+// NewFileCache creates a cache over fsys holding at most maxBytes of file content.
+func NewFileCache(fsys fs.FS, maxBytes int64) *FileCache {
+	return &FileCache{
+		MaxFileSize: 1 << 20,
+		Revalidate:  time.Second,
+		fsys:        fsys,
+		maxBytes:    maxBytes,
+		entries:     make(map[string]*list.Element),
+		lru:         list.New(),
+		inflight:    make(map[string]*fileCacheCall),
+	}
+}
+
+// Open implements fs.FS.
+func (c *FileCache) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if entry := c.lookup(name); entry != nil {
+		c.hits.Add(1)
+		return newMemFile(entry), nil
+	}
+
+	entry, f, err := c.load(name)
+	switch {
+	case err != nil:
+		c.misses.Add(1)
+		return nil, err
+	case entry == nil:
+		// Not cacheable (directory or too large).
+		c.bypasses.Add(1)
+		if f != nil {
+			return f, nil
+		}
+		return c.fsys.Open(name)
+	}
+	c.misses.Add(1)
+	return newMemFile(entry), nil
+}
+
+// Stats returns a snapshot of the cache counters.
+func (c *FileCache) Stats() FileCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return FileCacheStats{
+		Hits:     c.hits.Load(),
+		Misses:   c.misses.Load(),
+		Bypasses: c.bypasses.Load(),
+		Entries:  len(c.entries),
+		Bytes:    c.used,
+	}
+}
+
+// Purge drops all cached entries.
+func (c *FileCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+	c.used = 0
+}
+
+// lookup returns a valid cached entry for name, or nil.
+func (c *FileCache) lookup(name string) *fileCacheEntry {
+	c.mu.Lock()
+	elem, ok := c.entries[name]
+	if !ok {
+		c.mu.Unlock()
+		return nil
+	}
+	entry := elem.Value.(*fileCacheEntry)
+	c.lru.MoveToFront(elem)
+	if c.now().Sub(entry.checkedAt) < c.Revalidate {
+		c.mu.Unlock()
+		return entry
+	}
+	c.mu.Unlock()
+
+	info, err := fs.Stat(c.fsys, name)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil || info.Size() != entry.info.Size() || !info.ModTime().Equal(entry.info.ModTime()) {
+		c.removeLocked(name)
+		return nil
+	}
+	entry.checkedAt = c.now()
+	return entry
+}
+
+// load reads name into the cache, coalescing concurrent loads of the same file.
+// It returns a nil entry when the file should not be cached, along with the
+// file opened to find out, unless the load was coalesced with another.
+func (c *FileCache) load(name string) (*fileCacheEntry, fs.File, error) {
+	c.mu.Lock()
+	if call, ok := c.inflight[name]; ok {
+		c.mu.Unlock()
+		call.wg.Wait()
+		return call.entry, nil, call.err
+	}
+	call := &fileCacheCall{}
+	call.wg.Add(1)
+	c.inflight[name] = call
+	c.mu.Unlock()
+
+	var f fs.File
+	call.entry, f, call.err = c.read(name)
+
+	c.mu.Lock()
+	delete(c.inflight, name)
+	if call.entry != nil {
+		c.storeLocked(call.entry)
+	}
+	c.mu.Unlock()
+	call.wg.Done()
+
+	return call.entry, f, call.err
+}
+
+// read loads name from the underlying file system. Files that should not be
+// cached are returned open instead, for the caller to serve.
+func (c *FileCache) read(name string) (*fileCacheEntry, fs.File, error) {
+	f, err := c.fsys.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if info.IsDir() || info.Size() > c.MaxFileSize || info.Size() > c.maxBytes {
+		return nil, f, nil
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &fileCacheEntry{
+		name:      name,
+		data:      data,
+		info:      info,
+		checkedAt: c.now(),
+	}, nil, nil
+}
+
+// now returns the current time according to c.Clock.
+func (c *FileCache) now() time.Time {
+	if c.Clock != nil {
+		return c.Clock.Now()
+	}
+	return time.Now()
+}
+
+// storeLocked adds entry, evicting least recently used entries to make room.
+func (c *FileCache) storeLocked(entry *fileCacheEntry) {
+	c.removeLocked(entry.name)
+	size := int64(len(entry.data))
+	for c.used+size > c.maxBytes && c.lru.Len() > 0 {
+		oldest := c.lru.Back().Value.(*fileCacheEntry)
+		c.removeLocked(oldest.name)
+	}
+	c.entries[entry.name] = c.lru.PushFront(entry)
+	c.used += size
+}
+
+// removeLocked drops name from the cache if present.
+func (c *FileCache) removeLocked(name string) {
+	elem, ok := c.entries[name]
+	if !ok {
+		return
+	}
+	entry := c.lru.Remove(elem).(*fileCacheEntry)
+	delete(c.entries, name)
+	c.used -= int64(len(entry.data))
+}
+
+// memFile is an fs.File backed by a cached entry.
+type memFile struct {
+	*bytes.Reader
+	info fs.FileInfo
+}
+
+func newMemFile(entry *fileCacheEntry) *memFile {
+	return &memFile{Reader: bytes.NewReader(entry.data), info: entry.info}
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+func (f *memFile) Close() error { return nil }
@@ -0,0 +1,137 @@
+package groute
+
+import (
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func readCached(t *testing.T, c *FileCache, name string) string {
+	t.Helper()
+	f, err := c.Open(name)
+	if err != nil {
+		t.Fatalf("Open(%q) failed: %v", name, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("read %q failed: %v", name, err)
+	}
+	return string(data)
+}
+
+func TestFileCacheHits(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.js": {Data: []byte("console.log(1)"), ModTime: time.Unix(1, 0)},
+	}
+	c := NewFileCache(fsys, 1024)
+
+	for i := 0; i < 3; i++ {
+		if got := readCached(t, c, "app.js"); got != "console.log(1)" {
+			t.Errorf("expected cached content, got %q", got)
+		}
+	}
+
+	stats := c.Stats()
+	if stats.Misses != 1 || stats.Hits != 2 {
+		t.Errorf("expected 1 miss and 2 hits, got %+v", stats)
+	}
+	if stats.Entries != 1 || stats.Bytes != int64(len("console.log(1)")) {
+		t.Errorf("unexpected cache size: %+v", stats)
+	}
+	if rate := stats.HitRate(); rate < 0.66 || rate > 0.67 {
+		t.Errorf("expected hit rate 2/3, got %f", rate)
+	}
+}
+
+func TestFileCacheRevalidate(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.js": {Data: []byte("v1"), ModTime: time.Unix(1, 0)},
+	}
+	c := NewFileCache(fsys, 1024)
+	c.Revalidate = 0
+
+	if got := readCached(t, c, "app.js"); got != "v1" {
+		t.Fatalf("expected v1, got %q", got)
+	}
+
+	fsys["app.js"] = &fstest.MapFile{Data: []byte("v2!"), ModTime: time.Unix(2, 0)}
+	if got := readCached(t, c, "app.js"); got != "v2!" {
+		t.Errorf("expected changed file to be reloaded, got %q", got)
+	}
+}
+
+// countingFS counts the files opened on an fs.FS.
+type countingFS struct {
+	fs.FS
+	opens int
+}
+
+func (c *countingFS) Open(name string) (fs.File, error) {
+	c.opens++
+	return c.FS.Open(name)
+}
+
+func TestFileCacheBypassAndEviction(t *testing.T) {
+	fsys := &countingFS{FS: fstest.MapFS{
+		"big.bin": {Data: make([]byte, 64)},
+		"a.txt":   {Data: []byte("aaaaaaaaaa")},
+		"b.txt":   {Data: []byte("bbbbbbbbbb")},
+	}}
+	c := NewFileCache(fsys, 16)
+	c.MaxFileSize = 32
+
+	if got := readCached(t, c, "big.bin"); len(got) != 64 {
+		t.Errorf("expected large file to be served, got %d bytes", len(got))
+	}
+	if stats := c.Stats(); stats.Entries != 0 || stats.Bypasses != 1 || stats.Misses != 0 {
+		t.Errorf("large file should bypass the cache, got %+v", stats)
+	}
+	if fsys.opens != 1 {
+		t.Errorf("large file opened %d times, want 1", fsys.opens)
+	}
+
+	readCached(t, c, "a.txt")
+	readCached(t, c, "b.txt")
+	stats := c.Stats()
+	if stats.Entries != 1 || stats.Bytes != 10 || stats.Misses != 2 {
+		t.Errorf("expected least recently used entry to be evicted, got %+v", stats)
+	}
+
+	c.Purge()
+	if c.Stats().Entries != 0 {
+		t.Error("expected Purge to drop all entries")
+	}
+}
+
+func TestFileCacheWithFileServer(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html": {Data: []byte("<h1>hi</h1>")},
+		"style.css":  {Data: []byte("body{}")},
+	}
+	c := NewFileCache(fsys, 1024)
+
+	g := NewRouter()
+	g.Handle("/static/", http.StripPrefix("/static/", http.FileServerFS(c)))
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("GET", "/static/style.css", nil)
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("expected status 200, got %d", w.Code)
+		}
+		if w.Body.String() != "body{}" {
+			t.Errorf("expected file content, got %q", w.Body.String())
+		}
+	}
+
+	if c.Stats().Hits == 0 {
+		t.Error("expected repeated requests to hit the cache")
+	}
+}
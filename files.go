@@ -0,0 +1,174 @@
+package groute
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// filesParam is the wildcard name used by Files routes.
+const filesParam = "filepath"
+
+// Files serves the files of fsys below pattern, relative to the group:
+//
+//	api := r.Group("/app")
+//	api.Files("/static", os.DirFS("public")) // GET /app/static/css/site.css -> public/css/site.css
+//
+// The file name is taken from the route's wildcard, so no StripPrefix math
+// with the group prefix is needed. Directories are served through their
+// index.html (requests without a trailing slash are redirected to it), and
+// missing files fall through to the group's error handler as 404 Not Found
+// rather than http.FileServer's plain response. Directory listings are
+// never generated.
+//
+// Fingerprinted files, whose names carry a content hash such as
+// "app.3f9a2c1d.js", are served with a one-year immutable Cache-Control.
+// Other files get an ETag derived from their size and modification time
+// and "no-cache", so clients revalidate them on each use. Headers set by
+// earlier middlewares are left alone.
+func (g *Router) Files(pattern string, fsys fs.FS) *Route {
+	prefix := strings.TrimRight(pattern, "/")
+	return g.Get(prefix+"/{"+filesParam+"...}", func(w http.ResponseWriter, r *http.Request) {
+		serveFiles(w, r, fsys, r.PathValue(filesParam))
+	})
+}
+
+// SPA serves the single-page app in fsys below pattern, relative to the
+// group. Files are served as with Files, and paths without a file extension
+// that match no file get the app's index.html, so client-side routes work
+// on reload; missing assets still get 404 Not Found.
+//
+// Serving index.html for an unknown path is a soft 404: it succeeds even
+// when a link is broken. Such responses are reported by Soft404, counted by
+// Metrics and marked in AccessLog records; see also Route.Soft404Header.
+func (g *Router) SPA(pattern string, fsys fs.FS) *Route {
+	prefix := strings.TrimRight(pattern, "/")
+	route := g.Get(prefix+"/{"+filesParam+"...}", func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(path.Clean("/"+r.PathValue(filesParam)), "/")
+		if name != "" && path.Ext(name) == "" {
+			if _, err := fs.Stat(fsys, name); errors.Is(err, fs.ErrNotExist) {
+				markSoft404(w, r)
+				name = "index.html"
+			}
+		}
+		serveFiles(w, r, fsys, name)
+	})
+	route.spa = true
+	return route
+}
+
+// Soft404Header makes the route add an X-Soft-404: 1 header to soft 404
+// responses, so broken links stand out in browser developer tools. Enable it
+// in development only.
+func (rt *Route) Soft404Header() *Route {
+	rt.update(func(info *routeInfo) {
+		info.soft404Header = true
+	})
+	return rt
+}
+
+// soft404Key is the context key for the *soft404Mark of a request to an
+// SPA route.
+type soft404Key struct{}
+
+// soft404Mark records whether the response to a request is a soft 404.
+type soft404Mark struct {
+	marked bool
+}
+
+// Soft404 reports whether the response to r was a soft 404: an SPA route
+// serving its index.html for a path matching no file. Middlewares call it
+// after the handler returned.
+func Soft404(r *http.Request) bool {
+	m, ok := r.Context().Value(soft404Key{}).(*soft404Mark)
+	return ok && m.marked
+}
+
+// markSoft404 marks the response to r as a soft 404.
+func markSoft404(w http.ResponseWriter, r *http.Request) {
+	if m, ok := r.Context().Value(soft404Key{}).(*soft404Mark); ok {
+		m.marked = true
+	}
+	if CurrentRoute(r).info.Load().soft404Header {
+		w.Header().Set("X-Soft-404", "1")
+	}
+}
+
+// serveFiles serves name from fsys, resolving directory index files.
+func serveFiles(w http.ResponseWriter, r *http.Request, fsys fs.FS, name string) {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" {
+		name = "."
+	}
+
+	info, err := fs.Stat(fsys, name)
+	if err == nil && info.IsDir() {
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			http.Redirect(w, r, path.Base(r.URL.Path)+"/", http.StatusMovedPermanently)
+			return
+		}
+		name = path.Join(name, "index.html")
+		info, err = fs.Stat(fsys, name)
+	}
+	if err != nil || info.IsDir() {
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			Error(w, r, err)
+			return
+		}
+		Error(w, r, NewStatusError(http.StatusNotFound, "404 page not found"))
+		return
+	}
+
+	f, err := fsys.Open(name)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+	defer f.Close()
+	setFileCaching(w.Header(), name, info)
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		// Fall back to the standard implementation, which copies the file.
+		http.ServeFileFS(w, r, fsys, name)
+		return
+	}
+	http.ServeContent(w, r, info.Name(), info.ModTime(), content)
+}
+
+// setFileCaching sets the caching headers for serving the file name.
+func setFileCaching(h http.Header, name string, info fs.FileInfo) {
+	if h.Get("Cache-Control") != "" {
+		return
+	}
+	if isFingerprinted(name) {
+		h.Set("Cache-Control", "public, max-age=31536000, immutable")
+		return
+	}
+	h.Set("Cache-Control", "no-cache")
+	if h.Get("ETag") == "" {
+		h.Set("ETag", fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()))
+	}
+}
+
+// isFingerprinted reports whether the base name of file contains a content
+// hash: a segment delimited by dots or dashes of at least 8 lowercase hex
+// digits mixing decimal digits and letters, as in "app.3f9a2c1d.js" or
+// "app-3f9a2c1d.css". Dates and numbers such as "report-20241018.pdf", and
+// words such as "deadbeef.js", are not hashes.
+func isFingerprinted(file string) bool {
+	base := path.Base(file)
+	ext := path.Ext(base)
+	for _, segment := range strings.FieldsFunc(strings.TrimSuffix(base, ext), func(c rune) bool {
+		return c == '.' || c == '-'
+	}) {
+		if len(segment) >= 8 && strings.Trim(segment, "0123456789abcdef") == "" &&
+			strings.ContainsAny(segment, "0123456789") && strings.ContainsAny(segment, "abcdef") {
+			return true
+		}
+	}
+	return false
+}
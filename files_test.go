@@ -0,0 +1,197 @@
+package groute
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":      {Data: []byte("home")},
+		"css/site.css":    {Data: []byte("body{}")},
+		"docs/index.html": {Data: []byte("docs")},
+		"empty/.keep":     {Data: []byte("")},
+	}
+
+	g := NewRouter()
+	app := g.Group("/app")
+	app.SetErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		w.WriteHeader(ErrorStatus(err))
+		_, _ = w.Write([]byte("custom not found"))
+	})
+	app.Files("/static/", fsys)
+
+	tests := []struct {
+		name             string
+		path             string
+		expectedStatus   int
+		expectedBody     string
+		expectedLocation string
+	}{
+		{"file", "/app/static/css/site.css", http.StatusOK, "body{}", ""},
+		{"root index", "/app/static/", http.StatusOK, "home", ""},
+		{"explicit index", "/app/static/index.html", http.StatusOK, "home", ""},
+		{"directory index", "/app/static/docs/", http.StatusOK, "docs", ""},
+		{"directory redirect", "/app/static/docs", http.StatusMovedPermanently, "", "/app/static/docs/"},
+		{"missing file", "/app/static/missing.js", http.StatusNotFound, "custom not found", ""},
+		{"directory without index", "/app/static/empty/", http.StatusNotFound, "custom not found", ""},
+		{"encoded traversal", "/app/static/..%2F..%2Fcss%2Fsite.css", http.StatusOK, "body{}", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedBody != "" && w.Body.String() != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+			if tt.expectedLocation != "" && w.Header().Get("Location") != tt.expectedLocation {
+				t.Errorf("expected Location %q, got %q", tt.expectedLocation, w.Header().Get("Location"))
+			}
+		})
+	}
+}
+
+func TestFilesContentType(t *testing.T) {
+	g := NewRouter()
+	g.Files("/assets", fstest.MapFS{"app.js": {Data: []byte("1")}})
+
+	req := httptest.NewRequest("GET", "/assets/app.js", nil)
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/javascript; charset=utf-8" {
+		t.Errorf("expected javascript content type, got %q", ct)
+	}
+}
+
+func TestFilesCaching(t *testing.T) {
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	g := NewRouter()
+	g.Files("/assets", fstest.MapFS{
+		"app.js":              {Data: []byte("1"), ModTime: modTime},
+		"app.3f9a2c1d.js":     {Data: []byte("2"), ModTime: modTime},
+		"site-0123abcdef.css": {Data: []byte("3"), ModTime: modTime},
+		"release-notes.txt":   {Data: []byte("4"), ModTime: modTime},
+	})
+	etag := fmt.Sprintf(`"%x-1"`, modTime.UnixNano())
+
+	tests := []struct {
+		path         string
+		cacheControl string
+		etag         string
+	}{
+		{"/assets/app.js", "no-cache", etag},
+		{"/assets/app.3f9a2c1d.js", "public, max-age=31536000, immutable", ""},
+		{"/assets/site-0123abcdef.css", "public, max-age=31536000, immutable", ""},
+		{"/assets/release-notes.txt", "no-cache", etag},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+			if got := w.Header().Get("Cache-Control"); got != tt.cacheControl {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.cacheControl)
+			}
+			if got := w.Header().Get("ETag"); got != tt.etag {
+				t.Errorf("ETag = %q, want %q", got, tt.etag)
+			}
+		})
+	}
+
+	req := httptest.NewRequest("GET", "/assets/app.js", nil)
+	req.Header.Set("If-None-Match", etag)
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("revalidation status = %d, want 304", w.Code)
+	}
+}
+
+func TestIsFingerprinted(t *testing.T) {
+	tests := []struct {
+		file string
+		want bool
+	}{
+		{"app.3f9a2c1d.js", true},
+		{"css/site-0123abcdef.css", true},
+		{"chunk.5e2b9a7c41d0.min.js", true},
+		{"app.js", false},
+		{"report-20241018.pdf", false},
+		{"invoice-12345678.pdf", false},
+		{"deadbeef.js", false},
+		{"app.3f9a2c.js", false},
+		{"LOGO-ABCDEF12.png", false},
+	}
+	for _, tt := range tests {
+		if got := isFingerprinted(tt.file); got != tt.want {
+			t.Errorf("isFingerprinted(%q) = %v, want %v", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestSPA(t *testing.T) {
+	metrics := NewMetrics()
+	var logs bytes.Buffer
+	g := NewRouter()
+	g.Use(metrics.Middleware(), AccessLog(slog.New(slog.NewTextHandler(&logs, nil))))
+	g.SPA("/app", fstest.MapFS{
+		"index.html":      {Data: []byte("shell")},
+		"app.3f9a2c1d.js": {Data: []byte("js")},
+		"docs/index.html": {Data: []byte("docs")},
+	}).Soft404Header()
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+		soft   string
+	}{
+		{"/app/", 200, "shell", ""},
+		{"/app/app.3f9a2c1d.js", 200, "js", ""},
+		{"/app/docs/", 200, "docs", ""},
+		{"/app/settings/profile", 200, "shell", "1"},
+		{"/app/missing.js", 404, "404 page not found\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+			if w.Code != tt.status || w.Body.String() != tt.body {
+				t.Errorf("got %d %q, want %d %q", w.Code, w.Body.String(), tt.status, tt.body)
+			}
+			if got := w.Header().Get("X-Soft-404"); got != tt.soft {
+				t.Errorf("X-Soft-404 = %q, want %q", got, tt.soft)
+			}
+		})
+	}
+
+	if stats := metrics.Snapshot(); len(stats) != 1 || stats[0].Soft404s != 1 {
+		t.Errorf("stats = %+v, want one soft 404", stats)
+	}
+	if n := strings.Count(logs.String(), "soft_404=true"); n != 1 {
+		t.Errorf("%d soft 404 log records, want 1:\n%s", n, logs.String())
+	}
+}
+
+func TestSPAWithoutHeader(t *testing.T) {
+	g := NewRouter()
+	g.SPA("/", fstest.MapFS{"index.html": {Data: []byte("shell")}})
+
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/orders/42", nil))
+	if w.Body.String() != "shell" || w.Header().Get("X-Soft-404") != "" {
+		t.Errorf("got %q, X-Soft-404 %q", w.Body.String(), w.Header().Get("X-Soft-404"))
+	}
+}
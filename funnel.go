@@ -0,0 +1,118 @@
+package groute
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"sync/atomic"
+)
+
+// FunnelStats counts how the router's pre-routing middlewares transformed
+// requests before they reached a handler. Only requests passing through Pre
+// middlewares are counted.
+type FunnelStats struct {
+	// Requests is the number of requests entering the pre-routing pipeline.
+	Requests uint64 `json:"requests"`
+	// Redirects counts requests answered with a redirect before routing,
+	// canonicalizations included.
+	Redirects uint64 `json:"redirects"`
+	// Rewrites counts requests changed by a rewrite rule.
+	Rewrites uint64 `json:"rewrites"`
+	// Canonicalizations counts redirects to the canonical host.
+	Canonicalizations uint64 `json:"canonicalizations"`
+	// MethodOverrides counts requests whose method was overridden.
+	MethodOverrides uint64 `json:"method_overrides"`
+}
+
+// funnelEvent is a kind of transformation counted in FunnelStats.
+type funnelEvent int
+
+const (
+	funnelRequest funnelEvent = iota
+	funnelRedirect
+	funnelRewrite
+	funnelCanonicalization
+	funnelMethodOverride
+	funnelEvents
+)
+
+// funnel holds the router's counters, indexed by funnelEvent.
+type funnel [funnelEvents]atomic.Uint64
+
+// Funnel returns the router's pre-routing counters, for exposing next to
+// other metrics. It reports on the whole router, even when called on a
+// group.
+func (g *Router) Funnel() FunnelStats {
+	f := &g.root().funnel
+	return FunnelStats{
+		Requests:          f[funnelRequest].Load(),
+		Redirects:         f[funnelRedirect].Load(),
+		Rewrites:          f[funnelRewrite].Load(),
+		Canonicalizations: f[funnelCanonicalization].Load(),
+		MethodOverrides:   f[funnelMethodOverride].Load(),
+	}
+}
+
+// funnelKey is the context key for the request's *funnelWriter.
+type funnelKey struct{}
+
+// serveFunnel serves r through the pre-routing pipeline, counting it.
+func (g *Router) serveFunnel(w http.ResponseWriter, r *http.Request) {
+	g.funnel[funnelRequest].Add(1)
+	fw := &funnelWriter{ResponseWriter: w, funnel: &g.funnel}
+	g.preHandler(fw, r.WithContext(context.WithValue(r.Context(), funnelKey{}, fw)))
+}
+
+// countFunnel counts a transformation of r by a pre-routing middleware.
+func countFunnel(r *http.Request, event funnelEvent) {
+	if fw, ok := r.Context().Value(funnelKey{}).(*funnelWriter); ok {
+		fw.funnel[event].Add(1)
+	}
+}
+
+// markRouted records that r made it through the pre-routing pipeline.
+func markRouted(r *http.Request) {
+	if fw, ok := r.Context().Value(funnelKey{}).(*funnelWriter); ok {
+		fw.routed = true
+	}
+}
+
+// funnelWriter counts redirects written before the request was routed.
+type funnelWriter struct {
+	http.ResponseWriter
+	funnel *funnel
+	routed bool
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *funnelWriter) WriteHeader(code int) {
+	if !w.routed && code >= 200 {
+		if code >= 300 && code < 400 {
+			w.funnel[funnelRedirect].Add(1)
+		}
+		w.routed = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write implements http.ResponseWriter.
+func (w *funnelWriter) Write(b []byte) (int, error) {
+	w.routed = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher.
+func (w *funnelWriter) Flush() {
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker for websocket upgrades.
+func (w *funnelWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap returns the underlying http.ResponseWriter.
+func (w *funnelWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
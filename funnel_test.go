@@ -0,0 +1,66 @@
+package groute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFunnel(t *testing.T) {
+	g := NewRouter()
+	g.Pre(CanonicalHost("example.com", http.StatusMovedPermanently))
+	g.Pre(MethodOverride())
+	g.Rewrite(RewritePrefix("/old/", "/new/"))
+	g.Pre(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/moved" {
+				http.Redirect(w, r, "/new/moved", http.StatusFound)
+				return
+			}
+			next(w, r)
+		}
+	})
+	g.Get("/new/{name}", func(w http.ResponseWriter, r *http.Request) {})
+	g.Delete("/new/{name}", func(w http.ResponseWriter, r *http.Request) {})
+	g.Get("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new/x", http.StatusFound)
+	})
+
+	requests := []struct {
+		method string
+		host   string
+		path   string
+		header string
+	}{
+		{"GET", "example.com", "/new/a", ""},
+		{"GET", "www.example.com", "/new/a", ""},
+		{"GET", "example.com", "/old/a", ""},
+		{"GET", "example.com", "/old/b", ""},
+		{"POST", "example.com", "/new/a", "DELETE"},
+		{"GET", "example.com", "/moved", ""},
+		{"GET", "example.com", "/redirect", ""},
+		{"GET", "example.com", "/missing", ""},
+	}
+	for _, req := range requests {
+		r := httptest.NewRequest(req.method, req.path, nil)
+		r.Host = req.host
+		if req.header != "" {
+			r.Header.Set(MethodOverrideHeader, req.header)
+		}
+		g.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	want := FunnelStats{Requests: 8, Redirects: 2, Rewrites: 2, Canonicalizations: 1, MethodOverrides: 1}
+	if got := g.Group("/api").Funnel(); got != want {
+		t.Errorf("Funnel() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFunnelWithoutPre(t *testing.T) {
+	g := NewRouter()
+	g.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if got := g.Funnel(); got != (FunnelStats{}) {
+		t.Errorf("Funnel() = %+v, want zero", got)
+	}
+}
@@ -0,0 +1,117 @@
+package groute
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// GroupOnce returns the group for prefix created earlier on g by GroupOnce
+// or Group, creating it if there is none.
+//
+// Unlike Group, which creates a new group with its own copy of the
+// middleware on every call, calling GroupOnce from several places yields
+// the same group, so middleware added to it with Use is deliberately shared
+// by all routes registered through it afterwards.
+func (g *Router) GroupOnce(prefix string) *Router {
+	subGroup := g.newGroup(prefix)
+	key := groupKey(subGroup.prefix)
+
+	t := g.routes
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, group := range t.groups {
+		if group.parent == g && groupKey(group.prefix) == key {
+			return group
+		}
+	}
+	t.groups = append(t.groups, subGroup)
+	return subGroup
+}
+
+// Lint returns warnings about likely mistakes in the router's setup:
+//
+//   - creating several groups with the same prefix. Each such group
+//     carries its own copy of the middleware, which is rarely intended;
+//     use GroupOnce to share one group instead.
+//   - calling Use on a group after routes or sub-groups were created on it.
+//     The middleware does not apply to them, so the group's routes behave
+//     differently depending on registration order.
+//   - registering a pattern more than once with the same predicates.
+//     Only the first such route ever serves requests; see Router.When.
+//   - declaring route attributes whose keys map to the same metrics label,
+//     such as "api.tier" and "api_tier". Metrics only exports the first.
+//
+// Lint is meant to be called once all routes are registered, e.g. from a test.
+func (g *Router) Lint() []string {
+	t := g.routes
+	t.mu.Lock()
+	counts := make(map[string]int)
+	for _, group := range t.groups {
+		counts[groupKey(group.prefix)]++
+	}
+	lints := t.lints
+	handlers := make([]*routeHandler, 0, len(t.handlers))
+	for _, h := range t.handlers {
+		handlers = append(handlers, h)
+	}
+	routes := slices.Clone(t.routes)
+	t.mu.Unlock()
+
+	warnings := append([]string(nil), lints...)
+	for _, h := range handlers {
+		warnings = append(warnings, h.lintAlternatives()...)
+	}
+	for _, route := range routes {
+		warnings = append(warnings, route.lintAttributes()...)
+	}
+	for prefix, n := range counts {
+		if prefix == "" {
+			prefix = "/"
+		}
+		if n > 1 {
+			warnings = append(warnings, fmt.Sprintf("groute: group prefix %q created %d times; use GroupOnce to share one group", prefix, n))
+		}
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+// checkUse records a lint warning if Use on g comes after routes or
+// sub-groups were created on it.
+func (t *routeTable) checkUse(g *Router) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var routes, groups int
+	for _, route := range t.routes {
+		if route.group == g {
+			routes++
+		}
+	}
+	for _, group := range t.groups {
+		if group.parent == g {
+			groups++
+		}
+	}
+	if routes == 0 && groups == 0 {
+		return
+	}
+	prefix := g.prefix
+	if prefix == "" {
+		prefix = "/"
+	}
+	t.lints = append(t.lints, fmt.Sprintf("groute: Use on group %q after it already had %d routes and %d sub-groups; they do not get the new middleware", prefix, routes, groups))
+}
+
+// addGroup records a group created on the router.
+func (t *routeTable) addGroup(group *Router) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.groups = append(t.groups, group)
+}
+
+// groupKey normalizes a group prefix so "/api" and "/api/" compare equal.
+func groupKey(prefix string) string {
+	return strings.TrimRight(prefix, "/")
+}
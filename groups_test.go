@@ -0,0 +1,87 @@
+package groute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGroupOnce(t *testing.T) {
+	g := NewRouter()
+	var calls int
+	api := g.GroupOnce("/api")
+	api.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			next(w, r)
+		}
+	})
+	if again := g.GroupOnce("/api/"); again != api {
+		t.Fatal("expected GroupOnce to return the existing group")
+	}
+	g.GroupOnce("api").Get("/users", func(w http.ResponseWriter, r *http.Request) {})
+	if nested := api.GroupOnce("/api"); nested == api {
+		t.Error("expected a nested group to be distinct from its parent")
+	}
+
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/api/users", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", w.Code)
+	}
+	if calls != 1 {
+		t.Errorf("expected shared middleware to run once, ran %d times", calls)
+	}
+	if warnings := g.Lint(); len(warnings) != 0 {
+		t.Errorf("expected no lint warnings, got %v", warnings)
+	}
+}
+
+func TestLintDuplicateGroups(t *testing.T) {
+	g := NewRouter()
+	g.Group("/api")
+	g.Group("/api/")
+	g.Group("/api").Group("/v1")
+	g.Group("/api/v1")
+	g.Group("/admin")
+
+	expected := []string{
+		`groute: group prefix "/api" created 3 times; use GroupOnce to share one group`,
+		`groute: group prefix "/api/v1" created 2 times; use GroupOnce to share one group`,
+	}
+	warnings := g.Lint()
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected %d warnings, got %v", len(expected), warnings)
+	}
+	for i := range expected {
+		if warnings[i] != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], warnings[i])
+		}
+	}
+}
+
+func TestLintLateUse(t *testing.T) {
+	noop := func(next http.HandlerFunc) http.HandlerFunc { return next }
+	g := NewRouter()
+	g.Use(noop)
+	api := g.Group("/api")
+	api.Use(noop)
+	api.Get("/users", func(w http.ResponseWriter, r *http.Request) {})
+	api.Get("/orders", func(w http.ResponseWriter, r *http.Request) {})
+	api.Use(noop)
+	g.Use(noop)
+
+	expected := []string{
+		`groute: Use on group "/" after it already had 0 routes and 1 sub-groups; they do not get the new middleware`,
+		`groute: Use on group "/api" after it already had 2 routes and 0 sub-groups; they do not get the new middleware`,
+	}
+	warnings := g.Lint()
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected %d warnings, got %v", len(expected), warnings)
+	}
+	for i := range expected {
+		if warnings[i] != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], warnings[i])
+		}
+	}
+}
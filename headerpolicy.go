@@ -0,0 +1,130 @@
+package groute
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// HeaderPolicy edits the headers a proxy route sends to the upstream
+// (Request) and returns to the client (Response).
+//
+// Hop-by-hop headers such as Connection and Keep-Alive are always dropped
+// by the proxy; a policy is for everything else, e.g. stripping internal
+// headers before they leak to clients:
+//
+//	api.Handle("/billing/", billing).HeaderPolicy(groute.HeaderPolicy{
+//		Request:  groute.HeaderRules{Set: map[string]string{"X-Tenant": "acme"}},
+//		Response: groute.HeaderRules{Remove: []string{"Server", "X-Internal-*"}},
+//	})
+type HeaderPolicy struct {
+	Request  HeaderRules
+	Response HeaderRules
+}
+
+// HeaderRules edits a set of headers. Remove is applied first, then Set,
+// which replaces existing values, then Add, which appends to them. Names in
+// Remove ending in "*" match all headers with that prefix.
+type HeaderRules struct {
+	Remove []string
+	Set    map[string]string
+	Add    map[string]string
+}
+
+// HeaderPolicy sets the route's header policy, replacing any previous one.
+// Like all route options it can be changed while the router is serving, so
+// policies can be reloaded from configuration at runtime. It panics if the
+// policy is invalid; check policies from untrusted sources with Validate.
+func (rt *Route) HeaderPolicy(policy HeaderPolicy) *Route {
+	if err := policy.Validate(); err != nil {
+		panic(err)
+	}
+	policy = policy.clone()
+	rt.update(func(info *routeInfo) {
+		info.headerPolicy = &policy
+	})
+	return rt
+}
+
+// Validate reports whether all header names are valid tokens and all
+// values are safe to send.
+func (p HeaderPolicy) Validate() error {
+	for _, rules := range []HeaderRules{p.Request, p.Response} {
+		for _, name := range rules.Remove {
+			if !validHeaderName(strings.TrimSuffix(name, "*")) {
+				return fmt.Errorf("groute: invalid header name %q in header policy", name)
+			}
+		}
+		for _, m := range []map[string]string{rules.Set, rules.Add} {
+			for name, value := range m {
+				if !validHeaderName(name) {
+					return fmt.Errorf("groute: invalid header name %q in header policy", name)
+				}
+				if !ValidHeaderValue(value) {
+					return fmt.Errorf("%w for %s in header policy", ErrInvalidHeaderValue, name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// clone returns a copy of p sharing no memory with the caller's.
+func (p HeaderPolicy) clone() HeaderPolicy {
+	return HeaderPolicy{Request: p.Request.clone(), Response: p.Response.clone()}
+}
+
+func (rules HeaderRules) clone() HeaderRules {
+	return HeaderRules{
+		Remove: slices.Clone(rules.Remove),
+		Set:    maps.Clone(rules.Set),
+		Add:    maps.Clone(rules.Add),
+	}
+}
+
+// apply edits h according to the rules.
+func (rules HeaderRules) apply(h http.Header) {
+	for _, name := range rules.Remove {
+		prefix, ok := strings.CutSuffix(name, "*")
+		if !ok {
+			h.Del(name)
+			continue
+		}
+		for key := range h {
+			if len(key) >= len(prefix) && strings.EqualFold(key[:len(prefix)], prefix) {
+				delete(h, key)
+			}
+		}
+	}
+	for name, value := range rules.Set {
+		h.Set(name, value)
+	}
+	for name, value := range rules.Add {
+		h.Add(name, value)
+	}
+}
+
+// routeHeaderPolicy returns the header policy of r's route, or nil.
+func routeHeaderPolicy(r *http.Request) *HeaderPolicy {
+	route := CurrentRoute(r)
+	if route == nil {
+		return nil
+	}
+	return route.info.Load().headerPolicy
+}
+
+// validHeaderName reports whether name is a non-empty HTTP token.
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+package groute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHeaderPolicy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Got-Tenant", r.Header.Get("X-Tenant"))
+		w.Header().Set("X-Got-Debug", r.Header.Get("X-Debug-Token"))
+		w.Header()["X-Got-Via"] = r.Header.Values("Via")
+		w.Header().Set("X-Internal-Trace", "abc")
+		w.Header().Set("X-Internal-Host", "db-1")
+		w.Header().Set("X-Version", "7")
+	}))
+	defer upstream.Close()
+
+	p, err := Proxy(upstream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewRouter()
+	route := g.Handle("/", p).HeaderPolicy(HeaderPolicy{
+		Request: HeaderRules{
+			Remove: []string{"x-debug-*"},
+			Set:    map[string]string{"X-Tenant": "acme"},
+			Add:    map[string]string{"Via": "gateway"},
+		},
+		Response: HeaderRules{
+			Remove: []string{"X-Internal-*"},
+			Set:    map[string]string{"X-Version": "public"},
+		},
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Tenant", "spoofed")
+	req.Header.Set("X-Debug-Token", "secret")
+	req.Header.Set("Via", "client-proxy")
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+
+	h := w.Header()
+	if got := h.Get("X-Got-Tenant"); got != "acme" {
+		t.Errorf("upstream X-Tenant = %q, want acme", got)
+	}
+	if got := h.Get("X-Got-Debug"); got != "" {
+		t.Errorf("upstream X-Debug-Token = %q, want removed", got)
+	}
+	if got := h.Values("X-Got-Via"); len(got) != 2 || got[1] != "gateway" {
+		t.Errorf("upstream Via = %q, want client-proxy and gateway", got)
+	}
+	if h.Get("X-Internal-Trace") != "" || h.Get("X-Internal-Host") != "" {
+		t.Errorf("internal headers leaked: %v", h)
+	}
+	if got := h.Get("X-Version"); got != "public" {
+		t.Errorf("X-Version = %q, want public", got)
+	}
+
+	// Policies can be replaced while serving.
+	route.HeaderPolicy(HeaderPolicy{})
+	w = httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if got := w.Header().Get("X-Internal-Trace"); got != "abc" {
+		t.Errorf("after reload X-Internal-Trace = %q, want abc", got)
+	}
+}
+
+func TestHeaderPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy HeaderPolicy
+		valid  bool
+	}{
+		{"empty", HeaderPolicy{}, true},
+		{"prefix", HeaderPolicy{Response: HeaderRules{Remove: []string{"X-Internal-*"}}}, true},
+		{"bad remove", HeaderPolicy{Response: HeaderRules{Remove: []string{"X Bad"}}}, false},
+		{"empty name", HeaderPolicy{Request: HeaderRules{Set: map[string]string{"": "v"}}}, false},
+		{"bad name", HeaderPolicy{Request: HeaderRules{Add: map[string]string{"X:A": "v"}}}, false},
+		{"injection", HeaderPolicy{Request: HeaderRules{Set: map[string]string{"X-A": "v\r\nX-B: w"}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); (err == nil) != tt.valid {
+				t.Errorf("Validate() = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("HeaderPolicy should panic on an invalid policy")
+		}
+	}()
+	NewRouter().Get("/", func(w http.ResponseWriter, r *http.Request) {}).
+		HeaderPolicy(HeaderPolicy{Request: HeaderRules{Set: map[string]string{"X-A": "\n"}}})
+}
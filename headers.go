@@ -0,0 +1,118 @@
+package groute
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidHeaderValue is returned when a value cannot be written into a
+// header safely.
+var ErrInvalidHeaderValue = errors.New("groute: invalid header value")
+
+// ValidHeaderValue reports whether v can be written as a header value
+// without splitting the response or injecting headers: it must not contain
+// CR, LF, NUL or other control characters except horizontal tab.
+func ValidHeaderValue(v string) bool {
+	for i := 0; i < len(v); i++ {
+		if c := v[i]; c < ' ' && c != '\t' || c == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// SetHeader sets a header to a user-influenced value, returning
+// ErrInvalidHeaderValue instead of writing a value that would inject
+// headers.
+func SetHeader(w http.ResponseWriter, key, value string) error {
+	if !ValidHeaderValue(value) {
+		return fmt.Errorf("%w for %s", ErrInvalidHeaderValue, key)
+	}
+	w.Header().Set(key, value)
+	return nil
+}
+
+// SetLocation sets the Location header to a user-influenced URL. Absolute
+// URLs must use http or https, so values such as "javascript:..." are
+// rejected, and characters not allowed in a URL are percent-encoded.
+func SetLocation(w http.ResponseWriter, location string) error {
+	safe, err := safeLocation(location)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Location", safe)
+	return nil
+}
+
+// safeLocation validates and escapes a redirect target.
+func safeLocation(location string) (string, error) {
+	if !ValidHeaderValue(location) {
+		return "", fmt.Errorf("%w for Location", ErrInvalidHeaderValue)
+	}
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("%w for Location: %v", ErrInvalidHeaderValue, err)
+	}
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("%w for Location: scheme %q", ErrInvalidHeaderValue, u.Scheme)
+	}
+	return u.String(), nil
+}
+
+// Attachment sets the Content-Disposition header so browsers download the
+// response as filename. The name is reduced to its last path element and
+// sent both as an ASCII fallback and RFC 5987 encoded, so any user-supplied
+// name is safe to pass.
+func Attachment(w http.ResponseWriter, filename string) {
+	w.Header().Set("Content-Disposition", ContentDisposition("attachment", filename))
+}
+
+// ContentDisposition formats a Content-Disposition header value such as
+// `attachment; filename="report.pdf"` for an arbitrary file name.
+func ContentDisposition(disposition, filename string) string {
+	if i := strings.LastIndexAny(filename, `/\`); i >= 0 {
+		filename = filename[i+1:]
+	}
+	if filename == "" {
+		return disposition
+	}
+
+	var fallback, encoded strings.Builder
+	ascii := true
+	for _, c := range filename {
+		switch {
+		case c < ' ' || c == 0x7f:
+			continue
+		case c > 0x7e:
+			ascii = false
+			fallback.WriteByte('_')
+		case c == '"' || c == '\\':
+			fallback.WriteByte('_')
+		default:
+			fallback.WriteRune(c)
+		}
+	}
+	if ascii && fallback.String() == filename {
+		return disposition + `; filename="` + filename + `"`
+	}
+	for _, b := range []byte(filename) {
+		if isAttrChar(b) {
+			encoded.WriteByte(b)
+		} else if b >= ' ' && b != 0x7f {
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return disposition + `; filename="` + fallback.String() + `"; filename*=UTF-8''` + encoded.String()
+}
+
+// isAttrChar reports whether b may appear unencoded in an RFC 5987 value.
+func isAttrChar(b byte) bool {
+	switch {
+	case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", b) >= 0
+}
@@ -0,0 +1,88 @@
+package groute
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidHeaderValue(t *testing.T) {
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{"text/html; charset=utf-8", true},
+		{"a\tb", true},
+		{"héllo", true},
+		{"a\r\nSet-Cookie: x=1", false},
+		{"a\nb", false},
+		{"a\x00b", false},
+		{"a\x7fb", false},
+	}
+	for _, tt := range tests {
+		if got := ValidHeaderValue(tt.value); got != tt.valid {
+			t.Errorf("ValidHeaderValue(%q) = %v, expected %v", tt.value, got, tt.valid)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	if err := SetHeader(w, "X-Name", "a\r\nb"); !errors.Is(err, ErrInvalidHeaderValue) {
+		t.Errorf("expected ErrInvalidHeaderValue, got %v", err)
+	}
+	if w.Header().Get("X-Name") != "" {
+		t.Error("expected invalid value not to be written")
+	}
+	if err := SetHeader(w, "X-Name", "ok"); err != nil || w.Header().Get("X-Name") != "ok" {
+		t.Errorf("expected header to be set, got %v", err)
+	}
+}
+
+func TestSetLocation(t *testing.T) {
+	tests := []struct {
+		location string
+		expected string
+		valid    bool
+	}{
+		{"/users/1?tab=info", "/users/1?tab=info", true},
+		{"https://example.com/a b", "https://example.com/a%20b", true},
+		{"/next\r\nSet-Cookie: x=1", "", false},
+		{"javascript:alert(1)", "", false},
+		{"data:text/html,hi", "", false},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		err := SetLocation(w, tt.location)
+		if (err == nil) != tt.valid {
+			t.Errorf("SetLocation(%q): unexpected error %v", tt.location, err)
+		}
+		if got := w.Header().Get("Location"); got != tt.expected {
+			t.Errorf("SetLocation(%q): expected %q, got %q", tt.location, tt.expected, got)
+		}
+	}
+}
+
+func TestContentDisposition(t *testing.T) {
+	tests := []struct {
+		filename string
+		expected string
+	}{
+		{"report.pdf", `attachment; filename="report.pdf"`},
+		{"../../etc/passwd", `attachment; filename="passwd"`},
+		{`C:\temp\a.txt`, `attachment; filename="a.txt"`},
+		{`say "hi".txt`, `attachment; filename="say _hi_.txt"; filename*=UTF-8''say%20%22hi%22.txt`},
+		{"résumé.pdf", `attachment; filename="r_sum_.pdf"; filename*=UTF-8''r%C3%A9sum%C3%A9.pdf`},
+		{"a\r\nb.txt", `attachment; filename="ab.txt"; filename*=UTF-8''ab.txt`},
+		{"", "attachment"},
+	}
+	for _, tt := range tests {
+		if got := ContentDisposition("attachment", tt.filename); got != tt.expected {
+			t.Errorf("ContentDisposition(%q) = %q, expected %q", tt.filename, got, tt.expected)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	Attachment(w, "data.csv")
+	if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="data.csv"` {
+		t.Errorf("unexpected Content-Disposition %q", got)
+	}
+}
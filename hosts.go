@@ -0,0 +1,67 @@
+package groute
+
+import (
+	"net/http"
+	"strings"
+)
+
+// AllowedHosts returns a middleware rejecting requests for hosts not in
+// hosts with 400 Bad Request, mitigating host header attacks on password
+// reset links, absolute URLs and cache keys.
+//
+// Entries are host names without a port, matched case-insensitively. An
+// entry of the form "*.example.com" matches any subdomain of example.com,
+// but not example.com itself. The X-Forwarded-Host header, if present, must
+// be allowed as well, since URL generation may use it.
+//
+// Install it with Pre so that requests no route matches are checked too:
+//
+//	r.Pre(groute.AllowedHosts("example.com", "*.example.com"))
+func AllowedHosts(hosts ...string) Middleware {
+	allowed := make([]string, len(hosts))
+	for i, host := range hosts {
+		allowed[i] = normalizeHost(host)
+	}
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ok := hostAllowed(allowed, r.Host)
+			if forwarded := r.Header.Get("X-Forwarded-Host"); ok && forwarded != "" {
+				ok = hostAllowed(allowed, firstHeaderValue(forwarded))
+			}
+			if !ok {
+				Error(w, r, NewStatusError(http.StatusBadRequest, "invalid host"))
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+// hostAllowed reports whether host matches one of the allowed entries.
+// Hosts with characters that cannot appear in a host[:port] value, such as
+// "evil.com/x.example.com", are never allowed.
+func hostAllowed(allowed []string, host string) bool {
+	if !validHost(host) {
+		return false
+	}
+	host = normalizeHost(hostWithoutPort(host))
+	if host == "" {
+		return false
+	}
+	for _, pattern := range allowed {
+		if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+			if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeHost lowercases host and strips IPv6 brackets and a trailing dot.
+func normalizeHost(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	return strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+}
@@ -0,0 +1,50 @@
+package groute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAllowedHosts(t *testing.T) {
+	g := NewRouter()
+	g.Pre(AllowedHosts("example.com", "*.Example.org", "[::1]"))
+	g.Get("/reset", func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		host      string
+		forwarded string
+		path      string
+		status    int
+	}{
+		{"example.com", "", "/reset", http.StatusOK},
+		{"EXAMPLE.com:8443", "", "/reset", http.StatusOK},
+		{"example.com.", "", "/reset", http.StatusOK},
+		{"api.example.org", "", "/reset", http.StatusOK},
+		{"a.b.example.org", "", "/reset", http.StatusOK},
+		{"[::1]:8080", "", "/reset", http.StatusOK},
+		{"example.org", "", "/reset", http.StatusBadRequest},
+		{"evil-example.org", "", "/reset", http.StatusBadRequest},
+		{"sub.example.com", "", "/reset", http.StatusBadRequest},
+		{"attacker.test", "", "/missing", http.StatusBadRequest},
+		{"example.com", "attacker.test", "/reset", http.StatusBadRequest},
+		{"example.com", "api.example.org:443, proxy", "/reset", http.StatusOK},
+		{"example.com", "evil.com/x.example.org", "/reset", http.StatusBadRequest},
+		{"example.com", "evil.com@a.example.org", "/reset", http.StatusBadRequest},
+		{"evil.com/x.example.org", "", "/reset", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host+" "+tt.forwarded, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Host = tt.host
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-Host", tt.forwarded)
+			}
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, w.Code)
+			}
+		})
+	}
+}
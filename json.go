@@ -0,0 +1,51 @@
+package groute
+
+import (
+	"errors"
+	"net/http"
+)
+
+// JSON answers r with v as a JSON response with the given status code,
+// encoded with the codec of the router that dispatched r. v is encoded
+// before anything is written, so encoding errors can still be answered with
+// an error response.
+func JSON(w http.ResponseWriter, r *http.Request, status int, v any) error {
+	data, err := codecFor(r).Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// Created answers r with 201 Created, a Location header pointing at the
+// named route and body encoded as JSON:
+//
+//	r.Get("/users/{id}", showUser).Name("user")
+//	r.Post("/users", func(w http.ResponseWriter, r *http.Request) {
+//		u := createUser(r)
+//		_ = groute.Created(w, r, u, "user", "id", u.ID)
+//	})
+//
+// The location is built with URLFor on the router that dispatched r. A nil
+// body writes no content.
+func Created(w http.ResponseWriter, r *http.Request, body any, routeName string, params ...string) error {
+	route := CurrentRoute(r)
+	if route == nil {
+		return errors.New("groute: Created needs a request dispatched by a Router")
+	}
+	location, err := route.group.URLFor(r, routeName, params...)
+	if err != nil {
+		return err
+	}
+	if err := SetLocation(w, location); err != nil {
+		return err
+	}
+	if body == nil {
+		w.WriteHeader(http.StatusCreated)
+		return nil
+	}
+	return JSON(w, r, http.StatusCreated, body)
+}
@@ -0,0 +1,76 @@
+package groute
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	if err := JSON(w, r, http.StatusAccepted, map[string]int{"n": 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w.Code != http.StatusAccepted {
+		t.Errorf("expected status 202, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	if w.Body.String() != "{\"n\":1}\n" {
+		t.Errorf("unexpected body %q", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	if err := JSON(w, r, http.StatusOK, make(chan int)); err == nil {
+		t.Error("expected encoding error")
+	}
+	if w.Code != http.StatusOK || w.Body.Len() != 0 || w.Header().Get("Content-Type") != "" {
+		t.Error("expected nothing to be written on encoding error")
+	}
+}
+
+func TestCreated(t *testing.T) {
+	g := NewRouter()
+	api := g.Group("/api")
+	api.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {}).Name("user")
+	api.Post("/users", func(w http.ResponseWriter, r *http.Request) {
+		if err := Created(w, r, map[string]string{"id": "42"}, "user", "id", "42"); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+	api.Post("/empty", func(w http.ResponseWriter, r *http.Request) {
+		_ = Created(w, r, nil, "user", "id", "7")
+	})
+	api.Post("/unknown", func(w http.ResponseWriter, r *http.Request) {
+		if err := Created(w, r, nil, "missing"); !errors.Is(err, ErrRouteNotFound) {
+			t.Errorf("expected ErrRouteNotFound, got %v", err)
+		}
+	})
+
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("POST", "/api/users", nil))
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected status 201, got %d", w.Code)
+	}
+	if location := w.Header().Get("Location"); location != "/api/users/42" {
+		t.Errorf("expected Location '/api/users/42', got %q", location)
+	}
+	if w.Body.String() != "{\"id\":\"42\"}\n" {
+		t.Errorf("unexpected body %q", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("POST", "/api/empty", nil))
+	if w.Code != http.StatusCreated || w.Body.Len() != 0 {
+		t.Errorf("expected empty 201, got %d %q", w.Code, w.Body.String())
+	}
+
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/unknown", nil))
+
+	if err := Created(httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil), nil, "user"); err == nil {
+		t.Error("expected error outside of a router")
+	}
+}
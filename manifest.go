@@ -0,0 +1,105 @@
+package groute
+
+import (
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ManifestVersion is the version of the Manifest schema. It is bumped
+// whenever a field changes meaning or is removed; new fields may be added
+// without a bump.
+const ManifestVersion = 1
+
+// Manifest is a machine-readable description of a router's routes for
+// external tooling such as API catalogs and security scanners. It has a
+// stable JSON encoding: routes are sorted and empty fields are omitted.
+type Manifest struct {
+	Version int             `json:"version"`
+	Routes  []ManifestRoute `json:"routes"`
+}
+
+// ManifestRoute describes one route in a Manifest.
+type ManifestRoute struct {
+	// Method is the route's HTTP method, empty if it matches any method.
+	Method string `json:"method,omitempty"`
+	// Path is the path pattern including the group prefix, e.g. "/users/{id}".
+	Path    string          `json:"path"`
+	Name    string          `json:"name,omitempty"`
+	Params  []ManifestParam `json:"params,omitempty"`
+	Accepts []string        `json:"accepts,omitempty"`
+	// Conditions describes the route's conditions; see Route.Where.
+	Conditions []string `json:"conditions,omitempty"`
+	// Disabled reports a route switched off with SetEnabled(false).
+	Disabled bool           `json:"disabled,omitempty"`
+	SLO      *SLO           `json:"slo,omitempty"`
+	Meta     map[string]any `json:"meta,omitempty"`
+}
+
+// ManifestParam describes a path parameter of a route.
+type ManifestParam struct {
+	Name string `json:"name"`
+	// Remainder reports a {name...} wildcard matching the rest of the path.
+	Remainder bool `json:"remainder,omitempty"`
+}
+
+// Manifest returns a description of all routes registered on the router
+// and its groups, sorted by path and method. Encode it as JSON for tooling:
+//
+//	data, _ := json.MarshalIndent(r.Manifest(), "", "  ")
+//
+// Metadata values are included as is and must be JSON-encodable.
+func (g *Router) Manifest() Manifest {
+	m := Manifest{Version: ManifestVersion, Routes: []ManifestRoute{}}
+	for _, route := range g.Routes() {
+		info := route.info.Load()
+		// The snapshot is read by running requests; hand out copies.
+		var slo *SLO
+		if info.slo != nil {
+			s := *info.slo
+			slo = &s
+		}
+		mr := ManifestRoute{
+			Method:     route.Method(),
+			Path:       route.Path(),
+			Name:       route.RouteName(),
+			Params:     manifestParams(route.Path()),
+			Accepts:    slices.Clone(info.accepts),
+			Conditions: route.Conditions(),
+			Disabled:   info.disabled,
+			SLO:        slo,
+			Meta:       manifestMeta(info.meta),
+		}
+		m.Routes = append(m.Routes, mr)
+	}
+	sort.SliceStable(m.Routes, func(i, j int) bool {
+		if m.Routes[i].Path != m.Routes[j].Path {
+			return m.Routes[i].Path < m.Routes[j].Path
+		}
+		return m.Routes[i].Method < m.Routes[j].Method
+	})
+	return m
+}
+
+// manifestMeta copies meta, including the attribute map set by
+// Route.Attribute.
+func manifestMeta(meta map[string]any) map[string]any {
+	meta = maps.Clone(meta)
+	if attrs, ok := meta[MetaAttributes].(map[string]string); ok {
+		meta[MetaAttributes] = maps.Clone(attrs)
+	}
+	return meta
+}
+
+// manifestParams describes the wildcards of a path pattern.
+func manifestParams(path string) []ManifestParam {
+	var params []ManifestParam
+	for _, name := range paramNames(path) {
+		params = append(params, ManifestParam{
+			Name:      name,
+			Remainder: strings.Contains(path, "{"+name+"...}"),
+		})
+	}
+	return params
+}
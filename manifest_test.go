@@ -0,0 +1,56 @@
+package groute
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestManifest(t *testing.T) {
+	h := func(w http.ResponseWriter, r *http.Request) {}
+	g := NewRouter()
+	api := g.Group("/api")
+	api.Post("/users", h).Accepts("application/json").Meta("owner", "accounts")
+	api.Get("/users/{id}", h).Name("user").SLO(SLO{Latency: 100 * time.Millisecond, Objective: 0.99})
+	g.Get("/files/{path...}", h).SetEnabled(false)
+
+	data, err := json.Marshal(g.Manifest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"version":1,"routes":[` +
+		`{"method":"POST","path":"/api/users","accepts":["application/json"],"meta":{"owner":"accounts"}},` +
+		`{"method":"GET","path":"/api/users/{id}","name":"user","params":[{"name":"id"}],"slo":{"latency":100000000,"objective":0.99}},` +
+		`{"method":"GET","path":"/files/{path...}","params":[{"name":"path","remainder":true}],"disabled":true}]}`
+	if string(data) != expected {
+		t.Errorf("unexpected manifest:\n%s", data)
+	}
+}
+
+func TestManifestEmpty(t *testing.T) {
+	data, err := json.Marshal(NewRouter().Manifest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"version":1,"routes":[]}` {
+		t.Errorf("unexpected manifest %s", data)
+	}
+}
+
+func TestManifestCopies(t *testing.T) {
+	h := func(w http.ResponseWriter, r *http.Request) {}
+	g := NewRouter()
+	route := g.Post("/users", h).Accepts("application/json").Meta("owner", "accounts").
+		SLO(SLO{Objective: 0.99})
+
+	m := g.Manifest()
+	m.Routes[0].Accepts[0] = "text/plain"
+	m.Routes[0].SLO.Objective = 0.5
+	m.Routes[0].Meta["owner"] = "nobody"
+
+	info := route.info.Load()
+	if info.accepts[0] != "application/json" || info.slo.Objective != 0.99 || info.meta["owner"] != "accounts" {
+		t.Errorf("editing the manifest changed the route: %v %+v %v", info.accepts, info.slo, info.meta)
+	}
+}
@@ -0,0 +1,105 @@
+package groute
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Matrix is a path segment split into its value and matrix parameters.
+//
+// For the segment "items;sort=price;dir=asc", Value is "items" and Params
+// holds sort=price and dir=asc.
+type Matrix struct {
+	Value  string
+	Params url.Values
+}
+
+// Get returns the first value of the matrix parameter key.
+func (m Matrix) Get(key string) string {
+	return m.Params.Get(key)
+}
+
+// Has reports whether the matrix parameter key is present.
+func (m Matrix) Has(key string) bool {
+	return m.Params.Has(key)
+}
+
+// ParseMatrix parses a single escaped path segment with matrix parameters,
+// unescaping the value and parameters after splitting, so "%3B" and "%3D"
+// can appear inside them. Parameters without "=" are recorded with an
+// empty value.
+func ParseMatrix(segment string) Matrix {
+	return parseMatrix(segment, unescapeMatrix)
+}
+
+// parseMatrix parses segment, applying unescape to each part.
+func parseMatrix(segment string, unescape func(string) string) Matrix {
+	parts := strings.Split(segment, ";")
+	m := Matrix{
+		Value:  unescape(parts[0]),
+		Params: make(url.Values),
+	}
+	for _, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(part, "=")
+		m.Params.Add(unescape(key), unescape(value))
+	}
+	return m
+}
+
+// MatrixPath parses every segment of path, e.g.
+// "/items;sort=price/123" yields two segments.
+func MatrixPath(path string) []Matrix {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	segments := strings.Split(path, "/")
+	result := make([]Matrix, len(segments))
+	for i, segment := range segments {
+		result[i] = ParseMatrix(segment)
+	}
+	return result
+}
+
+// MatrixValue parses the named path parameter of r as a matrix segment.
+//
+// Register the route with a parameter covering the whole segment, e.g.
+// "/{items}/{id}", and call MatrixValue(r, "items"). The segment is taken
+// from the escaped request path, since PathValue is already unescaped.
+func MatrixValue(r *http.Request, name string) Matrix {
+	if segment, ok := escapedPathValue(r, name); ok {
+		return ParseMatrix(segment)
+	}
+	return parseMatrix(r.PathValue(name), func(s string) string { return s })
+}
+
+// escapedPathValue returns the escaped path segment matched by the
+// single-segment wildcard name of the pattern r was routed with.
+func escapedPathValue(r *http.Request, name string) (string, bool) {
+	pattern := r.Pattern
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		pattern = path
+	}
+	if i := strings.IndexByte(pattern, '/'); i > 0 {
+		pattern = pattern[i:] // host-qualified
+	}
+	i := slices.Index(strings.Split(pattern, "/"), "{"+name+"}")
+	segments := strings.Split(r.URL.EscapedPath(), "/")
+	if i < 0 || i >= len(segments) || unescapeMatrix(segments[i]) != r.PathValue(name) {
+		return "", false
+	}
+	return segments[i], true
+}
+
+// unescapeMatrix unescapes s, returning it unchanged when it is not valid.
+func unescapeMatrix(s string) string {
+	if u, err := url.PathUnescape(s); err == nil {
+		return u
+	}
+	return s
+}
@@ -0,0 +1,136 @@
+package groute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseMatrix(t *testing.T) {
+	tests := []struct {
+		name          string
+		segment       string
+		expectedValue string
+		expected      map[string]string
+	}{
+		{
+			name:          "no parameters",
+			segment:       "items",
+			expectedValue: "items",
+			expected:      map[string]string{},
+		},
+		{
+			name:          "multiple parameters",
+			segment:       "items;sort=price;dir=asc",
+			expectedValue: "items",
+			expected:      map[string]string{"sort": "price", "dir": "asc"},
+		},
+		{
+			name:          "flag parameter",
+			segment:       "items;draft",
+			expectedValue: "items",
+			expected:      map[string]string{"draft": ""},
+		},
+		{
+			name:          "escaped values",
+			segment:       "items;q=a%20b",
+			expectedValue: "items",
+			expected:      map[string]string{"q": "a b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := ParseMatrix(tt.segment)
+			if m.Value != tt.expectedValue {
+				t.Errorf("expected value %q, got %q", tt.expectedValue, m.Value)
+			}
+			if len(m.Params) != len(tt.expected) {
+				t.Errorf("expected %d params, got %d: %v", len(tt.expected), len(m.Params), m.Params)
+			}
+			for key, expected := range tt.expected {
+				if !m.Has(key) {
+					t.Errorf("expected param %q to be present", key)
+				}
+				if got := m.Get(key); got != expected {
+					t.Errorf("expected param %q = %q, got %q", key, expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestMatrixPath(t *testing.T) {
+	segments := MatrixPath("/items;sort=price;dir=asc/123")
+	if len(segments) != 2 {
+		t.Fatalf("expected 2 segments, got %d", len(segments))
+	}
+	if segments[0].Value != "items" || segments[0].Get("sort") != "price" {
+		t.Errorf("unexpected first segment: %+v", segments[0])
+	}
+	if segments[1].Value != "123" {
+		t.Errorf("expected second segment value '123', got %q", segments[1].Value)
+	}
+	if MatrixPath("/") != nil {
+		t.Error("expected nil segments for root path")
+	}
+}
+
+func TestMatrixValue(t *testing.T) {
+	g := NewRouter()
+	var captured Matrix
+	var capturedID string
+
+	g.Get("/{items}/{id}", func(w http.ResponseWriter, r *http.Request) {
+		captured = MatrixValue(r, "items")
+		capturedID = r.PathValue("id")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/items;sort=price;dir=asc/123", nil)
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+
+	if captured.Value != "items" {
+		t.Errorf("expected value 'items', got %q", captured.Value)
+	}
+	if captured.Get("sort") != "price" || captured.Get("dir") != "asc" {
+		t.Errorf("unexpected matrix params: %v", captured.Params)
+	}
+	if capturedID != "123" {
+		t.Errorf("expected id '123', got %q", capturedID)
+	}
+}
+
+func TestMatrixValueEscaped(t *testing.T) {
+	g := NewRouter()
+	var captured Matrix
+	g.Get("/m/{items}/{id}", func(w http.ResponseWriter, r *http.Request) {
+		captured = MatrixValue(r, "items")
+	})
+	api := NewRouter()
+	api.Get("/{items}", func(w http.ResponseWriter, r *http.Request) {
+		captured = MatrixValue(r, "items")
+	})
+	g.Mount("/api", api)
+
+	tests := []struct {
+		path   string
+		value  string
+		params url.Values
+	}{
+		{"/m/a%3Bb;q=c%3Bd/1", "a;b", url.Values{"q": {"c;d"}}},
+		{"/m/items;q=a%3Db;k%3Dx=y/1", "items", url.Values{"q": {"a=b"}, "k=x": {"y"}}},
+		{"/m/items;q=%2541/1", "items", url.Values{"q": {"%41"}}},
+		{"/api/items;q=%2541%3B", "items", url.Values{"q": {"%41;"}}},
+	}
+	for _, tt := range tests {
+		captured = Matrix{}
+		g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", tt.path, nil))
+		if captured.Value != tt.value || !reflect.DeepEqual(captured.Params, tt.params) {
+			t.Errorf("%s: got %q %v, want %q %v", tt.path, captured.Value, captured.Params, tt.value, tt.params)
+		}
+	}
+}
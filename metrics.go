@@ -0,0 +1,321 @@
+package groute
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SLO declares a service level objective for a route.
+type SLO struct {
+	// Latency is the threshold a successful request must meet to count as
+	// a good event. Zero means only errors make an event bad.
+	Latency time.Duration `json:"latency"`
+	// Objective is the target fraction of good events, e.g. 0.999.
+	Objective float64 `json:"objective"`
+}
+
+// SLO attaches a service level objective to the route. Metrics then counts
+// good and bad events for it: a request is bad if it fails with a 5xx status
+// or exceeds the latency threshold. Client aborts (499) are not counted.
+func (rt *Route) SLO(slo SLO) *Route {
+	rt.update(func(info *routeInfo) {
+		info.slo = &slo
+	})
+	return rt
+}
+
+// Metrics collects per-route request metrics.
+//
+// Counters are updated with atomic operations on per-route records looked
+// up in a sync.Map, so recording never takes a lock on the hot path.
+type Metrics struct {
+	routes sync.Map // pattern -> *routeMetrics
+}
+
+// RouteStats is a snapshot of a route's metrics.
+type RouteStats struct {
+	Pattern string `json:"pattern"`
+	Owner   string `json:"owner,omitempty"`
+	// Attributes are the route's attributes, set with Route.Attribute.
+	Attributes   map[string]string `json:"attributes,omitempty"`
+	Requests     uint64            `json:"requests"`
+	Errors       uint64            `json:"errors"`
+	ClientClosed uint64            `json:"client_closed"`
+	// Soft404s counts successful responses that were soft 404s; see SPA.
+	Soft404s uint64        `json:"soft_404s,omitempty"`
+	Duration time.Duration `json:"duration"`
+	// SLO is the route's objective, if declared; Good and Bad count its events.
+	SLO  *SLO   `json:"slo,omitempty"`
+	Good uint64 `json:"good"`
+	Bad  uint64 `json:"bad"`
+}
+
+// BurnRate returns how fast the route consumes its error budget since the
+// metrics were created: the observed bad-event ratio divided by the allowed
+// ratio (1 - Objective). A burn rate of 1 spends the budget exactly over the
+// SLO window. It returns 0 for routes without an SLO or events.
+func (s RouteStats) BurnRate() float64 {
+	total := s.Good + s.Bad
+	if s.SLO == nil || total == 0 || s.SLO.Objective >= 1 {
+		return 0
+	}
+	return (float64(s.Bad) / float64(total)) / (1 - s.SLO.Objective)
+}
+
+type routeMetrics struct {
+	requests     atomic.Uint64
+	errors       atomic.Uint64
+	clientClosed atomic.Uint64
+	soft404s     atomic.Uint64
+	duration     atomic.Int64
+	good         atomic.Uint64
+	bad          atomic.Uint64
+	slo          atomic.Pointer[SLO]
+	owner        atomic.Pointer[string]
+	attributes   atomic.Pointer[map[string]string]
+}
+
+// NewMetrics creates an empty Metrics collector.
+func NewMetrics() *Metrics {
+	return &Metrics{}
+}
+
+// Middleware returns a middleware recording metrics for matched routes.
+func (m *Metrics) Middleware() Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			start := Now(r)
+			rw := WrapResponseWriter(w)
+			next(rw, r)
+			m.record(r, ResponseStatus(rw, r), Now(r).Sub(start))
+		}
+	}
+}
+
+// record updates the metrics of the route matched for r.
+func (m *Metrics) record(r *http.Request, status int, elapsed time.Duration) {
+	route, pattern := matchedRoute(r)
+	rm := m.lookup(pattern)
+	rm.requests.Add(1)
+	rm.duration.Add(int64(elapsed))
+	if Soft404(r) {
+		rm.soft404s.Add(1)
+	}
+	if owner := route.RouteOwner(); owner != "" {
+		if p := rm.owner.Load(); p == nil || *p != owner {
+			rm.owner.Store(&owner)
+		}
+	}
+	if attrs := route.attributes(); attrs != nil {
+		if p := rm.attributes.Load(); p == nil || !maps.Equal(*p, attrs) {
+			rm.attributes.Store(&attrs)
+		}
+	}
+	switch {
+	case status == StatusClientClosedRequest:
+		rm.clientClosed.Add(1)
+		return
+	case status >= 500:
+		rm.errors.Add(1)
+	}
+
+	var slo *SLO
+	if route != nil {
+		slo = route.info.Load().slo
+	}
+	if slo == nil {
+		return
+	}
+	rm.slo.Store(slo)
+	if status >= 500 || (slo.Latency > 0 && elapsed > slo.Latency) {
+		rm.bad.Add(1)
+	} else {
+		rm.good.Add(1)
+	}
+}
+
+// lookup returns the record for pattern, creating it on first use.
+func (m *Metrics) lookup(pattern string) *routeMetrics {
+	if rm, ok := m.routes.Load(pattern); ok {
+		return rm.(*routeMetrics)
+	}
+	rm, _ := m.routes.LoadOrStore(pattern, &routeMetrics{})
+	return rm.(*routeMetrics)
+}
+
+// Snapshot returns the current metrics of every route seen, sorted by pattern.
+func (m *Metrics) Snapshot() []RouteStats {
+	var stats []RouteStats
+	m.routes.Range(func(key, value any) bool {
+		rm := value.(*routeMetrics)
+		var owner string
+		if p := rm.owner.Load(); p != nil {
+			owner = *p
+		}
+		var attrs map[string]string
+		if p := rm.attributes.Load(); p != nil {
+			attrs = *p
+		}
+		stats = append(stats, RouteStats{
+			Pattern:      key.(string),
+			Owner:        owner,
+			Attributes:   maps.Clone(attrs),
+			Requests:     rm.requests.Load(),
+			Errors:       rm.errors.Load(),
+			ClientClosed: rm.clientClosed.Load(),
+			Soft404s:     rm.soft404s.Load(),
+			Duration:     time.Duration(rm.duration.Load()),
+			SLO:          rm.slo.Load(),
+			Good:         rm.good.Load(),
+			Bad:          rm.bad.Load(),
+		})
+		return true
+	})
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Pattern < stats[j].Pattern })
+	return stats
+}
+
+// OwnerStats aggregates the metrics of the routes owned by a team.
+type OwnerStats struct {
+	Owner        string `json:"owner"`
+	Requests     uint64 `json:"requests"`
+	Errors       uint64 `json:"errors"`
+	ClientClosed uint64 `json:"client_closed"`
+}
+
+// Owners returns the metrics of the routes tagged with Route.Owner, summed
+// per owner and sorted by owner.
+func (m *Metrics) Owners() []OwnerStats {
+	var owners []OwnerStats
+	index := make(map[string]int)
+	for _, s := range m.Snapshot() {
+		if s.Owner == "" {
+			continue
+		}
+		i, ok := index[s.Owner]
+		if !ok {
+			i = len(owners)
+			index[s.Owner] = i
+			owners = append(owners, OwnerStats{Owner: s.Owner})
+		}
+		owners[i].Requests += s.Requests
+		owners[i].Errors += s.Errors
+		owners[i].ClientClosed += s.ClientClosed
+	}
+	sort.Slice(owners, func(i, j int) bool { return owners[i].Owner < owners[j].Owner })
+	return owners
+}
+
+// Handler returns a handler exposing the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = m.WritePrometheus(w)
+	})
+}
+
+// WritePrometheus writes the metrics in the Prometheus text format.
+//
+// SLI events are exposed as groute_sli_events_total{result="good|bad"} next
+// to groute_slo_objective, so multi-window burn-rate alerts can be written
+// directly against them. Requests and errors are also summed per route
+// owner. Route attributes are exposed as the labels of groute_route_info,
+// to be joined on pattern. The binary's BuildInfo is exposed as the labels of
+// groute_build_info.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	stats := m.Snapshot()
+	var b strings.Builder
+
+	writeFamily := func(name, typ, help string, value func(s RouteStats) (string, bool)) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+		for _, s := range stats {
+			if v, ok := value(s); ok {
+				fmt.Fprintf(&b, "%s{pattern=\"%s\"} %s\n", name, escapeLabel(s.Pattern), v)
+			}
+		}
+	}
+	counter := func(get func(s RouteStats) uint64) func(s RouteStats) (string, bool) {
+		return func(s RouteStats) (string, bool) { return fmt.Sprint(get(s)), true }
+	}
+
+	writeFamily("groute_requests_total", "counter", "Requests handled per route.",
+		counter(func(s RouteStats) uint64 { return s.Requests }))
+	writeFamily("groute_request_errors_total", "counter", "Requests answered with a 5xx status per route.",
+		counter(func(s RouteStats) uint64 { return s.Errors }))
+	writeFamily("groute_requests_client_closed_total", "counter", "Requests abandoned by the client per route.",
+		counter(func(s RouteStats) uint64 { return s.ClientClosed }))
+	writeFamily("groute_soft_404_total", "counter", "Soft 404 responses per route.",
+		counter(func(s RouteStats) uint64 { return s.Soft404s }))
+	writeFamily("groute_slo_objective", "gauge", "Declared SLO objective per route.",
+		func(s RouteStats) (string, bool) {
+			if s.SLO == nil {
+				return "", false
+			}
+			return fmt.Sprint(s.SLO.Objective), true
+		})
+
+	// A summary without quantiles: _sum and _count give the mean latency.
+	b.WriteString("# HELP groute_request_duration_seconds Time spent handling requests per route.\n# TYPE groute_request_duration_seconds summary\n")
+	for _, s := range stats {
+		fmt.Fprintf(&b, "groute_request_duration_seconds_sum{pattern=\"%s\"} %v\n", escapeLabel(s.Pattern), s.Duration.Seconds())
+		fmt.Fprintf(&b, "groute_request_duration_seconds_count{pattern=\"%s\"} %d\n", escapeLabel(s.Pattern), s.Requests)
+	}
+
+	b.WriteString("# HELP groute_sli_events_total SLI events per route and result.\n# TYPE groute_sli_events_total counter\n")
+	for _, s := range stats {
+		if s.SLO == nil {
+			continue
+		}
+		fmt.Fprintf(&b, "groute_sli_events_total{pattern=\"%s\",result=\"good\"} %d\n", escapeLabel(s.Pattern), s.Good)
+		fmt.Fprintf(&b, "groute_sli_events_total{pattern=\"%s\",result=\"bad\"} %d\n", escapeLabel(s.Pattern), s.Bad)
+	}
+
+	b.WriteString("# HELP groute_route_info Attributes declared per route.\n# TYPE groute_route_info gauge\n")
+	for _, s := range stats {
+		if len(s.Attributes) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "groute_route_info{pattern=\"%s\"", escapeLabel(s.Pattern))
+		seen := map[string]bool{"pattern": true}
+		for _, key := range slices.Sorted(maps.Keys(s.Attributes)) {
+			// Keys mapping to a label already written are dropped; Lint
+			// reports them.
+			if name := promLabelName(key); !seen[name] {
+				seen[name] = true
+				fmt.Fprintf(&b, ",%s=\"%s\"", name, escapeLabel(s.Attributes[key]))
+			}
+		}
+		b.WriteString("} 1\n")
+	}
+
+	owners := m.Owners()
+	b.WriteString("# HELP groute_owner_requests_total Requests handled per route owner.\n# TYPE groute_owner_requests_total counter\n")
+	for _, o := range owners {
+		fmt.Fprintf(&b, "groute_owner_requests_total{owner=\"%s\"} %d\n", escapeLabel(o.Owner), o.Requests)
+	}
+	b.WriteString("# HELP groute_owner_errors_total Requests answered with a 5xx status per route owner.\n# TYPE groute_owner_errors_total counter\n")
+	for _, o := range owners {
+		fmt.Fprintf(&b, "groute_owner_errors_total{owner=\"%s\"} %d\n", escapeLabel(o.Owner), o.Errors)
+	}
+
+	writeBuildInfo(&b, ReadBuildInfo())
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// labelEscaper escapes a Prometheus label value.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes a Prometheus label value.
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
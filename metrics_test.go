@@ -0,0 +1,120 @@
+package groute
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics()
+	g := NewRouter()
+	g.Use(m.Middleware())
+	g.Get("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	g.Get("/fail", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	for i := 0; i < 3; i++ {
+		g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok", nil))
+	}
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/fail", nil))
+
+	stats := m.Snapshot()
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 routes, got %d", len(stats))
+	}
+	if stats[0].Pattern != "GET /fail" || stats[0].Requests != 1 || stats[0].Errors != 1 {
+		t.Errorf("unexpected stats for /fail: %+v", stats[0])
+	}
+	if stats[1].Pattern != "GET /ok" || stats[1].Requests != 3 || stats[1].Errors != 0 {
+		t.Errorf("unexpected stats for /ok: %+v", stats[1])
+	}
+	if stats[1].SLO != nil || stats[1].Good != 0 {
+		t.Errorf("expected no SLI events without SLO: %+v", stats[1])
+	}
+}
+
+func TestMetricsSLO(t *testing.T) {
+	m := NewMetrics()
+	g := NewRouter()
+	g.Use(m.Middleware())
+
+	var delay time.Duration
+	var status int
+	g.Get("/pay", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		w.WriteHeader(status)
+	}).SLO(SLO{Latency: 50 * time.Millisecond, Objective: 0.9})
+
+	requests := []struct {
+		delay  time.Duration
+		status int
+	}{
+		{0, http.StatusOK},
+		{0, http.StatusNotFound},
+		{0, http.StatusBadGateway},
+		{100 * time.Millisecond, http.StatusOK},
+	}
+	for _, req := range requests {
+		delay, status = req.delay, req.status
+		g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/pay", nil))
+	}
+
+	// Client aborts are excluded from SLI events.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	delay, status = 0, http.StatusOK
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/pay", nil).WithContext(ctx))
+
+	stats := m.Snapshot()[0]
+	if stats.Good != 2 || stats.Bad != 2 {
+		t.Errorf("expected 2 good and 2 bad events, got good=%d bad=%d", stats.Good, stats.Bad)
+	}
+	if stats.ClientClosed != 1 {
+		t.Errorf("expected 1 client closed request, got %d", stats.ClientClosed)
+	}
+	if rate := stats.BurnRate(); rate < 4.99 || rate > 5.01 {
+		t.Errorf("expected burn rate 5, got %f", rate)
+	}
+
+	var b strings.Builder
+	if err := m.WritePrometheus(&b); err != nil {
+		t.Fatalf("WritePrometheus failed: %v", err)
+	}
+	out := b.String()
+	for _, line := range []string{
+		`groute_requests_total{pattern="GET /pay"} 5`,
+		"# TYPE groute_request_duration_seconds summary\n",
+		`groute_request_duration_seconds_count{pattern="GET /pay"} 5`,
+		`groute_sli_events_total{pattern="GET /pay",result="good"} 2`,
+		`groute_sli_events_total{pattern="GET /pay",result="bad"} 2`,
+		`groute_slo_objective{pattern="GET /pay"} 0.9`,
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("expected output to contain %q, got:\n%s", line, out)
+		}
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	m := NewMetrics()
+	g := NewRouter()
+	g.Use(m.Middleware())
+	g.Get(`/q/"x"`, func(w http.ResponseWriter, r *http.Request) {})
+	g.Handle("GET /metrics", m.Handler())
+
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", `/q/"x"`, nil))
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("expected text/plain content type, got %q", w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(w.Body.String(), `pattern="GET /q/\"x\""`) {
+		t.Errorf("expected escaped label, got:\n%s", w.Body.String())
+	}
+}
@@ -0,0 +1,93 @@
+package groute
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Mount serves the separately built router sub below prefix, relative to
+// the group, and makes it part of g's operational surface:
+//
+//	billing := billingapp.NewRouter() // routes such as GET /invoices
+//	r.Mount("/billing", billing)      // GET /billing/invoices
+//
+// The prefix is stripped before requests reach sub, so its handlers keep
+// working as if it ran alone, with BasePath reporting the prefix; the URLs
+// sub generates include the prefix. The sub-app's routes are listed, with the prefix, by g's Routes,
+// Manifest, edge config exports and probes; Metrics and AccessLog
+// middlewares of g record mounted requests under the sub-app's route; Drain
+// and ActiveStreams cover the sub-app's streams. A router can be mounted
+// only once, and not below itself.
+func (g *Router) Mount(prefix string, sub *Router) *Route {
+	prefix = "/" + strings.Trim(prefix, "/")
+	subRoot := sub.root()
+	if subRoot.mountedOn != nil {
+		panic("groute: router already mounted")
+	}
+	for root := g.root(); root != nil; root = root.mountedOn {
+		if root = root.root(); root == subRoot {
+			panic("groute: cannot mount a router below itself")
+		}
+	}
+	fullPrefix := strings.TrimRight(joinPath(g.prefix, prefix), "/")
+	subRoot.mountedOn, subRoot.mountPrefix = g, fullPrefix
+	route := g.Handle(strings.TrimRight(prefix, "/")+"/", StripPrefix(fullPrefix)(subRoot.ServeHTTP))
+	route.mounted = subRoot
+	return route
+}
+
+// mountPath returns the client-visible path g's root router is mounted at,
+// or "" if it is not mounted.
+func (g *Router) mountPath() string {
+	root := g.root()
+	if root.mountedOn == nil {
+		return ""
+	}
+	return root.mountedOn.mountPath() + root.mountPrefix
+}
+
+// mountedRouters returns the routers mounted on g and its groups.
+func (g *Router) mountedRouters() []*Router {
+	var routers []*Router
+	for _, route := range g.routes.list() {
+		if route.mounted != nil {
+			routers = append(routers, route.mounted)
+		}
+	}
+	return routers
+}
+
+// mountMatchKey is the context key for the *mountMatch of a request
+// dispatched to a mounted router.
+type mountMatchKey struct{}
+
+// mountMatch records the route serving a request inside mounted routers, so
+// middlewares of the outer router can report it instead of the mount.
+type mountMatch struct {
+	route *Route
+}
+
+// trackMount prepares r for recording the route matched by a mounted router,
+// or records h's route if r comes from an outer router.
+func (h *routeHandler) trackMount(r *http.Request) *http.Request {
+	m, ok := r.Context().Value(mountMatchKey{}).(*mountMatch)
+	switch {
+	case h.route.mounted == nil:
+		if ok {
+			m.route = h.route
+		}
+	case !ok:
+		r = r.WithContext(context.WithValue(r.Context(), mountMatchKey{}, &mountMatch{}))
+	}
+	return r
+}
+
+// matchedRoute returns the route that served r and its pattern, looking
+// into mounted routers.
+func matchedRoute(r *http.Request) (*Route, string) {
+	if m, ok := r.Context().Value(mountMatchKey{}).(*mountMatch); ok && m.route != nil {
+		return m.route, m.route.Pattern()
+	}
+	return CurrentRoute(r), r.Pattern
+}
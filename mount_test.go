@@ -0,0 +1,148 @@
+package groute
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newBillingApp() *Router {
+	billing := NewRouter()
+	billing.Get("/invoices/{id}", func(w http.ResponseWriter, r *http.Request) {
+		u, _ := billing.URLFor(r, "invoice", "id", r.PathValue("id"))
+		w.Write([]byte(BasePath(r) + " " + u))
+	}).Name("invoice")
+	reports := NewRouter()
+	reports.Get("/daily", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("daily"))
+	})
+	billing.Mount("/reports", reports)
+	return billing
+}
+
+func TestMount(t *testing.T) {
+	metrics := NewMetrics()
+	var logs bytes.Buffer
+	g := NewRouter()
+	g.Use(metrics.Middleware(), AccessLog(slog.New(slog.NewTextHandler(&logs, nil))))
+	g.Get("/health", func(w http.ResponseWriter, r *http.Request) {})
+	g.Group("/apps").Mount("/billing", newBillingApp())
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/apps/billing/invoices/7", 200, "/apps/billing /apps/billing/invoices/7"},
+		{"/apps/billing/reports/daily", 200, "daily"},
+		{"/apps/billing/missing", 404, "404 page not found\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+			if w.Code != tt.code || w.Body.String() != tt.body {
+				t.Errorf("got %d %q, want %d %q", w.Code, w.Body.String(), tt.code, tt.body)
+			}
+		})
+	}
+
+	var patterns []string
+	for _, route := range g.Routes() {
+		patterns = append(patterns, route.Pattern())
+	}
+	want := "GET /health,GET /apps/billing/invoices/{id},GET /apps/billing/reports/daily"
+	if got := strings.Join(patterns, ","); got != want {
+		t.Errorf("Routes = %s, want %s", got, want)
+	}
+
+	var stats []string
+	for _, s := range metrics.Snapshot() {
+		stats = append(stats, s.Pattern)
+	}
+	want = "/apps/billing/,GET /apps/billing/invoices/{id},GET /apps/billing/reports/daily"
+	if got := strings.Join(stats, ","); got != want {
+		t.Errorf("metrics patterns = %s, want %s", got, want)
+	}
+	if !strings.Contains(logs.String(), `pattern="GET /apps/billing/reports/daily"`) {
+		t.Errorf("access log missing the mounted pattern:\n%s", logs.String())
+	}
+}
+
+func TestMountTwice(t *testing.T) {
+	sub := NewRouter()
+	NewRouter().Mount("/a", sub)
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	NewRouter().Mount("/b", sub)
+}
+
+func TestMountCycle(t *testing.T) {
+	tests := []struct {
+		name  string
+		mount func()
+	}{
+		{"self", func() {
+			g := NewRouter()
+			g.Mount("/x", g)
+		}},
+		{"own group", func() {
+			g := NewRouter()
+			g.Group("/api").Mount("/x", g)
+		}},
+		{"cycle", func() {
+			a, b := NewRouter(), NewRouter()
+			a.Mount("/b", b)
+			b.Mount("/a", a)
+		}},
+		{"deep cycle", func() {
+			a, b, c := NewRouter(), NewRouter(), NewRouter()
+			a.Mount("/b", b)
+			b.Group("/v1").Mount("/c", c)
+			c.Mount("/a", a)
+		}},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if v := recover(); v != "groute: cannot mount a router below itself" {
+					t.Errorf("%s: recovered %v, want a cycle panic", tt.name, v)
+				}
+			}()
+			tt.mount()
+		}()
+	}
+}
+
+func TestMountDrain(t *testing.T) {
+	started := make(chan struct{})
+	sub := NewRouter()
+	sub.Get("/events", func(w http.ResponseWriter, r *http.Request) {
+		defer TrackStream(r)()
+		close(started)
+		<-Draining(r)
+	})
+	g := NewRouter()
+	g.Mount("/app", sub)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/app/events", nil))
+	}()
+	<-started
+	if n := g.ActiveStreams(); n != 1 {
+		t.Errorf("ActiveStreams = %d, want 1", n)
+	}
+	g.Drain()
+	<-done
+	if n := g.ActiveStreams(); n != 0 {
+		t.Errorf("ActiveStreams after drain = %d, want 0", n)
+	}
+}
@@ -0,0 +1,79 @@
+package groute
+
+import "io"
+
+// Option configures a router created with NewRouter. Each option has the
+// same effect as the corresponding setter, which remains available for
+// changes after creation:
+//
+//	r := groute.NewRouter(
+//		groute.WithErrorHandler(pages.HandleError),
+//		groute.WithTrustForwardedHeaders(true),
+//		groute.WithoutTrace(),
+//	)
+type Option func(g *Router)
+
+// WithMiddleware adds middlewares as with Use.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(g *Router) { g.Use(middlewares...) }
+}
+
+// WithPre adds pre-routing middlewares as with Pre.
+func WithPre(middlewares ...Middleware) Option {
+	return func(g *Router) {
+		for _, mw := range middlewares {
+			g.Pre(mw)
+		}
+	}
+}
+
+// WithErrorHandler sets the error handler as with SetErrorHandler.
+func WithErrorHandler(handler ErrorHandler) Option {
+	return func(g *Router) { g.SetErrorHandler(handler) }
+}
+
+// WithErrorReporter sets the error reporter as with SetErrorReporter.
+func WithErrorReporter(reporter ErrorReporter) Option {
+	return func(g *Router) { g.SetErrorReporter(reporter) }
+}
+
+// WithClock sets the clock as with SetClock.
+func WithClock(clock Clock) Option {
+	return func(g *Router) { g.SetClock(clock) }
+}
+
+// WithCodec sets the JSON codec as with SetCodec.
+func WithCodec(codec Codec) Option {
+	return func(g *Router) { g.SetCodec(codec) }
+}
+
+// WithRandom sets the source of random bytes as with SetRandom.
+func WithRandom(src io.Reader) Option {
+	return func(g *Router) { g.SetRandom(src) }
+}
+
+// WithServerTiming enables the Server-Timing header as with SetServerTiming.
+func WithServerTiming(enabled bool) Option {
+	return func(g *Router) { g.SetServerTiming(enabled) }
+}
+
+// WithTrustForwardedHeaders trusts forwarded headers as with
+// SetTrustForwardedHeaders.
+func WithTrustForwardedHeaders(trust bool) Option {
+	return func(g *Router) { g.SetTrustForwardedHeaders(trust) }
+}
+
+// WithBaseURL sets the base URL as with SetBaseURL. NewRouter panics if
+// rawURL is invalid.
+func WithBaseURL(rawURL string) Option {
+	return func(g *Router) {
+		if err := g.SetBaseURL(rawURL); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// WithoutTrace rejects TRACE requests as with DisableTrace.
+func WithoutTrace() Option {
+	return func(g *Router) { g.DisableTrace() }
+}
@@ -0,0 +1,73 @@
+package groute
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewRouterOptions(t *testing.T) {
+	clock := newFakeClock()
+	var reported int
+	var order []string
+	g := NewRouter(
+		WithClock(clock),
+		WithRandom(bytes.NewReader(make([]byte, 64))),
+		WithServerTiming(true),
+		WithTrustForwardedHeaders(true),
+		WithBaseURL("https://example.com"),
+		WithoutTrace(),
+		WithErrorReporter(ErrorReporterFunc(func(r *http.Request, event ErrorEvent) { reported++ })),
+		WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+			w.WriteHeader(ErrorStatus(err))
+			w.Write([]byte("custom"))
+		}),
+		WithPre(func(next http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, "pre")
+				next(w, r)
+			}
+		}),
+		WithMiddleware(func(next http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, "use")
+				next(w, r)
+			}
+		}),
+	)
+	g.Get("/fail", func(w http.ResponseWriter, r *http.Request) {
+		Error(w, r, NewStatusError(http.StatusInternalServerError, "boom"))
+	}).Name("fail")
+
+	if g.Clock() != clock || !g.serverTiming || !g.trustForwarded || !g.disableTrace || g.random == nil {
+		t.Error("options not applied")
+	}
+	if u, err := g.AbsoluteURL(nil, "fail"); err != nil || u != "https://example.com/fail" {
+		t.Errorf("AbsoluteURL = %q, %v", u, err)
+	}
+
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/fail", nil))
+	if w.Code != http.StatusInternalServerError || w.Body.String() != "custom" || reported != 1 {
+		t.Errorf("got %d %q, %d reports", w.Code, w.Body.String(), reported)
+	}
+	if len(order) != 2 || order[0] != "pre" || order[1] != "use" {
+		t.Errorf("order = %v, want [pre use]", order)
+	}
+
+	w = httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("TRACE", "/fail", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("TRACE status = %d, want 405", w.Code)
+	}
+}
+
+func TestWithBaseURLInvalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	NewRouter(WithBaseURL("/relative"))
+}
@@ -0,0 +1,19 @@
+package groute
+
+// MetaOwner is the metadata key of the team owning a route, as a string.
+const MetaOwner = "owner"
+
+// Owner tags the route with the team owning it. The owner is added to
+// access log records and ErrorEvents, and Metrics breaks requests and
+// errors down per owner, so incidents on shared services reach the right
+// team.
+func (rt *Route) Owner(team string) *Route {
+	return rt.Meta(MetaOwner, team)
+}
+
+// RouteOwner returns the team set with Owner, or "".
+func (rt *Route) RouteOwner() string {
+	owner, _ := rt.Value(MetaOwner)
+	team, _ := owner.(string)
+	return team
+}
@@ -0,0 +1,64 @@
+package groute
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRouteOwner(t *testing.T) {
+	metrics := NewMetrics()
+	var logs bytes.Buffer
+	var events []ErrorEvent
+	g := NewRouter()
+	g.SetErrorReporter(ErrorReporterFunc(func(r *http.Request, event ErrorEvent) {
+		events = append(events, event)
+	}))
+	g.Use(metrics.Middleware(), AccessLog(slog.New(slog.NewTextHandler(&logs, nil))))
+	g.Get("/pay", func(w http.ResponseWriter, r *http.Request) {
+		Error(w, r, errors.New("ledger down"))
+	}).Owner("payments")
+	g.Get("/refund", func(w http.ResponseWriter, r *http.Request) {}).Owner("payments")
+	g.Get("/search", func(w http.ResponseWriter, r *http.Request) {}).Owner("discovery")
+	g.Get("/health", func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, path := range []string{"/pay", "/refund", "/refund", "/search", "/health"} {
+		g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	if len(events) != 1 || events[0].Owner != "payments" {
+		t.Errorf("events = %+v, want one owned by payments", events)
+	}
+	if !strings.Contains(logs.String(), `path=/pay pattern="GET /pay" status=500`) || !strings.Contains(logs.String(), "owner=payments") {
+		t.Errorf("access log missing the owner:\n%s", logs.String())
+	}
+	for _, line := range strings.Split(logs.String(), "\n") {
+		if strings.Contains(line, "path=/health") && strings.Contains(line, "owner=") {
+			t.Errorf("unowned route logged with an owner: %s", line)
+		}
+	}
+
+	want := []OwnerStats{
+		{Owner: "discovery", Requests: 1},
+		{Owner: "payments", Requests: 3, Errors: 1},
+	}
+	got := metrics.Owners()
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Owners = %+v, want %+v", got, want)
+	}
+
+	var b strings.Builder
+	metrics.WritePrometheus(&b)
+	for _, line := range []string{
+		`groute_owner_requests_total{owner="payments"} 3`,
+		`groute_owner_errors_total{owner="payments"} 1`,
+	} {
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("expected output to contain %q, got:\n%s", line, b.String())
+		}
+	}
+}
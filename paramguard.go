@@ -0,0 +1,108 @@
+package groute
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// ParamGuardMode selects how ParamGuard treats unsafe parameter values.
+type ParamGuardMode int
+
+const (
+	// RejectUnsafeParams answers requests with unsafe parameter values
+	// with 400 Bad Request.
+	RejectUnsafeParams ParamGuardMode = iota
+	// SanitizeParams cleans unsafe values where that is unambiguous: null
+	// bytes are dropped, backslashes become slashes and {name...}
+	// wildcards have their dot segments resolved so they cannot climb
+	// above the route. Values that cannot be cleaned, such as a single
+	// segment parameter of "..", are still rejected.
+	SanitizeParams
+)
+
+// AllowUnsafeParams exempts the route from ParamGuard, e.g. for a search
+// endpoint whose parameter legitimately contains slashes.
+func (rt *Route) AllowUnsafeParams() *Route {
+	rt.update(func(info *routeInfo) {
+		info.allowUnsafeParams = true
+	})
+	return rt
+}
+
+// ParamGuard returns a middleware checking path parameter values before
+// handlers see them, hardening routes that hand parameters to file systems
+// or upstream URLs. A value is unsafe if it contains a null byte or a
+// backslash, is a ".." or "." segment (or, for {name...} wildcards,
+// contains one), or came from an encoded slash (%2F) or backslash (%5C).
+//
+// Once installed with Use, the guard covers every route of the group;
+// routes opt out with AllowUnsafeParams. Rejections go through the route's
+// error handler.
+func ParamGuard(mode ParamGuardMode) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if route := CurrentRoute(r); route != nil && route.info.Load().allowUnsafeParams {
+				next(w, r)
+				return
+			}
+			rawPath := strings.ToLower(r.URL.EscapedPath())
+			encodedSlash := strings.Contains(rawPath, "%2f") || strings.Contains(rawPath, "%5c")
+			cloned := false
+			for _, name := range paramNames(r.Pattern) {
+				value := r.PathValue(name)
+				remainder := strings.Contains(r.Pattern, "{"+name+"...}")
+				if safeParam(value, remainder) && !(remainder && encodedSlash) {
+					continue
+				}
+				if mode == SanitizeParams {
+					if clean, ok := sanitizeParam(value, remainder); ok {
+						// Clean values go to a copy, leaving the caller's
+						// request as it was; Clone copies the path values a
+						// shallow copy would share.
+						if !cloned {
+							r, cloned = r.Clone(r.Context()), true
+						}
+						r.SetPathValue(name, clean)
+						continue
+					}
+				}
+				Error(w, r, NewStatusError(http.StatusBadRequest, fmt.Sprintf("unsafe path parameter %q", name)))
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+// safeParam reports whether a decoded parameter value is safe to use.
+// Single segment values never contain a slash unless it was encoded.
+func safeParam(value string, remainder bool) bool {
+	if strings.ContainsAny(value, "\x00\\") {
+		return false
+	}
+	if !remainder {
+		return !strings.Contains(value, "/") && value != ".." && value != "."
+	}
+	for segment := range strings.SplitSeq(value, "/") {
+		if segment == ".." || segment == "." {
+			return false
+		}
+	}
+	return true
+}
+
+// sanitizeParam cleans value, reporting false if it cannot be made safe.
+func sanitizeParam(value string, remainder bool) (string, bool) {
+	value = strings.ReplaceAll(value, "\x00", "")
+	value = strings.ReplaceAll(value, `\`, "/")
+	if !remainder {
+		return value, safeParam(value, false)
+	}
+	clean := strings.TrimPrefix(path.Clean("/"+value), "/")
+	if strings.HasSuffix(value, "/") && clean != "" {
+		clean += "/"
+	}
+	return clean, true
+}
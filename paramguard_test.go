@@ -0,0 +1,98 @@
+package groute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newParamGuardRouter(mode ParamGuardMode) *Router {
+	echo := func(w http.ResponseWriter, r *http.Request) {
+		for _, name := range []string{"id", "path", "q"} {
+			_, _ = w.Write([]byte(r.PathValue(name)))
+		}
+	}
+	g := NewRouter()
+	g.Use(ParamGuard(mode))
+	g.Get("/users/{id}", echo)
+	g.Get("/files/{path...}", echo)
+	g.Get("/search/{q}", echo).AllowUnsafeParams()
+	return g
+}
+
+func TestParamGuardReject(t *testing.T) {
+	g := newParamGuardRouter(RejectUnsafeParams)
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/users/42", http.StatusOK, "42"},
+		{"/files/css/site.css", http.StatusOK, "css/site.css"},
+		{"/users/a%2Fb", http.StatusBadRequest, "unsafe path parameter \"id\"\n"},
+		{"/users/%2e%2e", http.StatusBadRequest, "unsafe path parameter \"id\"\n"},
+		{"/users/a%00", http.StatusBadRequest, "unsafe path parameter \"id\"\n"},
+		{"/files/..%5C..%5Cwin.ini", http.StatusBadRequest, "unsafe path parameter \"path\"\n"},
+		{"/files/a%2F..%2F..%2Fsecret", http.StatusBadRequest, "unsafe path parameter \"path\"\n"},
+		{"/search/a%2Fb", http.StatusOK, "a/b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+			if w.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, w.Code)
+			}
+			if w.Body.String() != tt.body {
+				t.Errorf("expected body %q, got %q", tt.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestParamGuardSanitize(t *testing.T) {
+	g := newParamGuardRouter(SanitizeParams)
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/users/a%00b", http.StatusOK, "ab"},
+		{"/users/a%5Cb", http.StatusBadRequest, "unsafe path parameter \"id\"\n"},
+		{"/files/..%5C..%5Cwin.ini", http.StatusOK, "win.ini"},
+		{"/files/docs%2F..%2F..%2Fsecret", http.StatusOK, "secret"},
+		{"/files/docs%2F", http.StatusOK, "docs/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+			if w.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, w.Code)
+			}
+			if w.Body.String() != tt.body {
+				t.Errorf("expected body %q, got %q", tt.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestParamGuardSanitizeCopiesRequest(t *testing.T) {
+	var got string
+	guard := ParamGuard(SanitizeParams)(func(w http.ResponseWriter, r *http.Request) {
+		got = r.PathValue("path")
+	})
+	req := httptest.NewRequest("GET", "/files/..%5Cwin.ini", nil)
+	req.Pattern = "/files/{path...}"
+	req.SetPathValue("path", `..\win.ini`)
+
+	guard(httptest.NewRecorder(), req)
+	if got != "win.ini" {
+		t.Errorf("handler saw %q, want win.ini", got)
+	}
+	if v := req.PathValue("path"); v != `..\win.ini` {
+		t.Errorf("caller's request changed to %q", v)
+	}
+}
@@ -0,0 +1,42 @@
+package groute
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Params returns the path parameters of the route matched for r, keyed by
+// name. Wildcards such as {path...} are included under their name.
+// It returns nil if r has no matched pattern or the pattern has no parameters.
+func Params(r *http.Request) map[string]string {
+	names := paramNames(r.Pattern)
+	if len(names) == 0 {
+		return nil
+	}
+	params := make(map[string]string, len(names))
+	for _, name := range names {
+		params[name] = r.PathValue(name)
+	}
+	return params
+}
+
+// paramNames returns the wildcard names in a ServeMux pattern, in order.
+// The anonymous {$} end-of-path marker is skipped.
+func paramNames(pattern string) []string {
+	var names []string
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			return names
+		}
+		end := strings.IndexByte(pattern[start:], '}')
+		if end < 0 {
+			return names
+		}
+		name := strings.TrimSuffix(pattern[start+1:start+end], "...")
+		if name != "$" && name != "" {
+			names = append(names, name)
+		}
+		pattern = pattern[start+end+1:]
+	}
+}
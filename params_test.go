@@ -0,0 +1,43 @@
+package groute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParams(t *testing.T) {
+	tests := []struct {
+		name        string
+		pattern     string
+		requestPath string
+		expected    map[string]string
+	}{
+		{"no params", "/users", "/users", nil},
+		{"single param", "/user/{id}", "/user/1", map[string]string{"id": "1"}},
+		{"multiple params", "/user/{userId}/post/{postId}", "/user/1/post/2", map[string]string{"userId": "1", "postId": "2"}},
+		{"wildcard", "/files/{path...}", "/files/a/b.txt", map[string]string{"path": "a/b.txt"}},
+		{"end marker", "/items/{$}", "/items/", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewRouter()
+			var captured map[string]string
+			g.Get(tt.pattern, func(w http.ResponseWriter, r *http.Request) {
+				captured = Params(r)
+			})
+
+			g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", tt.requestPath, nil))
+
+			if len(captured) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, captured)
+			}
+			for k, v := range tt.expected {
+				if captured[k] != v {
+					t.Errorf("expected %s=%q, got %q", k, v, captured[k])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package groute
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Pre adds middlewares that run before the request is matched against
+// routes, in the order they are added.
+//
+// Unlike middlewares added with Use, which run after a route has been
+// chosen, pre-routing middlewares may change the method, host or path of the
+// request and thereby which route handles it. They also run for requests no
+// route matches. Pre always applies to the whole router, even when called on
+// a group.
+func (g *Router) Pre(middlewares ...Middleware) {
+	root := g.root()
+	root.pre = append(root.pre, middlewares...)
+
+	h := http.HandlerFunc(root.serveMux)
+	for i := len(root.pre) - 1; i >= 0; i-- {
+		h = root.pre[i](h)
+	}
+	root.preHandler = h
+}
+
+// MethodOverrideHeader is the header read by MethodOverride.
+const MethodOverrideHeader = "X-HTTP-Method-Override"
+
+// MethodOverride returns a pre-routing middleware letting clients that can
+// only send GET and POST tunnel other methods: a POST request with an
+// X-HTTP-Method-Override header of PUT, PATCH or DELETE is routed as that
+// method.
+func MethodOverride() Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				switch method := strings.ToUpper(r.Header.Get(MethodOverrideHeader)); method {
+				case http.MethodPut, http.MethodPatch, http.MethodDelete:
+					countFunnel(r, funnelMethodOverride)
+					r = shallowCopyRequest(r)
+					r.Method = method
+				}
+			}
+			next(w, r)
+		}
+	}
+}
+
+// CanonicalHost returns a pre-routing middleware redirecting requests for
+// any other host to host with the given status code (typically 301 or 308),
+// keeping the path and query.
+func CanonicalHost(host string, code int) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if strings.EqualFold(r.Host, host) {
+				next(w, r)
+				return
+			}
+			scheme := "http"
+			if r.TLS != nil {
+				scheme = "https"
+			}
+			countFunnel(r, funnelCanonicalization)
+			http.Redirect(w, r, scheme+"://"+host+r.URL.RequestURI(), code)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package groute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPre(t *testing.T) {
+	g := NewRouter()
+	order := []string{}
+
+	g.Pre(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			order = append(order, "pre1")
+			if CurrentRoute(r) != nil {
+				t.Error("pre-routing middleware should run before a route is matched")
+			}
+			next(w, r)
+		}
+	})
+	g.Group("/api").Pre(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			order = append(order, "pre2")
+			next(w, r)
+		}
+	})
+	g.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			order = append(order, "use")
+			next(w, r)
+		}
+	})
+	g.Get("/x", func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+	})
+
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/x", nil))
+
+	expectedOrder := []string{"pre1", "pre2", "use", "handler"}
+	if len(order) != len(expectedOrder) {
+		t.Fatalf("expected %v, got %v", expectedOrder, order)
+	}
+	for i, expected := range expectedOrder {
+		if order[i] != expected {
+			t.Errorf("expected order[%d] = %q, got %q", i, expected, order[i])
+		}
+	}
+
+	// Pre-routing middlewares also see unmatched requests.
+	order = nil
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/missing", nil))
+	if len(order) != 2 || w.Code != http.StatusNotFound {
+		t.Errorf("expected pre middlewares to run for 404, got %v (status %d)", order, w.Code)
+	}
+}
+
+func TestMethodOverride(t *testing.T) {
+	g := NewRouter()
+	g.Pre(MethodOverride())
+	var method string
+	g.Delete("/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+	})
+
+	tests := []struct {
+		name           string
+		method         string
+		override       string
+		expectedStatus int
+	}{
+		{"override POST", "POST", "delete", http.StatusOK},
+		{"ignore GET", "GET", "DELETE", http.StatusMethodNotAllowed},
+		{"ignore unsupported", "POST", "CONNECT", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/items/1", nil)
+			req.Header.Set(MethodOverrideHeader, tt.override)
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, req)
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+	if method != "DELETE" {
+		t.Errorf("expected DELETE handler to see method DELETE, got %q", method)
+	}
+}
+
+func TestCanonicalHost(t *testing.T) {
+	g := NewRouter()
+	g.Pre(CanonicalHost("example.com", http.StatusMovedPermanently))
+	g.Get("/x", func(w http.ResponseWriter, r *http.Request) {})
+
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "http://www.example.com/x?a=1", nil))
+	if w.Code != http.StatusMovedPermanently {
+		t.Errorf("expected status 301, got %d", w.Code)
+	}
+	if location := w.Header().Get("Location"); location != "http://example.com/x?a=1" {
+		t.Errorf("unexpected Location %q", location)
+	}
+
+	w = httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/x", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200 for canonical host, got %d", w.Code)
+	}
+}
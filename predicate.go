@@ -0,0 +1,275 @@
+package groute
+
+import (
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Predicate is a route condition that describes itself, so Manifest and
+// Lint can show it. Predicates are combined with All, Any and Not and
+// attached to routes with Route.Where; they run after the path matched.
+type Predicate interface {
+	Match(r *http.Request) bool
+	String() string
+}
+
+// Match implements Predicate.
+func (c Condition) Match(r *http.Request) bool {
+	return c(r)
+}
+
+// String implements Predicate. Plain conditions cannot describe themselves;
+// name them with Func.
+func (c Condition) String() string {
+	return "condition"
+}
+
+// Where returns a view of g whose routes are restricted to requests
+// matching all predicates, in addition to those of g. It behaves like When,
+// which it complements with introspection:
+//
+//	r.Where(groute.Any(
+//		groute.Header("X-Beta", "1"),
+//		groute.Query("v", "2"),
+//	)).Get("/search", searchV2)
+//	r.Get("/search", search)
+func (g *Router) Where(predicates ...Predicate) *Router {
+	view := g.newGroup("")
+	view.prefix = g.prefix
+	view.conditions = append(g.conditions[:len(g.conditions):len(g.conditions)], predicates...)
+	return view
+}
+
+// Where restricts the route to requests matching all predicates, like
+// Route.When.
+func (rt *Route) Where(predicates ...Predicate) *Route {
+	rt.update(func(info *routeInfo) {
+		info.conditions = append(info.conditions[:len(info.conditions):len(info.conditions)], predicates...)
+	})
+	return rt
+}
+
+// Conditions returns the descriptions of the route's conditions.
+func (rt *Route) Conditions() []string {
+	var descriptions []string
+	for _, p := range rt.info.Load().conditions {
+		descriptions = append(descriptions, p.String())
+	}
+	return descriptions
+}
+
+// predicate is a Predicate built by this package. It is matched against
+// the route being selected, whose router's clock time predicates use.
+type predicate struct {
+	desc  string
+	match func(r *http.Request, rt *Route) bool
+	// opaque is set if the description does not tell the predicate apart
+	// from others, as for plain Conditions.
+	opaque bool
+}
+
+// newPredicate returns a predicate that does not depend on the route.
+func newPredicate(desc string, match func(r *http.Request) bool) predicate {
+	return predicate{desc: desc, match: func(r *http.Request, _ *Route) bool { return match(r) }}
+}
+
+// Match implements Predicate, for the route matched for r, if any.
+func (p predicate) Match(r *http.Request) bool { return p.match(r, CurrentRoute(r)) }
+
+func (p predicate) String() string { return p.desc }
+
+// matchRoute reports whether r, being routed to rt, matches p.
+func matchRoute(p Predicate, r *http.Request, rt *Route) bool {
+	if p, ok := p.(predicate); ok {
+		return p.match(r, rt)
+	}
+	return p.Match(r)
+}
+
+// isOpaque reports whether the description of p may be shared by
+// predicates matching different requests.
+func isOpaque(p Predicate) bool {
+	switch p := p.(type) {
+	case predicate:
+		return p.opaque
+	case Condition:
+		return true
+	}
+	return false
+}
+
+// Func returns a predicate described by name, for custom conditions.
+func Func(name string, match Condition) Predicate {
+	return newPredicate(name, match)
+}
+
+// All returns a predicate matching requests that match every predicate.
+func All(predicates ...Predicate) Predicate {
+	return predicate{
+		desc: "all(" + joinPredicates(predicates) + ")",
+		match: func(r *http.Request, rt *Route) bool {
+			for _, p := range predicates {
+				if !matchRoute(p, r, rt) {
+					return false
+				}
+			}
+			return true
+		},
+		opaque: slices.ContainsFunc(predicates, isOpaque),
+	}
+}
+
+// Any returns a predicate matching requests that match at least one
+// predicate.
+func Any(predicates ...Predicate) Predicate {
+	return predicate{
+		desc: "any(" + joinPredicates(predicates) + ")",
+		match: func(r *http.Request, rt *Route) bool {
+			for _, p := range predicates {
+				if matchRoute(p, r, rt) {
+					return true
+				}
+			}
+			return false
+		},
+		opaque: slices.ContainsFunc(predicates, isOpaque),
+	}
+}
+
+// Not returns a predicate matching requests p does not match.
+func Not(p Predicate) Predicate {
+	return predicate{
+		desc: "not(" + p.String() + ")",
+		match: func(r *http.Request, rt *Route) bool {
+			return !matchRoute(p, r, rt)
+		},
+		opaque: isOpaque(p),
+	}
+}
+
+// Header returns a predicate matching requests with the header set to
+// value, or with the header present if value is "".
+func Header(name, value string) Predicate {
+	desc := "header(" + name + ")"
+	if value != "" {
+		desc = "header(" + name + "=" + value + ")"
+	}
+	return newPredicate(desc, func(r *http.Request) bool {
+		values := r.Header.Values(name)
+		if value == "" {
+			return len(values) > 0
+		}
+		for _, v := range values {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Query returns a predicate matching requests with the query parameter set
+// to value, or with the parameter present if value is "".
+func Query(name, value string) Predicate {
+	desc := "query(" + name + ")"
+	if value != "" {
+		desc = "query(" + name + "=" + value + ")"
+	}
+	return newPredicate(desc, func(r *http.Request) bool {
+		values, ok := r.URL.Query()[name]
+		if value == "" {
+			return ok
+		}
+		for _, v := range values {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Host returns a predicate matching requests for one of hosts, compared
+// case-insensitively and without the port.
+func Host(hosts ...string) Predicate {
+	return newPredicate("host("+strings.Join(hosts, ", ")+")", func(r *http.Request) bool {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		for _, h := range hosts {
+			if strings.EqualFold(h, host) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// ContentType returns a predicate matching requests whose Content-Type is
+// one of types, which may end in "/*" as with Route.Accepts.
+func ContentType(types ...string) Predicate {
+	return newPredicate("content-type("+strings.Join(types, ", ")+")", func(r *http.Request) bool {
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil {
+			return false
+		}
+		for _, t := range types {
+			t = strings.ToLower(t)
+			if t == mediaType {
+				return true
+			}
+			if prefix, ok := strings.CutSuffix(t, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// During returns a predicate matching requests made during the minutes
+// matching the cron expression spec; see Route.ActiveDuring for the syntax.
+// Like the route schedules, it reads the time from the router's Clock.
+// During panics if spec is invalid.
+func During(spec string) Predicate {
+	schedule := mustParseCron(spec)
+	return predicate{
+		desc: "during(" + spec + ")",
+		match: func(r *http.Request, rt *Route) bool {
+			return schedule.matches(rt.now())
+		},
+	}
+}
+
+// Between returns a predicate matching requests made from start until end,
+// read from the router's Clock as for Route.ActiveBetween. A zero start or
+// end leaves that side open.
+func Between(start, end time.Time) Predicate {
+	window := &routeSchedule{start: start, end: end}
+	format := func(t time.Time) string {
+		if t.IsZero() {
+			return "*"
+		}
+		return t.Format(time.RFC3339)
+	}
+	return predicate{
+		desc: "between(" + format(start) + ", " + format(end) + ")",
+		match: func(r *http.Request, rt *Route) bool {
+			return window.activeAt(rt.now())
+		},
+	}
+}
+
+// joinPredicates joins the descriptions of predicates.
+func joinPredicates(predicates []Predicate) string {
+	descriptions := make([]string, len(predicates))
+	for i, p := range predicates {
+		descriptions[i] = fmt.Sprint(p)
+	}
+	return strings.Join(descriptions, ", ")
+}
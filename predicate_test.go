@@ -0,0 +1,170 @@
+package groute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPredicates(t *testing.T) {
+	tests := []struct {
+		name string
+		pred Predicate
+		req  func() *http.Request
+		want bool
+	}{
+		{"header", Header("X-Beta", "1"), withHeader("X-Beta", "1"), true},
+		{"header mismatch", Header("X-Beta", "1"), withHeader("X-Beta", "2"), false},
+		{"header present", Header("X-Beta", ""), withHeader("X-Beta", "2"), true},
+		{"header absent", Header("X-Beta", ""), withHeader("X-Other", "1"), false},
+		{"query", Query("v", "2"), newReq("/?v=1&v=2"), true},
+		{"query mismatch", Query("v", "2"), newReq("/?v=1"), false},
+		{"query present", Query("debug", ""), newReq("/?debug"), true},
+		{"host", Host("api.example.com"), newReq("http://API.example.com:8080/"), true},
+		{"host mismatch", Host("api.example.com"), newReq("http://www.example.com/"), false},
+		{"content type", ContentType("application/json"), withHeader("Content-Type", "application/json; charset=utf-8"), true},
+		{"content type wildcard", ContentType("image/*"), withHeader("Content-Type", "image/png"), true},
+		{"content type missing", ContentType("application/json"), newReq("/"), false},
+		{"during", During("* * * * *"), newReq("/"), true},
+		{"during never", During("0 0 31 2 *"), newReq("/"), false},
+		{"all", All(Header("X-Beta", "1"), Query("v", "")), withHeader("X-Beta", "1"), false},
+		{"any", Any(Header("X-Beta", "1"), Query("v", "")), newReq("/?v"), true},
+		{"not", Not(Header("X-Beta", "")), newReq("/"), true},
+		{"func", Func("always", func(*http.Request) bool { return true }), newReq("/"), true},
+	}
+	for _, tt := range tests {
+		if got := tt.pred.Match(tt.req()); got != tt.want {
+			t.Errorf("%s: %s matched %v, want %v", tt.name, tt.pred, got, tt.want)
+		}
+	}
+}
+
+func TestPredicateString(t *testing.T) {
+	tests := []struct {
+		pred Predicate
+		want string
+	}{
+		{Header("X-Beta", "1"), "header(X-Beta=1)"},
+		{Query("debug", ""), "query(debug)"},
+		{Host("a.example", "b.example"), "host(a.example, b.example)"},
+		{ContentType("image/*"), "content-type(image/*)"},
+		{During("0 9 * * 1-5"), "during(0 9 * * 1-5)"},
+		{Between(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), time.Time{}), "between(2024-01-01T09:00:00Z, *)"},
+		{Not(Any(Header("X-Beta", ""), All(Query("v", "2"), Func("canary", nil)))), "not(any(header(X-Beta), all(query(v=2), canary)))"},
+		{Condition(nil), "condition"},
+	}
+	for _, tt := range tests {
+		if got := tt.pred.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestWhere(t *testing.T) {
+	handler := func(name string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(name)) }
+	}
+	g := NewRouter()
+	beta := g.Get("/search", handler("v2")).Where(Any(Header("X-Beta", "1"), Query("v", "2")))
+	g.Get("/search", handler("v1"))
+
+	tests := []struct {
+		req  func() *http.Request
+		body string
+	}{
+		{withHeader("X-Beta", "1"), "v2"},
+		{newReq("/search?v=2"), "v2"},
+		{newReq("/search"), "v1"},
+	}
+	for _, tt := range tests {
+		req := tt.req()
+		req.URL.Path = "/search"
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, req)
+		if w.Body.String() != tt.body {
+			t.Errorf("%s: body = %q, want %q", req.URL, w.Body.String(), tt.body)
+		}
+	}
+
+	if got, want := beta.Conditions(), []string{"any(header(X-Beta=1), query(v=2))"}; !slices.Equal(got, want) {
+		t.Errorf("Conditions() = %q, want %q", got, want)
+	}
+	for _, route := range g.Manifest().Routes {
+		if route.Path == "/search" && len(route.Conditions) == 1 {
+			return
+		}
+	}
+	t.Errorf("manifest does not list the route's conditions")
+}
+
+func TestTimePredicates(t *testing.T) {
+	clock := newFakeClock() // 2024-01-01 00:00 UTC, a Monday
+	g := NewRouter()
+	g.SetClock(clock)
+	handler := func(name string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(name)) }
+	}
+	g.Where(During("* 9-17 * * 1-5")).Get("/support", handler("chat"))
+	g.Get("/support", handler("email"))
+	g.Where(Between(clock.Now().Add(time.Hour), clock.Now().Add(2*time.Hour))).Get("/sale", handler("sale"))
+	g.Get("/sale", handler("regular"))
+
+	tests := []struct {
+		advance time.Duration
+		path    string
+		body    string
+	}{
+		{0, "/support", "email"},
+		{0, "/sale", "regular"},
+		{time.Hour, "/sale", "sale"},
+		{time.Hour, "/sale", "regular"},
+		{7 * time.Hour, "/support", "chat"},
+	}
+	for _, tt := range tests {
+		clock.Advance(tt.advance)
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Body.String() != tt.body {
+			t.Errorf("%s at %s: body = %q, want %q", tt.path, clock.Now().Format(time.Kitchen), w.Body.String(), tt.body)
+		}
+	}
+}
+
+func TestLintShadowedPredicates(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	g := NewRouter()
+	g.Get("/a", ok).Where(Header("X-Beta", "1"))
+	g.Get("/a", ok).Where(Header("X-Beta", "1"))
+	g.Get("/b", ok).When(func(*http.Request) bool { return true })
+	g.Get("/b", ok).When(func(*http.Request) bool { return false })
+	g.Get("/c", ok).Where(All(Condition(func(*http.Request) bool { return true })))
+	g.Get("/c", ok).Where(All(Condition(func(*http.Request) bool { return false })))
+
+	var found bool
+	for _, w := range g.Lint() {
+		if strings.Contains(w, `"GET /b"`) || strings.Contains(w, `"GET /c"`) {
+			t.Errorf("unexpected warning for opaque conditions: %s", w)
+		}
+		if strings.Contains(w, `"GET /a"`) && strings.Contains(w, "header(X-Beta=1)") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Lint() = %q, want a warning about shadowed predicates", g.Lint())
+	}
+}
+
+func newReq(target string) func() *http.Request {
+	return func() *http.Request { return httptest.NewRequest("GET", target, nil) }
+}
+
+func withHeader(name, value string) func() *http.Request {
+	return func() *http.Request {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(name, value)
+		return req
+	}
+}
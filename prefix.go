@@ -0,0 +1,92 @@
+package groute
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// basePathKey is the context key for the accumulated stripped prefix.
+type basePathKey struct{}
+
+// StripPrefix returns a middleware removing prefix from the request path
+// before calling the next handler, like http.StripPrefix but on whole path
+// segments only. Unlike http.StripPrefix it also updates r.RequestURI and
+// records the removed prefix, so OriginalPath, BasePath and URL generation
+// keep working behind it. Requests outside prefix receive 404 Not Found.
+func StripPrefix(prefix string) Middleware {
+	prefix = "/" + strings.Trim(prefix, "/")
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		if prefix == "/" {
+			return next
+		}
+		return func(w http.ResponseWriter, r *http.Request) {
+			path, ok := cutPathPrefix(r.URL.Path, prefix)
+			if !ok {
+				Error(w, r, NewStatusError(http.StatusNotFound, "404 page not found"))
+				return
+			}
+			r2 := withPath(r, path)
+			if rawPath, ok := cutPathPrefix(r.URL.RawPath, prefix); ok {
+				r2.URL.RawPath = rawPath
+			}
+			r2.RequestURI = r2.URL.RequestURI()
+			ctx := context.WithValue(r2.Context(), basePathKey{}, BasePath(r)+prefix)
+			next(w, r2.WithContext(ctx))
+		}
+	}
+}
+
+// AddPrefix returns a middleware prepending prefix to the request path
+// before calling the next handler, e.g. to hand requests to a handler that
+// expects to live below a fixed path. r.RequestURI is updated accordingly
+// and the original path stays available through OriginalPath.
+func AddPrefix(prefix string) Middleware {
+	prefix = "/" + strings.Trim(prefix, "/")
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		if prefix == "/" {
+			return next
+		}
+		return func(w http.ResponseWriter, r *http.Request) {
+			r2 := withPath(r, prefix+r.URL.Path)
+			if r.URL.RawPath != "" {
+				r2.URL.RawPath = prefix + r.URL.RawPath
+			}
+			r2.RequestURI = r2.URL.RequestURI()
+			next(w, r2)
+		}
+	}
+}
+
+// BasePath returns the prefixes removed by StripPrefix on the way to the
+// current handler, e.g. "/static" for a file server mounted there. It is
+// the path the handler is mounted at from the client's point of view.
+func BasePath(r *http.Request) string {
+	base, _ := r.Context().Value(basePathKey{}).(string)
+	return base
+}
+
+// withPath returns a copy of r with the given path, recording the original
+// path for OriginalPath if it was not recorded yet.
+func withPath(r *http.Request, path string) *http.Request {
+	if _, ok := r.Context().Value(originalPathKey{}).(string); !ok {
+		r = r.WithContext(context.WithValue(r.Context(), originalPathKey{}, r.URL.Path))
+	}
+	r2 := shallowCopyRequest(r)
+	r2.URL.Path = path
+	r2.URL.RawPath = ""
+	return r2
+}
+
+// cutPathPrefix removes prefix from path if it matches whole segments.
+// The result always starts with "/".
+func cutPathPrefix(path, prefix string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, prefix)
+	if !ok || (rest != "" && rest[0] != '/') {
+		return "", false
+	}
+	if rest == "" {
+		rest = "/"
+	}
+	return rest, true
+}
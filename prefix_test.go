@@ -0,0 +1,113 @@
+package groute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStripPrefix(t *testing.T) {
+	tests := []struct {
+		name               string
+		requestPath        string
+		expectedStatus     int
+		expectedPath       string
+		expectedRequestURI string
+	}{
+		{"nested path", "/static/css/app.css?v=1", http.StatusOK, "/css/app.css", "/css/app.css?v=1"},
+		{"exact prefix", "/static", http.StatusOK, "/", "/"},
+		{"escaped path", "/static/a%2Fb", http.StatusOK, "/a/b", "/a%2Fb"},
+		{"partial segment", "/staticx/app.css", http.StatusNotFound, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path, requestURI, original, base string
+			handler := StripPrefix("/static/")(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				requestURI = r.RequestURI
+				original = OriginalPath(r)
+				base = BasePath(r)
+			})
+
+			req := httptest.NewRequest("GET", tt.requestPath, nil)
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			if path != tt.expectedPath {
+				t.Errorf("expected path %q, got %q", tt.expectedPath, path)
+			}
+			if requestURI != tt.expectedRequestURI {
+				t.Errorf("expected RequestURI %q, got %q", tt.expectedRequestURI, requestURI)
+			}
+			if original != req.URL.Path {
+				t.Errorf("expected original path %q, got %q", req.URL.Path, original)
+			}
+			if base != "/static" {
+				t.Errorf("expected base path '/static', got %q", base)
+			}
+		})
+	}
+}
+
+func TestStripPrefixNested(t *testing.T) {
+	var path, base, original string
+	handler := StripPrefix("/app")(StripPrefix("/assets")(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		base = BasePath(r)
+		original = OriginalPath(r)
+	}))
+
+	handler(httptest.NewRecorder(), httptest.NewRequest("GET", "/app/assets/logo.png", nil))
+
+	if path != "/logo.png" {
+		t.Errorf("expected path '/logo.png', got %q", path)
+	}
+	if base != "/app/assets" {
+		t.Errorf("expected base path '/app/assets', got %q", base)
+	}
+	if original != "/app/assets/logo.png" {
+		t.Errorf("expected original path '/app/assets/logo.png', got %q", original)
+	}
+}
+
+func TestAddPrefix(t *testing.T) {
+	var path, requestURI, original string
+	handler := AddPrefix("/v2")(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		requestURI = r.RequestURI
+		original = OriginalPath(r)
+	})
+
+	handler(httptest.NewRecorder(), httptest.NewRequest("GET", "/users?page=2", nil))
+
+	if path != "/v2/users" {
+		t.Errorf("expected path '/v2/users', got %q", path)
+	}
+	if requestURI != "/v2/users?page=2" {
+		t.Errorf("expected RequestURI '/v2/users?page=2', got %q", requestURI)
+	}
+	if original != "/users" {
+		t.Errorf("expected original path '/users', got %q", original)
+	}
+}
+
+func TestStripPrefixWithRouter(t *testing.T) {
+	g := NewRouter()
+	var path string
+	g.Get("/files/{path...}", StripPrefix("/files")(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+	}))
+
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/files/docs/a.txt", nil))
+
+	if path != "/docs/a.txt" {
+		t.Errorf("expected path '/docs/a.txt', got %q", path)
+	}
+}
@@ -0,0 +1,167 @@
+package groute
+
+import (
+	"net/http"
+	"runtime/metrics"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Profile marks the route for allocation profiling by a Profiler.
+func (rt *Route) Profile() *Route {
+	rt.update(func(info *routeInfo) {
+		info.profile = true
+	})
+	return rt
+}
+
+// Profiler samples runtime allocation and goroutine metrics around the
+// handlers of routes marked with Profile, to help locate memory-hungry
+// endpoints without an external profiler.
+//
+// The runtime counters are process-wide, so allocations of concurrent
+// requests are attributed to whichever profiled requests overlap them.
+// Figures are most accurate under light load and are meant for debugging,
+// not accounting.
+type Profiler struct {
+	routes sync.Map // pattern -> *routeProfile
+}
+
+// RouteProfile is a snapshot of a route's profiling aggregates.
+type RouteProfile struct {
+	Pattern string `json:"pattern"`
+	// Samples is the number of profiled requests.
+	Samples uint64 `json:"samples"`
+	// AllocBytes and AllocObjects are the heap allocations observed while
+	// the route's handler ran, summed over all samples.
+	AllocBytes   uint64 `json:"alloc_bytes"`
+	AllocObjects uint64 `json:"alloc_objects"`
+	// MaxAllocBytes is the largest allocation volume of a single request.
+	MaxAllocBytes uint64 `json:"max_alloc_bytes"`
+	// Goroutines is the net number of goroutines left running by handlers,
+	// summed over all samples. A steadily growing value hints at a leak.
+	Goroutines int64 `json:"goroutines"`
+}
+
+// AvgAllocBytes returns the average bytes allocated per request.
+func (p RouteProfile) AvgAllocBytes() uint64 {
+	if p.Samples == 0 {
+		return 0
+	}
+	return p.AllocBytes / p.Samples
+}
+
+type routeProfile struct {
+	samples       atomic.Uint64
+	allocBytes    atomic.Uint64
+	allocObjects  atomic.Uint64
+	maxAllocBytes atomic.Uint64
+	goroutines    atomic.Int64
+}
+
+// profileMetrics are the runtime/metrics samples read around handlers.
+var profileMetrics = []string{
+	"/gc/heap/allocs:bytes",
+	"/gc/heap/allocs:objects",
+	"/sched/goroutines:goroutines",
+}
+
+// NewProfiler creates an empty Profiler.
+func NewProfiler() *Profiler {
+	return &Profiler{}
+}
+
+// Middleware returns a middleware profiling the routes marked with Profile.
+// Other requests pass through untouched.
+func (p *Profiler) Middleware() Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			route := CurrentRoute(r)
+			if route == nil || !route.info.Load().profile {
+				next(w, r)
+				return
+			}
+			before := readProfileMetrics()
+			next(w, r)
+			after := readProfileMetrics()
+			p.record(r.Pattern, before, after)
+		}
+	}
+}
+
+// readProfileMetrics reads the current values of profileMetrics.
+func readProfileMetrics() [3]uint64 {
+	samples := make([]metrics.Sample, len(profileMetrics))
+	for i, name := range profileMetrics {
+		samples[i].Name = name
+	}
+	metrics.Read(samples)
+	var values [3]uint64
+	for i, s := range samples {
+		if s.Value.Kind() == metrics.KindUint64 {
+			values[i] = s.Value.Uint64()
+		}
+	}
+	return values
+}
+
+// record adds one sample to the aggregates of pattern.
+func (p *Profiler) record(pattern string, before, after [3]uint64) {
+	v, ok := p.routes.Load(pattern)
+	if !ok {
+		v, _ = p.routes.LoadOrStore(pattern, &routeProfile{})
+	}
+	rp := v.(*routeProfile)
+	bytes := after[0] - before[0]
+	rp.samples.Add(1)
+	rp.allocBytes.Add(bytes)
+	rp.allocObjects.Add(after[1] - before[1])
+	rp.goroutines.Add(int64(after[2]) - int64(before[2]))
+	for {
+		old := rp.maxAllocBytes.Load()
+		if bytes <= old || rp.maxAllocBytes.CompareAndSwap(old, bytes) {
+			break
+		}
+	}
+}
+
+// Snapshot returns the aggregates of every profiled route seen, sorted by
+// average allocation, largest first.
+func (p *Profiler) Snapshot() []RouteProfile {
+	var profiles []RouteProfile
+	p.routes.Range(func(key, value any) bool {
+		rp := value.(*routeProfile)
+		profiles = append(profiles, RouteProfile{
+			Pattern:       key.(string),
+			Samples:       rp.samples.Load(),
+			AllocBytes:    rp.allocBytes.Load(),
+			AllocObjects:  rp.allocObjects.Load(),
+			MaxAllocBytes: rp.maxAllocBytes.Load(),
+			Goroutines:    rp.goroutines.Load(),
+		})
+		return true
+	})
+	sort.Slice(profiles, func(i, j int) bool {
+		ai, aj := profiles[i].AvgAllocBytes(), profiles[j].AvgAllocBytes()
+		if ai != aj {
+			return ai > aj
+		}
+		return profiles[i].Pattern < profiles[j].Pattern
+	})
+	return profiles
+}
+
+// Handler returns a handler exposing the aggregates as JSON, for mounting
+// on an internal admin endpoint:
+//
+//	admin.Get("/debug/routes/profile", profiler.Handler().ServeHTTP)
+func (p *Profiler) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		profiles := p.Snapshot()
+		if profiles == nil {
+			profiles = []RouteProfile{}
+		}
+		_ = JSON(w, r, http.StatusOK, profiles)
+	})
+}
@@ -0,0 +1,67 @@
+package groute
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var profileSink []byte
+
+func TestProfiler(t *testing.T) {
+	p := NewProfiler()
+	g := NewRouter()
+	g.Use(p.Middleware())
+	g.Get("/export", func(w http.ResponseWriter, r *http.Request) {
+		profileSink = make([]byte, 1<<20)
+	}).Profile()
+	g.Get("/leak", func(w http.ResponseWriter, r *http.Request) {
+		go time.Sleep(time.Second)
+	}).Profile()
+	g.Get("/plain", func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, path := range []string{"/export", "/export", "/leak", "/plain"} {
+		g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	profiles := p.Snapshot()
+	if len(profiles) != 2 {
+		t.Fatalf("expected 2 profiled routes, got %+v", profiles)
+	}
+	export := profiles[0]
+	if export.Pattern != "GET /export" || export.Samples != 2 {
+		t.Errorf("expected 2 samples for GET /export first, got %+v", export)
+	}
+	if export.AllocBytes < 2<<20 || export.MaxAllocBytes < 1<<20 || export.AvgAllocBytes() < 1<<20 {
+		t.Errorf("expected at least 1MiB allocated per request, got %+v", export)
+	}
+	if leak := profiles[1]; leak.Pattern != "GET /leak" || leak.Goroutines < 1 {
+		t.Errorf("expected GET /leak to leave a goroutine running, got %+v", leak)
+	}
+}
+
+func TestProfilerHandler(t *testing.T) {
+	p := NewProfiler()
+	rec := httptest.NewRecorder()
+	p.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Body.String() != "[]\n" {
+		t.Errorf("expected empty JSON array, got %q", rec.Body.String())
+	}
+
+	g := NewRouter()
+	g.Use(p.Middleware())
+	g.Get("/", func(w http.ResponseWriter, r *http.Request) {}).Profile()
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	rec = httptest.NewRecorder()
+	p.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	var profiles []RouteProfile
+	if err := json.Unmarshal(rec.Body.Bytes(), &profiles); err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 1 || profiles[0].Pattern != "GET /" || profiles[0].Samples != 1 {
+		t.Errorf("unexpected profiles %+v", profiles)
+	}
+}
@@ -0,0 +1,251 @@
+package groute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync/atomic"
+	"time"
+)
+
+// ReverseProxy forwards requests to one or more upstream servers. Create it
+// with Proxy.
+//
+// Request and response bodies are streamed in both directions without
+// buffering, so large uploads such as multipart forms pass through with
+// constant memory, and trailers are preserved. Configure the exported
+// fields before the proxy starts serving.
+type ReverseProxy struct {
+	// Timeout bounds each upstream request, from connecting until the
+	// response body is done. Websocket upgrades and server-sent events are
+	// long-lived, so for them it only bounds the wait for the response
+	// header. Routes override it with a time.Duration under MetaTimeout;
+	// values of other types are ignored. Zero means no timeout.
+	Timeout time.Duration
+	// ConnectRetries is how many more times a request is tried when the
+	// upstream connection cannot be established, preferring upstreams not
+	// tried yet. Requests are only retried before any of their body was sent.
+	ConnectRetries int
+	// Transport performs upstream requests. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+	// Balance selects how requests are spread over the upstreams.
+	Balance BalanceMode
+	// MaxFails is the number of consecutive failures after which an upstream
+	// is ejected for FailTimeout. Zero disables ejection.
+	MaxFails int
+	// FailTimeout is how long an ejected upstream receives no requests.
+	// Defaults to 10 seconds.
+	FailTimeout time.Duration
+	// Clock is used to time ejections. Defaults to the real clock.
+	Clock Clock
+
+	upstreams []*upstream
+	next      atomic.Uint64
+	proxy     *httputil.ReverseProxy
+}
+
+// Proxy returns a reverse proxy to the targets, e.g. "http://10.0.0.5:8080/v2".
+// The request path is appended to the target's path, and X-Forwarded-For,
+// X-Forwarded-Host and X-Forwarded-Proto are set for the upstream:
+//
+//	upstream, err := groute.Proxy("http://users-1.internal", "http://users-2.internal")
+//	api.Handle("/users/", groute.StripPrefix("/api")(upstream.ServeHTTP)).
+//		Meta(groute.MetaTimeout, 30*time.Second)
+//
+// Upstream failures go through the route's error handler as 502 Bad
+// Gateway, or 504 Gateway Timeout when the timeout expired.
+func Proxy(targets ...string) (*ReverseProxy, error) {
+	if len(targets) == 0 {
+		return nil, errors.New("groute: proxy needs at least one target")
+	}
+	p := &ReverseProxy{}
+	for i, target := range targets {
+		u, err := url.Parse(target)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("groute: proxy target %q must be an absolute URL", target)
+		}
+		p.upstreams = append(p.upstreams, &upstream{index: i, target: u})
+	}
+	p.proxy = &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			// The upstream URL is set by the transport, which picks the
+			// upstream for each attempt.
+			pr.SetXForwarded()
+			if policy := routeHeaderPolicy(pr.In); policy != nil {
+				policy.Request.apply(pr.Out.Header)
+			}
+		},
+		Transport:      proxyTransport{p},
+		ModifyResponse: modifyResponse,
+		ErrorHandler:   proxyError,
+	}
+	return p, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (p *ReverseProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	state := &proxyState{}
+	defer state.end()
+	route := CurrentRoute(r)
+	if route != nil && route.group != nil {
+		state.router = route.group.root()
+	}
+	timeout := p.Timeout
+	if v, ok := route.Value(MetaTimeout); ok {
+		if d, ok := v.(time.Duration); ok {
+			timeout = d
+		}
+	}
+	if timeout > 0 {
+		// A timer rather than a deadline, so that handleStream can stop it.
+		ctx, cancel := context.WithCancelCause(r.Context())
+		defer cancel(nil)
+		state.timer = time.AfterFunc(timeout, func() { cancel(context.DeadlineExceeded) })
+		defer state.timer.Stop()
+		r = r.WithContext(ctx)
+	}
+	p.proxy.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), proxyStateKey{}, state)))
+}
+
+// proxyStateKey is the context key for the request's *proxyState.
+type proxyStateKey struct{}
+
+// proxyState follows a request through the proxy.
+type proxyState struct {
+	// timer enforces the proxy timeout, if any.
+	timer *time.Timer
+	// router is the router that dispatched the request, if any.
+	router *Router
+	// endStream releases a websocket or event stream.
+	endStream func()
+}
+
+// end releases the request's resources once it was served.
+func (s *proxyState) end() {
+	if s.endStream != nil {
+		s.endStream()
+	}
+}
+
+// modifyResponse prepares an upstream response for the client.
+func modifyResponse(resp *http.Response) error {
+	if resp.Request == nil {
+		return nil
+	}
+	handleStream(resp)
+	if policy := routeHeaderPolicy(resp.Request); policy != nil {
+		policy.Response.apply(resp.Header)
+	}
+	return nil
+}
+
+// handleStream prepares a websocket upgrade or server-sent events response:
+// the proxy timeout is stopped so the stream can outlive it, and the stream
+// is tracked and ended when the router drains.
+func handleStream(resp *http.Response) {
+	upgrade := resp.StatusCode == http.StatusSwitchingProtocols
+	if !upgrade && !isEventStream(resp.Header) {
+		return
+	}
+	state, ok := resp.Request.Context().Value(proxyStateKey{}).(*proxyState)
+	if !ok {
+		return
+	}
+	if state.timer != nil {
+		state.timer.Stop()
+	}
+	if state.router == nil {
+		return
+	}
+
+	// Upgraded connections are closed outright, as frames are opaque to the
+	// proxy. Event streams end as if the upstream finished them.
+	conn := resp.Body
+	end := func() { _ = conn.Close() }
+	if !upgrade {
+		body := &drainingBody{ReadCloser: resp.Body}
+		resp.Body = body
+		end = body.drain
+	}
+	done := state.router.trackStream()
+	stop := context.AfterFunc(state.router.draining, end)
+	state.endStream = func() {
+		stop()
+		done()
+	}
+}
+
+// proxyTransport sends requests to the proxy's upstreams, retrying failed
+// connections.
+type proxyTransport struct {
+	p *ReverseProxy
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t proxyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	p := t.p
+	transport := p.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	// The transport closes the request body when a request fails; keep it
+	// open so an untouched body can be sent again.
+	var body *retryBody
+	if p.ConnectRetries > 0 && req.Body != nil && req.Body != http.NoBody {
+		body = &retryBody{ReadCloser: req.Body}
+		req.Body = body
+	}
+	turn := p.next.Add(1) - 1
+	tried := make([]bool, len(p.upstreams))
+	for attempt := 0; ; attempt++ {
+		u := p.pick(turn, tried)
+		tried[u.index] = true
+		resp, err := p.send(transport, u, req)
+		if err == nil || attempt >= p.ConnectRetries || !isConnectError(err) || (body != nil && body.read.Load()) {
+			return resp, err
+		}
+	}
+}
+
+// retryBody is a request body whose Close is deferred to the server, so it
+// survives failed connection attempts.
+type retryBody struct {
+	io.ReadCloser
+	read atomic.Bool
+}
+
+func (b *retryBody) Read(p []byte) (int, error) {
+	b.read.Store(true)
+	return b.ReadCloser.Read(p)
+}
+
+func (b *retryBody) Close() error {
+	return nil
+}
+
+// isConnectError reports whether err happened while dialing the upstream.
+func isConnectError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// proxyError reports an upstream failure through the route's error handler.
+func proxyError(w http.ResponseWriter, r *http.Request, err error) {
+	code := http.StatusBadGateway
+	switch cause := context.Cause(r.Context()); {
+	case errors.Is(cause, context.DeadlineExceeded), errors.Is(err, context.DeadlineExceeded):
+		code = http.StatusGatewayTimeout
+	case errors.Is(cause, context.Canceled):
+		code = StatusClientClosedRequest
+	}
+	Error(w, r, &StatusError{Code: code, Err: err})
+}
@@ -0,0 +1,282 @@
+package groute
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProxyInvalidTarget(t *testing.T) {
+	for _, target := range []string{"", "/relative", "users.internal", "http://%zz"} {
+		if _, err := Proxy(target); err == nil {
+			t.Errorf("Proxy(%q) should fail", target)
+		}
+	}
+}
+
+func TestProxyStreamsBody(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Trailer", "X-Checksum")
+		w.Header().Set("X-Path", r.URL.Path)
+		w.Header().Set("X-Forwarded", r.Header.Get("X-Forwarded-Host"))
+		w.Write(body)
+		w.Header().Set("X-Checksum", "abc")
+	}))
+	defer upstream.Close()
+
+	p, err := Proxy(upstream.URL + "/v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewRouter()
+	g.Handle("/api/", StripPrefix("/api")(p.ServeHTTP))
+	front := httptest.NewServer(g)
+	defer front.Close()
+
+	pr, pw := io.Pipe()
+	go func() {
+		for i := 0; i < 100; i++ {
+			pw.Write([]byte(strings.Repeat("x", 1024)))
+		}
+		pw.Close()
+	}()
+	resp, err := http.Post(front.URL+"/api/upload", "multipart/form-data; boundary=x", pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if len(body) != 100*1024 {
+		t.Errorf("body length = %d, want %d", len(body), 100*1024)
+	}
+	if got := resp.Header.Get("X-Path"); got != "/v2/upload" {
+		t.Errorf("upstream path = %q, want /v2/upload", got)
+	}
+	if got := resp.Header.Get("X-Forwarded"); got != strings.TrimPrefix(front.URL, "http://") {
+		t.Errorf("X-Forwarded-Host = %q, want %q", got, strings.TrimPrefix(front.URL, "http://"))
+	}
+	if got := resp.Trailer.Get("X-Checksum"); got != "abc" {
+		t.Errorf("trailer = %q, want abc", got)
+	}
+}
+
+func TestProxyTimeout(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer upstream.Close()
+
+	p, err := Proxy(upstream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Timeout = time.Minute
+	g := NewRouter()
+	g.Handle("/slow", p).Meta(MetaTimeout, 20*time.Millisecond)
+
+	// A MetaTimeout of another type, e.g. from config, leaves the
+	// proxy's timeout in place rather than disabling it.
+	fallback, err := Proxy(upstream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fallback.Timeout = 20 * time.Millisecond
+	g.Handle("/untyped", fallback).Meta(MetaTimeout, "5s")
+
+	for _, path := range []string{"/slow", "/untyped"} {
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusGatewayTimeout {
+			t.Errorf("%s: status = %d, want %d", path, w.Code, http.StatusGatewayTimeout)
+		}
+	}
+}
+
+func TestProxyUnreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	p, err := Proxy("http://" + addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewRouter()
+	g.Handle("/", p)
+
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadGateway)
+	}
+}
+
+// flakyTransport fails the first n requests as if the upstream refused the
+// connection.
+type flakyTransport struct {
+	n     int
+	calls int
+}
+
+func (t *flakyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.calls++
+	if t.calls <= t.n {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestProxyConnectRetries(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, r.Body)
+	}))
+	defer upstream.Close()
+
+	tests := []struct {
+		failures int
+		retries  int
+		status   int
+		calls    int
+	}{
+		{0, 0, http.StatusOK, 1},
+		{1, 0, http.StatusBadGateway, 1},
+		{1, 2, http.StatusOK, 2},
+		{3, 2, http.StatusBadGateway, 3},
+	}
+	for _, tt := range tests {
+		p, err := Proxy(upstream.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		transport := &flakyTransport{n: tt.failures}
+		p.Transport = transport
+		p.ConnectRetries = tt.retries
+
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader("payload")))
+		if w.Code != tt.status {
+			t.Errorf("failures=%d retries=%d: status = %d, want %d", tt.failures, tt.retries, w.Code, tt.status)
+		}
+		if transport.calls != tt.calls {
+			t.Errorf("failures=%d retries=%d: calls = %d, want %d", tt.failures, tt.retries, transport.calls, tt.calls)
+		}
+		if tt.status == http.StatusOK && w.Body.String() != "payload" {
+			t.Errorf("failures=%d retries=%d: body = %q, want payload", tt.failures, tt.retries, w.Body.String())
+		}
+	}
+}
+
+func TestProxyUpgrade(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "echo" {
+			http.Error(w, "upgrade required", http.StatusUpgradeRequired)
+			return
+		}
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+		rw.Flush()
+		io.Copy(conn, rw)
+	}))
+	defer upstream.Close()
+
+	p, err := Proxy(upstream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Timeout = 20 * time.Millisecond
+	g := NewRouter()
+	g.SetServerTiming(true)
+	g.Use(Compress(0))
+	g.Handle("/ws", p)
+	front := httptest.NewServer(g)
+	defer front.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(front.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("GET /ws HTTP/1.1\r\nHost: example.com\r\nConnection: Upgrade\r\nUpgrade: echo\r\nAccept-Encoding: gzip\r\n\r\n"))
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusSwitchingProtocols)
+	}
+
+	// The connection outlives the proxy timeout.
+	time.Sleep(50 * time.Millisecond)
+	conn.Write([]byte("ping"))
+	buf := make([]byte, 4)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := io.ReadFull(br, buf); err != nil || string(buf) != "ping" {
+		t.Errorf("echo = %q, %v; want ping", buf, err)
+	}
+}
+
+func TestProxyEventStream(t *testing.T) {
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: first\n\n")
+		http.NewResponseController(w).Flush()
+		<-release
+		io.WriteString(w, "data: second\n\n")
+	}))
+	defer upstream.Close()
+
+	p, err := Proxy(upstream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Timeout = 20 * time.Millisecond
+	g := NewRouter()
+	g.SetServerTiming(true)
+	g.Use(Compress(0))
+	g.Handle("/events", p)
+	front := httptest.NewServer(g)
+	defer front.Close()
+
+	req, _ := http.NewRequest("GET", front.URL+"/events", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if enc := resp.Header.Get("Content-Encoding"); enc != "" {
+		t.Errorf("Content-Encoding = %q, want none", enc)
+	}
+
+	// The first event arrives while the upstream is still streaming.
+	br := bufio.NewReader(resp.Body)
+	if line, err := br.ReadString('\n'); err != nil || line != "data: first\n" {
+		t.Fatalf("first line = %q, %v", line, err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	rest, err := io.ReadAll(br)
+	if err != nil || string(rest) != "\ndata: second\n\n" {
+		t.Errorf("rest = %q, %v; want the second event", rest, err)
+	}
+}
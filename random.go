@@ -0,0 +1,56 @@
+package groute
+
+import (
+	"crypto/rand"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// SetRandom sets the source of random bytes used by the router and its
+// middlewares, such as RequestID, so integration tests can be reproducible:
+//
+//	r.SetRandom(mathrand.NewChaCha8([32]byte{}))
+//
+// Reads from src are serialized, so it need not be safe for concurrent use.
+// A nil src restores crypto/rand. It applies to the whole router, even when
+// set on a group, and should be set before the router starts serving.
+func (g *Router) SetRandom(src io.Reader) {
+	root := g.root()
+	if src == nil {
+		root.random = nil
+		return
+	}
+	root.random = &lockedReader{r: src}
+}
+
+// Random returns the router's source of random bytes.
+func (g *Router) Random() io.Reader {
+	if src := g.root().random; src != nil {
+		return src
+	}
+	return rand.Reader
+}
+
+// Random returns the source of random bytes of the router that dispatched
+// r. Outside a matched route, e.g. in pre-routing middlewares, it returns
+// crypto/rand.Reader.
+func Random(r *http.Request) io.Reader {
+	if route := CurrentRoute(r); route != nil && route.group != nil {
+		return route.group.Random()
+	}
+	return rand.Reader
+}
+
+// lockedReader serializes reads from a source that is not safe for
+// concurrent use.
+type lockedReader struct {
+	mu sync.Mutex
+	r  io.Reader
+}
+
+func (l *lockedReader) Read(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Read(p)
+}
@@ -0,0 +1,44 @@
+package groute
+
+import (
+	"crypto/rand"
+	mathrand "math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterRandom(t *testing.T) {
+	newSeeded := func() *Router {
+		g := NewRouter()
+		g.SetRandom(mathrand.NewChaCha8([32]byte{1}))
+		g.Use(RequestID())
+		g.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+		return g
+	}
+	ids := func(g *Router) []string {
+		var ids []string
+		for i := 0; i < 3; i++ {
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+			ids = append(ids, w.Header().Get(RequestIDHeader))
+		}
+		return ids
+	}
+
+	first, second := ids(newSeeded()), ids(newSeeded())
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("expected reproducible IDs, got %q and %q", first[i], second[i])
+		}
+	}
+	if first[0] == first[1] {
+		t.Errorf("expected distinct IDs, got %v", first)
+	}
+
+	g := newSeeded()
+	g.Group("/api").SetRandom(nil)
+	if g.Random() != rand.Reader {
+		t.Error("expected nil source to restore crypto/rand")
+	}
+}
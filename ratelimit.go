@@ -0,0 +1,170 @@
+package groute
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// KeyFunc extracts the key a request is throttled by, such as an API key,
+// user ID or tenant. An empty key exempts the request.
+type KeyFunc func(r *http.Request) string
+
+// KeyByHeader returns a KeyFunc keying requests by the named header.
+func KeyByHeader(name string) KeyFunc {
+	return func(r *http.Request) string {
+		return r.Header.Get(name)
+	}
+}
+
+// KeyByPathValue returns a KeyFunc keying requests by the named path
+// parameter, e.g. "tenant" in "/t/{tenant}/orders".
+func KeyByPathValue(name string) KeyFunc {
+	return func(r *http.Request) string {
+		return r.PathValue(name)
+	}
+}
+
+// KeyByIP keys requests by the client IP in r.RemoteAddr.
+func KeyByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Rate is a request budget: up to Requests per Per, with bursts of up to
+// Requests. The zero Rate means no limit.
+type Rate struct {
+	Requests int
+	Per      time.Duration
+}
+
+// RateStore looks up per-key rates, e.g. from the plan of a tenant. Lookups
+// run on every request, so stores backed by a database should cache.
+type RateStore interface {
+	// Rate returns the rate for key, or ok false to use the default.
+	Rate(ctx context.Context, key string) (rate Rate, ok bool, err error)
+}
+
+// RateMap is a RateStore holding fixed per-key rates.
+type RateMap map[string]Rate
+
+// Rate implements RateStore.
+func (m RateMap) Rate(ctx context.Context, key string) (Rate, bool, error) {
+	rate, ok := m[key]
+	return rate, ok, nil
+}
+
+// RateLimiter throttles requests per key with a token bucket:
+//
+//	limiter := &groute.RateLimiter{
+//		Key:       groute.KeyByHeader("X-API-Key"),
+//		Rate:      groute.Rate{Requests: 100, Per: time.Minute},
+//		Overrides: groute.RateMap{"enterprise-key": {}},
+//	}
+//	api.Use(limiter.Middleware())
+//
+// Throttled requests fail with 429 Too Many Requests and a Retry-After
+// header, through the group's error handler. Time is read from the router's
+// Clock.
+type RateLimiter struct {
+	// Key extracts the throttling key. Defaults to KeyByIP.
+	Key KeyFunc
+	// Rate applies to keys without an override.
+	Rate Rate
+	// Overrides provides per-key rates. Lookup errors fall back to Rate.
+	Overrides RateStore
+
+	mu        sync.Mutex
+	buckets   map[string]*rateBucket
+	lastSweep time.Time
+}
+
+// rateBucket holds the tokens left for a key.
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+	rate   Rate
+}
+
+// Middleware returns a middleware enforcing the limiter.
+func (l *RateLimiter) Middleware() Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			keyFunc := l.Key
+			if keyFunc == nil {
+				keyFunc = KeyByIP
+			}
+			key := keyFunc(r)
+			if key == "" {
+				next(w, r)
+				return
+			}
+			rate := l.Rate
+			if l.Overrides != nil {
+				if override, ok, err := l.Overrides.Rate(r.Context(), key); err == nil && ok {
+					rate = override
+				}
+			}
+			if wait := l.take(key, rate, Now(r)); wait > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				Error(w, r, NewStatusError(http.StatusTooManyRequests, "rate limit exceeded"))
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+// take spends a token of key's bucket at now. It returns zero if the
+// request is allowed, or how long until a token is available.
+func (l *RateLimiter) take(key string, rate Rate, now time.Time) time.Duration {
+	if rate.Requests <= 0 || rate.Per <= 0 {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	capacity := float64(rate.Requests)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &rateBucket{tokens: capacity, last: now, rate: rate}
+		l.buckets[key] = b
+	}
+	b.rate = rate // the key may have changed plans
+	interval := rate.Per / time.Duration(rate.Requests)
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += float64(elapsed) / float64(interval)
+		b.last = now
+	}
+	b.tokens = min(b.tokens, capacity)
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) * float64(interval))
+	}
+	b.tokens--
+	return 0
+}
+
+// sweep drops buckets that have refilled completely, at most once a minute,
+// so keys seen once do not accumulate.
+func (l *RateLimiter) sweep(now time.Time) {
+	if l.buckets == nil {
+		l.buckets = make(map[string]*rateBucket)
+	}
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= b.rate.Per {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package groute
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type failingRateStore struct{}
+
+func (failingRateStore) Rate(ctx context.Context, key string) (Rate, bool, error) {
+	return Rate{}, false, errors.New("store down")
+}
+
+func TestRateLimiter(t *testing.T) {
+	clock := newFakeClock()
+	limiter := &RateLimiter{
+		Key:  KeyByPathValue("tenant"),
+		Rate: Rate{Requests: 2, Per: time.Minute},
+		Overrides: RateMap{
+			"pro":        {Requests: 4, Per: time.Minute},
+			"enterprise": {},
+		},
+	}
+	g := NewRouter()
+	g.SetClock(clock)
+	g.Use(limiter.Middleware())
+	g.Get("/t/{tenant}/orders", func(w http.ResponseWriter, r *http.Request) {})
+	g.Get("/public", func(w http.ResponseWriter, r *http.Request) {})
+
+	allowed := func(path string, n int) int {
+		var ok int
+		for range n {
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+			if w.Code == http.StatusOK {
+				ok++
+			}
+		}
+		return ok
+	}
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/t/free/orders", 2},
+		{"/t/other/orders", 2},
+		{"/t/pro/orders", 4},
+		{"/t/enterprise/orders", 10},
+		{"/public", 10},
+	}
+	for _, tt := range tests {
+		if got := allowed(tt.path, 10); got != tt.want {
+			t.Errorf("%s: %d of 10 allowed, want %d", tt.path, got, tt.want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/t/free/orders", nil))
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "30" {
+		t.Errorf("throttled: %d, Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	// Tokens refill over time.
+	clock.Advance(30 * time.Second)
+	if got := allowed("/t/free/orders", 10); got != 1 {
+		t.Errorf("after 30s: %d allowed, want 1", got)
+	}
+	clock.Advance(time.Hour)
+	if got := allowed("/t/free/orders", 10); got != 2 {
+		t.Errorf("after an hour: %d allowed, want 2", got)
+	}
+}
+
+func TestRateLimiterStoreError(t *testing.T) {
+	limiter := &RateLimiter{
+		Rate:      Rate{Requests: 1, Per: time.Second},
+		Overrides: failingRateStore{},
+	}
+	g := NewRouter()
+	g.Use(limiter.Middleware())
+	g.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	var codes []int
+	for range 2 {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		g.ServeHTTP(w, req)
+		codes = append(codes, w.Code)
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusTooManyRequests {
+		t.Errorf("codes = %v, want the default rate by IP", codes)
+	}
+}
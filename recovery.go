@@ -0,0 +1,119 @@
+package groute
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+// maxPanicFrames bounds the number of stack frames kept in a PanicReport.
+const maxPanicFrames = 32
+
+// PanicReport describes a panic recovered while serving a request.
+type PanicReport struct {
+	Value     any
+	RequestID string
+	Method    string
+	Path      string
+	Pattern   string
+	Params    map[string]string
+	// Stack is the panicking goroutine's stack, trimmed of runtime frames
+	// and limited to the innermost frames.
+	Stack string
+}
+
+// PanicReporter receives panic reports, e.g. to forward them to Sentry or
+// another error tracker.
+type PanicReporter interface {
+	ReportPanic(r *http.Request, report PanicReport)
+}
+
+// PanicReporterFunc adapts a function to the PanicReporter interface.
+type PanicReporterFunc func(r *http.Request, report PanicReport)
+
+// ReportPanic implements PanicReporter.
+func (f PanicReporterFunc) ReportPanic(r *http.Request, report PanicReport) {
+	f(r, report)
+}
+
+// Recovery returns a middleware that recovers from panics in later
+// middlewares and the handler.
+//
+// Each panic is logged as a structured record carrying the request ID (see
+// RequestID), matched pattern, path parameters and a trimmed stack, passed
+// to the optional reporters and the router's ErrorReporter, and answered with
+// 500 by the route's error handler unless a response was already started.
+// If logger is nil, slog.Default() is used. http.ErrAbortHandler is
+// re-panicked so net/http can abort the connection.
+func Recovery(logger *slog.Logger, reporters ...PanicReporter) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			rw := WrapResponseWriter(w)
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+
+				report := PanicReport{
+					Value:     v,
+					RequestID: GetRequestID(r),
+					Method:    r.Method,
+					Path:      r.URL.Path,
+					Pattern:   r.Pattern,
+					Params:    Params(r),
+					Stack:     panicStack(),
+				}
+				logger.LogAttrs(r.Context(), slog.LevelError, "panic recovered",
+					slog.String("request_id", report.RequestID),
+					slog.String("method", report.Method),
+					slog.String("path", report.Path),
+					slog.String("pattern", report.Pattern),
+					slog.Any("params", report.Params),
+					slog.String("panic", fmt.Sprint(v)),
+					slog.String("stack", report.Stack),
+				)
+				for _, reporter := range reporters {
+					reporter.ReportPanic(r, report)
+				}
+
+				err := fmt.Errorf("groute: panic: %v", v)
+				reportError(r, err, &report)
+				if !rw.Written() {
+					errorHandlerFor(r)(rw, r, err)
+				}
+			}()
+			next(rw, r)
+		}
+	}
+}
+
+// panicStack formats the stack of the panicking goroutine, skipping runtime
+// frames (including the panic machinery) and this package's recovery frames.
+func panicStack() string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var b strings.Builder
+	count := 0
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+			count++
+		}
+		if !more || count == maxPanicFrames {
+			break
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,87 @@
+package groute
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecovery(t *testing.T) {
+	var buf bytes.Buffer
+	var reported PanicReport
+	reporter := PanicReporterFunc(func(r *http.Request, report PanicReport) {
+		reported = report
+	})
+
+	g := NewRouter()
+	g.Use(RequestID(), Recovery(newTestLogger(&buf), reporter))
+	g.Get("/user/{id}", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest("GET", "/user/42", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", w.Code)
+	}
+	if reported.Value != "boom" {
+		t.Errorf("expected panic value 'boom', got %v", reported.Value)
+	}
+	if reported.RequestID != "req-1" {
+		t.Errorf("expected request ID 'req-1', got %q", reported.RequestID)
+	}
+	if reported.Pattern != "GET /user/{id}" {
+		t.Errorf("expected pattern 'GET /user/{id}', got %q", reported.Pattern)
+	}
+	if reported.Params["id"] != "42" {
+		t.Errorf("expected param id=42, got %v", reported.Params)
+	}
+	if !strings.Contains(reported.Stack, "TestRecovery") {
+		t.Errorf("expected stack to contain the panicking handler, got:\n%s", reported.Stack)
+	}
+	if strings.Contains(reported.Stack, "runtime.gopanic") {
+		t.Error("expected runtime frames to be trimmed from the stack")
+	}
+
+	record := decodeLogRecord(t, &buf)
+	if record["msg"] != "panic recovered" || record["request_id"] != "req-1" {
+		t.Errorf("unexpected log record: %v", record)
+	}
+}
+
+func TestRecoveryAfterWrite(t *testing.T) {
+	var buf bytes.Buffer
+	g := NewRouter()
+	g.Use(Recovery(newTestLogger(&buf)))
+	g.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("late")
+	})
+
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != http.StatusAccepted {
+		t.Errorf("expected already written status 202 to be kept, got %d", w.Code)
+	}
+}
+
+func TestRecoveryAbortHandler(t *testing.T) {
+	g := NewRouter()
+	g.Use(Recovery(newTestLogger(&bytes.Buffer{})))
+	g.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("expected http.ErrAbortHandler to be re-panicked, got %v", v)
+		}
+	}()
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
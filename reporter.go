@@ -0,0 +1,80 @@
+package groute
+
+import (
+	"maps"
+	"net/http"
+)
+
+// ErrorEvent describes a server error reported to an ErrorReporter.
+type ErrorEvent struct {
+	Err       error
+	Status    int
+	RequestID string
+	Method    string
+	Path      string
+	Pattern   string
+	Params    map[string]string
+	// Owner is the team owning the route, set with Route.Owner.
+	Owner string
+	// Attributes are the route's attributes, set with Route.Attribute.
+	Attributes map[string]string
+	// Panic is set when the error comes from a recovered panic.
+	Panic *PanicReport
+}
+
+// ErrorReporter receives server errors (5xx) passed to Error and panics
+// recovered by Recovery, so APM and error tracking tools can integrate
+// without custom middleware in every application.
+// The request is passed along so reporters can use its context.
+type ErrorReporter interface {
+	ReportError(r *http.Request, event ErrorEvent)
+}
+
+// ErrorReporterFunc adapts a function to the ErrorReporter interface.
+type ErrorReporterFunc func(r *http.Request, event ErrorEvent)
+
+// ReportError implements ErrorReporter.
+func (f ErrorReporterFunc) ReportError(r *http.Request, event ErrorEvent) {
+	f(r, event)
+}
+
+// SetErrorReporter sets the reporter for server errors of routes registered
+// on this router and its groups. A group may set its own reporter to
+// override the one inherited from its parent.
+func (g *Router) SetErrorReporter(reporter ErrorReporter) {
+	g.errorReporter = reporter
+}
+
+// lookupErrorReporter returns the closest reporter set on g or its parents.
+func (g *Router) lookupErrorReporter() ErrorReporter {
+	for r := g; r != nil; r = r.parent {
+		if r.errorReporter != nil {
+			return r.errorReporter
+		}
+	}
+	return nil
+}
+
+// reportError passes err to the error reporter of the route matched for r.
+func reportError(r *http.Request, err error, panicReport *PanicReport) {
+	route := CurrentRoute(r)
+	if route == nil || route.group == nil {
+		return
+	}
+	reporter := route.group.lookupErrorReporter()
+	if reporter == nil {
+		return
+	}
+	reporter.ReportError(r, ErrorEvent{
+		Err:        err,
+		Status:     ErrorStatus(err),
+		RequestID:  GetRequestID(r),
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Pattern:    r.Pattern,
+		Params:     Params(r),
+		Owner:      route.RouteOwner(),
+		Attributes: maps.Clone(route.attributes()),
+		Panic:      panicReport,
+	})
+}
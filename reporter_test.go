@@ -0,0 +1,91 @@
+package groute
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorReporter(t *testing.T) {
+	var events []ErrorEvent
+	g := NewRouter()
+	g.SetErrorReporter(ErrorReporterFunc(func(r *http.Request, event ErrorEvent) {
+		events = append(events, event)
+	}))
+	g.Use(RequestID(), Recovery(newTestLogger(&bytes.Buffer{})))
+
+	api := g.Group("/api")
+	api.Get("/fail/{id}", func(w http.ResponseWriter, r *http.Request) {
+		Error(w, r, errors.New("database down"))
+	})
+	api.Get("/bad", func(w http.ResponseWriter, r *http.Request) {
+		Error(w, r, NewStatusError(http.StatusBadRequest, "bad input"))
+	})
+	api.Get("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	tests := []struct {
+		path           string
+		expectedEvents int
+		expectPanic    bool
+	}{
+		{"/api/fail/7", 1, false},
+		{"/api/bad", 0, false},
+		{"/api/panic", 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			events = nil
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header.Set(RequestIDHeader, "req-9")
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, req)
+
+			if len(events) != tt.expectedEvents {
+				t.Fatalf("expected %d events, got %d", tt.expectedEvents, len(events))
+			}
+			if tt.expectedEvents == 0 {
+				return
+			}
+			event := events[0]
+			if event.Status != http.StatusInternalServerError {
+				t.Errorf("expected status 500, got %d", event.Status)
+			}
+			if event.RequestID != "req-9" {
+				t.Errorf("expected request ID 'req-9', got %q", event.RequestID)
+			}
+			if (event.Panic != nil) != tt.expectPanic {
+				t.Errorf("expected panic report %v, got %+v", tt.expectPanic, event.Panic)
+			}
+			if w.Code != http.StatusInternalServerError {
+				t.Errorf("expected status 500, got %d", w.Code)
+			}
+		})
+	}
+
+	events = nil
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/fail/7", nil))
+	if len(events) != 1 || events[0].Params["id"] != "7" {
+		t.Errorf("expected params in event, got %+v", events)
+	}
+}
+
+func TestErrorReporterGroupOverride(t *testing.T) {
+	var rootCalls, adminCalls int
+	g := NewRouter()
+	g.SetErrorReporter(ErrorReporterFunc(func(r *http.Request, event ErrorEvent) { rootCalls++ }))
+	admin := g.Group("/admin")
+	admin.SetErrorReporter(ErrorReporterFunc(func(r *http.Request, event ErrorEvent) { adminCalls++ }))
+	admin.Get("/x", func(w http.ResponseWriter, r *http.Request) {
+		Error(w, r, errors.New("fail"))
+	})
+
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/admin/x", nil))
+
+	if rootCalls != 0 || adminCalls != 1 {
+		t.Errorf("expected only the group reporter to be called, got root=%d admin=%d", rootCalls, adminCalls)
+	}
+}
@@ -0,0 +1,64 @@
+package groute
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+)
+
+// RequestIDHeader is the header used to receive and propagate request IDs.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the context key for the request ID.
+type requestIDKey struct{}
+
+// RequestID returns a middleware that assigns each request an ID.
+//
+// A well-formed X-Request-ID sent by the client or an upstream proxy is kept;
+// otherwise a random ID is generated from the router's random source. The ID
+// is echoed in the response header and available to handlers and other
+// middlewares through GetRequestID.
+func RequestID() Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if !validRequestID(id) {
+				id = newRequestID(Random(r))
+			}
+			w.Header().Set(RequestIDHeader, id)
+			ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+			next(w, r.WithContext(ctx))
+		}
+	}
+}
+
+// GetRequestID returns the ID assigned to r by the RequestID middleware,
+// or "" if none was assigned.
+func GetRequestID(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDKey{}).(string)
+	return id
+}
+
+// newRequestID returns a random 128-bit hex ID read from src.
+func newRequestID(src io.Reader) string {
+	var b [16]byte
+	_, _ = io.ReadFull(src, b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// validRequestID reports whether id is safe to reuse: short and limited to
+// characters that cannot break headers or log lines.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
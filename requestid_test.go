@@ -0,0 +1,56 @@
+package groute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name       string
+		incoming   string
+		expectKeep bool
+	}{
+		{"generated", "", false},
+		{"propagated", "abc-123", true},
+		{"rejected unsafe", "abc\r\nX-Evil: 1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewRouter()
+			g.Use(RequestID())
+			var captured string
+			g.Get("/", func(w http.ResponseWriter, r *http.Request) {
+				captured = GetRequestID(r)
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.incoming != "" {
+				req.Header[http.CanonicalHeaderKey(RequestIDHeader)] = []string{tt.incoming}
+			}
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, req)
+
+			if captured == "" {
+				t.Fatal("expected a request ID")
+			}
+			if tt.expectKeep && captured != tt.incoming {
+				t.Errorf("expected incoming ID %q to be kept, got %q", tt.incoming, captured)
+			}
+			if !tt.expectKeep && captured == tt.incoming {
+				t.Errorf("expected a generated ID, got %q", captured)
+			}
+			if got := w.Header().Get(RequestIDHeader); got != captured {
+				t.Errorf("expected response header %q, got %q", captured, got)
+			}
+		})
+	}
+}
+
+func TestGetRequestIDMissing(t *testing.T) {
+	if id := GetRequestID(httptest.NewRequest("GET", "/", nil)); id != "" {
+		t.Errorf("expected empty ID, got %q", id)
+	}
+}
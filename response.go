@@ -0,0 +1,93 @@
+package groute
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// ResponseWriter is an http.ResponseWriter that records the status code and
+// size of the response, for use by logging and metrics middlewares.
+type ResponseWriter interface {
+	http.ResponseWriter
+	http.Flusher
+	// Status returns the status code written, or 200 if the handler wrote a
+	// body without calling WriteHeader. It returns 0 if nothing was written.
+	Status() int
+	// Size returns the number of body bytes written.
+	Size() int64
+	// Written reports whether the response header has been written.
+	Written() bool
+	// Unwrap returns the underlying http.ResponseWriter.
+	Unwrap() http.ResponseWriter
+}
+
+// WrapResponseWriter returns w as a ResponseWriter, wrapping it if needed.
+// Wrapping an already wrapped writer returns it unchanged, so several
+// middlewares can share the same recorded status and size.
+func WrapResponseWriter(w http.ResponseWriter) ResponseWriter {
+	if rw, ok := w.(ResponseWriter); ok {
+		return rw
+	}
+	return &responseWriter{ResponseWriter: w}
+}
+
+// responseWriter is the default ResponseWriter implementation.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *responseWriter) WriteHeader(code int) {
+	// Informational responses other than 101 are followed by the real status.
+	if w.status == 0 && (code >= 200 || code == http.StatusSwitchingProtocols) {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write implements http.ResponseWriter.
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher.
+func (w *responseWriter) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker for websocket upgrades.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *responseWriter) Status() int                 { return w.status }
+func (w *responseWriter) Size() int64                 { return w.size }
+func (w *responseWriter) Written() bool               { return w.status != 0 }
+func (w *responseWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// ResponseStatus returns the status to account for a completed request.
+//
+// Requests whose client disconnected before the handler finished are
+// reported as StatusClientClosedRequest (nginx-style 499), whatever the
+// handler tried to write, since the response was never delivered. This keeps
+// client aborts out of server error rates.
+func ResponseStatus(w ResponseWriter, r *http.Request) int {
+	if Disconnected(r) != nil {
+		return StatusClientClosedRequest
+	}
+	if w.Status() == 0 {
+		return http.StatusOK
+	}
+	return w.Status()
+}
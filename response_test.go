@@ -0,0 +1,76 @@
+package groute
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseWriterRecords(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        http.HandlerFunc
+		expectedStatus int
+		expectedSize   int64
+	}{
+		{
+			name:           "explicit status",
+			handler:        func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusCreated) },
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "implicit status",
+			handler:        func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte("hello")) },
+			expectedStatus: http.StatusOK,
+			expectedSize:   5,
+		},
+		{
+			name: "informational status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusEarlyHints)
+				w.WriteHeader(http.StatusAccepted)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := WrapResponseWriter(httptest.NewRecorder())
+			tt.handler(rw, httptest.NewRequest("GET", "/", nil))
+
+			if rw.Status() != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rw.Status())
+			}
+			if rw.Size() != tt.expectedSize {
+				t.Errorf("expected size %d, got %d", tt.expectedSize, rw.Size())
+			}
+			if !rw.Written() {
+				t.Error("expected response to be written")
+			}
+		})
+	}
+}
+
+func TestWrapResponseWriterReuses(t *testing.T) {
+	rw := WrapResponseWriter(httptest.NewRecorder())
+	if WrapResponseWriter(rw) != rw {
+		t.Error("expected wrapping a ResponseWriter to return it unchanged")
+	}
+}
+
+func TestResponseStatus(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	rw := WrapResponseWriter(httptest.NewRecorder())
+	if status := ResponseStatus(rw, req); status != http.StatusOK {
+		t.Errorf("expected status 200 for empty response, got %d", status)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rw.WriteHeader(http.StatusInternalServerError)
+	if status := ResponseStatus(rw, req.WithContext(ctx)); status != StatusClientClosedRequest {
+		t.Errorf("expected status 499 after client disconnect, got %d", status)
+	}
+}
@@ -0,0 +1,146 @@
+package groute
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// RewriteRule rewrites the path and/or host of a request before routing.
+type RewriteRule struct {
+	// Host restricts the rule to requests for this host (case-insensitive,
+	// port ignored). Empty matches any host.
+	Host string
+	// Match is matched against the request path. A nil Match matches any path.
+	Match *regexp.Regexp
+	// Replace is the new path when Match is set. It may reference capture
+	// groups as in regexp.Regexp.Expand, e.g. "/v2/${1}". Empty keeps the path.
+	Replace string
+	// NewHost, if set, replaces the request host.
+	NewHost string
+}
+
+// RewritePath returns a rule rewriting paths matching the regular expression
+// pattern to replace. It panics if pattern does not compile.
+//
+//	groute.RewritePath(`^/blog/(\d+)$`, "/posts/${1}")
+func RewritePath(pattern, replace string) RewriteRule {
+	return RewriteRule{Match: regexp.MustCompile(pattern), Replace: replace}
+}
+
+// RewritePrefix returns a rule replacing the path prefix oldPrefix with
+// newPrefix, matching whole segments only: "/old" matches "/old" and
+// "/old/x" but not "/older".
+func RewritePrefix(oldPrefix, newPrefix string) RewriteRule {
+	oldPrefix = strings.TrimRight(oldPrefix, "/")
+	newPrefix = strings.TrimRight(newPrefix, "/")
+	return RewriteRule{
+		Match:   regexp.MustCompile("^" + regexp.QuoteMeta(oldPrefix) + "(/.*)?$"),
+		Replace: strings.ReplaceAll(newPrefix, "$", "$$") + "${1}",
+	}
+}
+
+// RewriteHost returns a rule replacing the host from with to, e.g. for
+// serving a legacy domain from the same routes.
+func RewriteHost(from, to string) RewriteRule {
+	return RewriteRule{Host: from, NewHost: to}
+}
+
+// Rewrite adds rules applied to every request before it is matched against
+// routes, so rewritten requests reach the route for their new path and host.
+// Rules are tried in order and the first matching rule wins. It is a
+// shorthand for g.Pre(Rewrites(rules...)) and, like Pre, applies to the
+// whole router even when called on a group.
+//
+// The original request URI is kept in r.RequestURI and the original path is
+// available through OriginalPath.
+func (g *Router) Rewrite(rules ...RewriteRule) {
+	g.Pre(Rewrites(rules...))
+}
+
+// Rewrites returns a pre-routing middleware applying the first matching rule.
+func Rewrites(rules ...RewriteRule) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			rewritten := applyRewrites(rules, r)
+			if rewritten != r {
+				countFunnel(r, funnelRewrite)
+			}
+			next(w, rewritten)
+		}
+	}
+}
+
+// matches reports whether the rule applies to r.
+func (rule *RewriteRule) matches(r *http.Request) bool {
+	if rule.Host != "" && !strings.EqualFold(hostWithoutPort(r.Host), rule.Host) {
+		return false
+	}
+	return rule.Match == nil || rule.Match.MatchString(r.URL.Path)
+}
+
+// apply rewrites a copy of r according to the rule.
+func (rule *RewriteRule) apply(r *http.Request) *http.Request {
+	r = shallowCopyRequest(r)
+	if rule.Match != nil && rule.Replace != "" {
+		path := r.URL.Path
+		var dst []byte
+		for _, submatches := range rule.Match.FindAllStringSubmatchIndex(path, 1) {
+			dst = rule.Match.ExpandString(dst, rule.Replace, path, submatches)
+		}
+		newPath := string(dst)
+		if !strings.HasPrefix(newPath, "/") {
+			newPath = "/" + newPath
+		}
+		r.URL.Path = newPath
+		r.URL.RawPath = ""
+	}
+	if rule.NewHost != "" {
+		r.Host = rule.NewHost
+		r.URL.Host = rule.NewHost
+	}
+	return r
+}
+
+// originalPathKey is the context key for the path before rewriting.
+type originalPathKey struct{}
+
+// OriginalPath returns the request path before any rewrite rule or prefix
+// stripping was applied.
+func OriginalPath(r *http.Request) string {
+	if path, ok := r.Context().Value(originalPathKey{}).(string); ok {
+		return path
+	}
+	return r.URL.Path
+}
+
+// applyRewrites applies the first matching rule to r.
+func applyRewrites(rules []RewriteRule, r *http.Request) *http.Request {
+	for i := range rules {
+		if rules[i].matches(r) {
+			original := OriginalPath(r)
+			r = rules[i].apply(r)
+			return r.WithContext(context.WithValue(r.Context(), originalPathKey{}, original))
+		}
+	}
+	return r
+}
+
+// shallowCopyRequest returns a copy of r with its own URL, safe to modify
+// the URL and host of without affecting r.
+func shallowCopyRequest(r *http.Request) *http.Request {
+	r2 := new(http.Request)
+	*r2 = *r
+	u := *r.URL
+	r2.URL = &u
+	return r2
+}
+
+// hostWithoutPort strips an optional port from host.
+func hostWithoutPort(host string) string {
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && !strings.Contains(host[i:], "]") {
+		return host[:i]
+	}
+	return host
+}
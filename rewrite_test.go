@@ -0,0 +1,83 @@
+package groute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRewrite(t *testing.T) {
+	g := NewRouter()
+	var capturedID, capturedOriginal, capturedHost string
+
+	g.Rewrite(
+		RewritePath(`^/blog/(\d+)$`, "/posts/${1}"),
+		RewritePrefix("/legacy", "/api/v2"),
+		RewriteHost("old.example.com", "example.com"),
+	)
+	g.Get("/posts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		capturedID = r.PathValue("id")
+		capturedOriginal = OriginalPath(r)
+	})
+	api := g.Group("/api/v2")
+	api.Get("/users", func(w http.ResponseWriter, r *http.Request) {
+		capturedOriginal = OriginalPath(r)
+	})
+	g.Get("/home", func(w http.ResponseWriter, r *http.Request) {
+		capturedHost = r.Host
+	})
+
+	tests := []struct {
+		name             string
+		host             string
+		path             string
+		expectedStatus   int
+		expectedOriginal string
+	}{
+		{"regex rewrite", "", "/blog/42", http.StatusOK, "/blog/42"},
+		{"prefix rewrite", "", "/legacy/users", http.StatusOK, "/legacy/users"},
+		{"prefix matches whole segments", "", "/legacyx/users", http.StatusNotFound, ""},
+		{"host rewrite", "old.example.com:8080", "/home", http.StatusOK, ""},
+		{"no rule", "", "/posts/7", http.StatusOK, "/posts/7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capturedOriginal = ""
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if capturedOriginal != tt.expectedOriginal {
+				t.Errorf("expected original path %q, got %q", tt.expectedOriginal, capturedOriginal)
+			}
+		})
+	}
+
+	if capturedID != "7" {
+		t.Errorf("expected id '7', got %q", capturedID)
+	}
+	if capturedHost != "example.com" {
+		t.Errorf("expected rewritten host, got %q", capturedHost)
+	}
+}
+
+func TestRewriteOnGroupAppliesToRouter(t *testing.T) {
+	g := NewRouter()
+	called := false
+	g.Group("/admin").Rewrite(RewritePrefix("/old", "/new"))
+	g.Get("/new", func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/old", nil))
+
+	if !called {
+		t.Error("expected rewrite added on a group to apply to the whole router")
+	}
+}
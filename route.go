@@ -0,0 +1,246 @@
+package groute
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Route is a registered route.
+//
+// Route methods configure per-route options and can be chained:
+//
+//	r.Get("/healthz", health).Meta("tier", "internal")
+//
+// Concurrency model: a route's options live in an immutable snapshot held by
+// an atomic pointer. Requests read the current snapshot without locking;
+// configuration methods copy the snapshot, modify the copy and publish it
+// atomically, so options may be changed while the router is serving.
+// Concurrent writers are serialized by a mutex that requests never touch.
+type Route struct {
+	pattern string
+	method  string
+	path    string
+	group   *Router
+	name    string
+	// mounted is the router served by a route created with Mount.
+	mounted *Router
+	// spa is set for routes created with SPA, whose responses may be
+	// soft 404s.
+	spa bool
+
+	mu   sync.Mutex // serializes writers of info
+	info atomic.Pointer[routeInfo]
+	hits atomic.Uint64
+}
+
+// routeInfo is an immutable snapshot of a route's options.
+// It must never be modified after it has been published.
+type routeInfo struct {
+	meta              map[string]any
+	disabled          bool
+	accepts           []string
+	slo               *SLO
+	logLevel          *slog.Level
+	noLog             bool
+	noCompress        bool
+	profile           bool
+	sizeLimit         *SizeLimit
+	examples          map[string]string
+	allowUnsafeParams bool
+	headerPolicy      *HeaderPolicy
+	schedule          *routeSchedule
+	conditions        []Predicate
+	noIndex           bool
+	breaker           *circuitBreaker
+	soft404Header     bool
+}
+
+// routeKey is the context key for the matched *Route.
+type routeKey struct{}
+
+// newRoute creates a route for a full pattern such as "GET /users/{id}".
+func newRoute(pattern string) *Route {
+	route := &Route{pattern: pattern, path: pattern}
+	if method, path, ok := strings.Cut(pattern, " "); ok {
+		route.method = method
+		route.path = path
+	}
+	route.info.Store(&routeInfo{})
+	return route
+}
+
+// Pattern returns the full pattern the route was registered with,
+// including the method and group prefix.
+func (rt *Route) Pattern() string {
+	if rt.group == nil || rt.group.mountPath() == "" {
+		return rt.pattern
+	}
+	if rt.method == "" {
+		return rt.Path()
+	}
+	return rt.method + " " + rt.Path()
+}
+
+// Method returns the route's HTTP method, or "" if it matches any method.
+func (rt *Route) Method() string {
+	return rt.method
+}
+
+// Path returns the path part of the route's pattern.
+func (rt *Route) Path() string {
+	if rt.group != nil && strings.HasPrefix(rt.path, "/") {
+		return rt.group.mountPath() + rt.path
+	}
+	return rt.path
+}
+
+// Meta attaches a metadata value to the route.
+func (rt *Route) Meta(key string, value any) *Route {
+	rt.update(func(info *routeInfo) {
+		meta := make(map[string]any, len(info.meta)+1)
+		for k, v := range info.meta {
+			meta[k] = v
+		}
+		meta[key] = value
+		info.meta = meta
+	})
+	return rt
+}
+
+// Value returns the metadata value stored under key.
+// It is safe to call on a nil Route.
+func (rt *Route) Value(key string) (any, bool) {
+	if rt == nil {
+		return nil, false
+	}
+	v, ok := rt.info.Load().meta[key]
+	return v, ok
+}
+
+// SetEnabled toggles the route at runtime. Requests to a disabled route
+// receive 404 Not Found.
+func (rt *Route) SetEnabled(enabled bool) *Route {
+	rt.update(func(info *routeInfo) {
+		info.disabled = !enabled
+	})
+	return rt
+}
+
+// Enabled reports whether the route is serving requests.
+func (rt *Route) Enabled() bool {
+	return !rt.info.Load().disabled
+}
+
+// Hits returns the number of requests dispatched to the route.
+func (rt *Route) Hits() uint64 {
+	return rt.hits.Load()
+}
+
+// update publishes a modified copy of the route's options.
+// The map fields of the copy are shared with the previous snapshot;
+// fn must replace rather than mutate them.
+func (rt *Route) update(fn func(info *routeInfo)) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	info := *rt.info.Load()
+	fn(&info)
+	rt.info.Store(&info)
+}
+
+// CurrentRoute returns the route matched for r, or nil if r was not
+// dispatched by a Router.
+func CurrentRoute(r *http.Request) *Route {
+	route, _ := r.Context().Value(routeKey{}).(*Route)
+	return route
+}
+
+// routeHandler dispatches a matched request to the route's handler chain.
+type routeHandler struct {
+	route *Route
+	next  http.Handler
+	// alternatives are the other routes registered with the same pattern.
+	alternatives atomic.Pointer[[]*routeHandler]
+}
+
+// ServeHTTP implements http.Handler interface.
+func (h *routeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w = routeWriter(w)
+	selected := h.selectRoute(r)
+	if selected == nil {
+		h.notFound(w, r)
+		return
+	}
+	h = selected
+	info := h.route.info.Load()
+	if info.disabled {
+		h.notFound(w, r)
+		return
+	}
+	if s := info.schedule; s != nil && !s.activeAt(h.route.now()) {
+		if s.placeholder == nil {
+			h.notFound(w, r)
+			return
+		}
+		s.placeholder(w, r.WithContext(context.WithValue(r.Context(), routeKey{}, h.route)))
+		return
+	}
+	h.route.hits.Add(1)
+	Timing(r).markMatched()
+	if h.route.mounted != nil || h.route.group.root().mountedOn != nil {
+		r = h.trackMount(r)
+	}
+	if h.route.spa {
+		r = r.WithContext(context.WithValue(r.Context(), soft404Key{}, &soft404Mark{}))
+	}
+	ctx := context.WithValue(r.Context(), routeKey{}, h.route)
+	r = r.WithContext(ctx)
+	if info.noIndex {
+		w.Header().Set("X-Robots-Tag", "noindex")
+	}
+	if len(info.accepts) > 0 && !acceptsContentType(info.accepts, r) {
+		rejectContentType(w, r, info.accepts)
+		return
+	}
+	if cb := info.breaker; cb != nil {
+		cb.serve(w, r, h.next, h.route.now)
+		return
+	}
+	h.next.ServeHTTP(w, r)
+}
+
+// notFound answers a request the route does not serve with 404 Not Found,
+// through the error handler of the route's group.
+func (h *routeHandler) notFound(w http.ResponseWriter, r *http.Request) {
+	h.route.group.lookupErrorHandler()(w, r, NewStatusError(http.StatusNotFound, "404 page not found"))
+}
+
+// routeTable records the routes registered on a router and its groups.
+type routeTable struct {
+	mu     sync.Mutex
+	routes []*Route
+	names  map[string]*Route
+	groups []*Router
+	lints  []string
+	// handlers maps full patterns to the handler registered with the mux.
+	handlers map[string]*routeHandler
+
+	// errorHandlers is set once any error handler was set, making unmatched
+	// requests go through them.
+	errorHandlers atomic.Bool
+}
+
+func (t *routeTable) add(route *Route) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.routes = append(t.routes, route)
+}
+
+func (t *routeTable) list() []*Route {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*Route(nil), t.routes...)
+}
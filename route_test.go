@@ -0,0 +1,142 @@
+package groute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestRouteAccessors(t *testing.T) {
+	g := NewRouter()
+	api := g.Group("/api")
+
+	route := api.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	if route.Pattern() != "GET /api/users/{id}" {
+		t.Errorf("expected pattern 'GET /api/users/{id}', got %q", route.Pattern())
+	}
+	if route.Method() != "GET" {
+		t.Errorf("expected method GET, got %q", route.Method())
+	}
+	if route.Path() != "/api/users/{id}" {
+		t.Errorf("expected path '/api/users/{id}', got %q", route.Path())
+	}
+
+	anyRoute := g.HandleFunc("/any", func(w http.ResponseWriter, r *http.Request) {})
+	if anyRoute.Method() != "" {
+		t.Errorf("expected empty method, got %q", anyRoute.Method())
+	}
+
+	routes := g.Routes()
+	if len(routes) != 2 || routes[0] != route || routes[1] != anyRoute {
+		t.Errorf("expected routes in registration order, got %v", routes)
+	}
+}
+
+func TestCurrentRouteAndMeta(t *testing.T) {
+	g := NewRouter()
+	var captured *Route
+	var tier any
+
+	g.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			// Middlewares see the matched route.
+			tier, _ = CurrentRoute(r).Value("tier")
+			next(w, r)
+		}
+	})
+	route := g.Get("/users", func(w http.ResponseWriter, r *http.Request) {
+		captured = CurrentRoute(r)
+		w.WriteHeader(http.StatusOK)
+	}).Meta("tier", "public")
+
+	req := httptest.NewRequest("GET", "/users", nil)
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+
+	if captured != route {
+		t.Error("expected CurrentRoute to return the matched route")
+	}
+	if tier != "public" {
+		t.Errorf("expected metadata 'public', got %v", tier)
+	}
+	if route.Hits() != 1 {
+		t.Errorf("expected 1 hit, got %d", route.Hits())
+	}
+
+	if CurrentRoute(httptest.NewRequest("GET", "/", nil)) != nil {
+		t.Error("expected nil route for undispatched request")
+	}
+	if _, ok := (*Route)(nil).Value("tier"); ok {
+		t.Error("expected no value on nil route")
+	}
+}
+
+func TestRouteSetEnabled(t *testing.T) {
+	g := NewRouter()
+	route := g.Get("/feature", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	route.SetEnabled(false)
+	req := httptest.NewRequest("GET", "/feature", nil)
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for disabled route, got %d", w.Code)
+	}
+
+	route.SetEnabled(true)
+	w = httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200 for enabled route, got %d", w.Code)
+	}
+}
+
+// TestRouteConcurrentUpdates is meant to be run with -race.
+func TestRouteConcurrentUpdates(t *testing.T) {
+	g := NewRouter()
+	route := g.Get("/users", func(w http.ResponseWriter, r *http.Request) {
+		CurrentRoute(r).Value("version")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				route.Meta("version", i*100+j)
+				route.SetEnabled(true)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				req := httptest.NewRequest("GET", "/users", nil)
+				g.ServeHTTP(httptest.NewRecorder(), req)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if route.Hits() != 400 {
+		t.Errorf("expected 400 hits, got %d", route.Hits())
+	}
+	if _, ok := route.Value("version"); !ok {
+		t.Error("expected metadata to be set")
+	}
+}
+
+func BenchmarkRouteValue(b *testing.B) {
+	g := NewRouter()
+	route := g.Get("/users", func(w http.ResponseWriter, r *http.Request) {}).Meta("tier", "public")
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			route.Value("tier")
+		}
+	})
+}
@@ -1,8 +1,13 @@
 package groute
 
 import (
+	"context"
+	"io"
+	"maps"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync/atomic"
 )
 
 // Router represents a route router with shared middleware and prefix.
@@ -10,93 +15,258 @@ type Router struct {
 	prefix      string
 	middlewares []Middleware
 	mux         *http.ServeMux
+	routes      *routeTable
+	parent      *Router
+
+	errorHandler  ErrorHandler
+	errorReporter ErrorReporter
+	pre           []Middleware
+	preHandler    http.HandlerFunc
+
+	trustForwarded bool
+	baseURL        *url.URL
+	serverTiming   bool
+	disableTrace   bool
+	clock          Clock
+	codec          Codec
+	random         io.Reader
+	funnel         funnel
+	draining       context.Context
+	drain          context.CancelFunc
+	streams        atomic.Int64
+	// mountedOn is the router a root router was mounted on with Mount,
+	// at mountPrefix.
+	mountedOn   *Router
+	mountPrefix string
+	// inherited is the number of middlewares copied from the parent.
+	inherited int
+	// conditions are given to the routes registered on g; see When.
+	conditions []Predicate
 }
 
-// NewRouter creates a new router.
-func NewRouter() *Router {
-	return &Router{
+// NewRouter creates a new router configured by opts.
+func NewRouter(opts ...Option) *Router {
+	g := &Router{
 		mux:         http.NewServeMux(),
 		middlewares: make([]Middleware, 0),
+		routes:      &routeTable{},
+	}
+	g.draining, g.drain = context.WithCancel(context.Background())
+	for _, opt := range opts {
+		opt(g)
 	}
+	return g
 }
 
 // Use adds middleware to the router.
 // Middleware will be applied in the order they are added.
+// It only applies to routes and groups created on g afterwards;
+// Lint reports calls that come too late for some of them.
 func (g *Router) Use(middlewares ...Middleware) {
+	g.routes.checkUse(g)
 	g.middlewares = append(g.middlewares, middlewares...)
 }
 
 // Get registers a GET route.
-func (g *Router) Get(pattern string, handler http.HandlerFunc) {
-	g.HandleFunc("GET "+pattern, handler)
+func (g *Router) Get(pattern string, handler http.HandlerFunc) *Route {
+	return g.HandleFunc("GET "+pattern, handler)
 }
 
 // Post registers a POST route.
-func (g *Router) Post(pattern string, handler http.HandlerFunc) {
-	g.HandleFunc("POST "+pattern, handler)
+func (g *Router) Post(pattern string, handler http.HandlerFunc) *Route {
+	return g.HandleFunc("POST "+pattern, handler)
 }
 
 // Put registers a PUT route.
-func (g *Router) Put(pattern string, handler http.HandlerFunc) {
-	g.HandleFunc("PUT "+pattern, handler)
+func (g *Router) Put(pattern string, handler http.HandlerFunc) *Route {
+	return g.HandleFunc("PUT "+pattern, handler)
 }
 
 // Delete registers a DELETE route.
-func (g *Router) Delete(pattern string, handler http.HandlerFunc) {
-	g.HandleFunc("DELETE "+pattern, handler)
+func (g *Router) Delete(pattern string, handler http.HandlerFunc) *Route {
+	return g.HandleFunc("DELETE "+pattern, handler)
 }
 
 // Patch registers a PATCH route.
-func (g *Router) Patch(pattern string, handler http.HandlerFunc) {
-	g.HandleFunc("PATCH "+pattern, handler)
+func (g *Router) Patch(pattern string, handler http.HandlerFunc) *Route {
+	return g.HandleFunc("PATCH "+pattern, handler)
 }
 
 // Head registers a HEAD route.
-func (g *Router) Head(pattern string, handler http.HandlerFunc) {
-	g.HandleFunc("HEAD "+pattern, handler)
+func (g *Router) Head(pattern string, handler http.HandlerFunc) *Route {
+	return g.HandleFunc("HEAD "+pattern, handler)
 }
 
 // Options registers an OPTIONS route.
-func (g *Router) Options(pattern string, handler http.HandlerFunc) {
-	g.HandleFunc("OPTIONS "+pattern, handler)
+func (g *Router) Options(pattern string, handler http.HandlerFunc) *Route {
+	return g.HandleFunc("OPTIONS "+pattern, handler)
 }
 
 // Connect registers a CONNECT route.
-func (g *Router) Connect(pattern string, handler http.HandlerFunc) {
-	g.HandleFunc("CONNECT "+pattern, handler)
+func (g *Router) Connect(pattern string, handler http.HandlerFunc) *Route {
+	return g.HandleFunc("CONNECT "+pattern, handler)
 }
 
 // Trace registers a TRACE route.
-func (g *Router) Trace(pattern string, handler http.HandlerFunc) {
-	g.HandleFunc("TRACE "+pattern, handler)
+func (g *Router) Trace(pattern string, handler http.HandlerFunc) *Route {
+	return g.HandleFunc("TRACE "+pattern, handler)
 }
 
 // Handle registers a route with any HTTP method.
-func (g *Router) Handle(pattern string, handler http.Handler) {
+// The returned Route can be used to attach per-route options.
+// Handle panics if pattern uses syntax the ServeMux engine does not
+// support, such as {id:[0-9]+} constraints or {page?} optional parameters.
+func (g *Router) Handle(pattern string, handler http.Handler) *Route {
 	fullPattern := joinPath(g.prefix, pattern)
+	if err := checkPattern(fullPattern); err != nil {
+		panic(err)
+	}
+	route := newRoute(fullPattern)
+	route.group = g
+	if len(g.conditions) > 0 {
+		route.info.Store(&routeInfo{conditions: g.conditions})
+	}
 	// Apply middlewares to handler
-	wrappedHandler := g.applyMiddlewares(handler)
-	g.mux.Handle(fullPattern, wrappedHandler)
+	wrappedHandler := g.applyMiddlewares(timedHandler(handler))
+	g.routes.register(g.mux, fullPattern, &routeHandler{route: route, next: wrappedHandler})
+	g.routes.add(route)
+	return route
 }
 
 // HandleFunc registers a route handler function.
-func (g *Router) HandleFunc(pattern string, handler http.HandlerFunc) {
-	g.Handle(pattern, http.HandlerFunc(handler))
+func (g *Router) HandleFunc(pattern string, handler http.HandlerFunc) *Route {
+	return g.Handle(pattern, http.HandlerFunc(handler))
+}
+
+// Routes returns all routes registered on the router and its groups,
+// in registration order. The routes of a router mounted with Mount are
+// listed in place of the mount.
+func (g *Router) Routes() []*Route {
+	var routes []*Route
+	for _, route := range g.routes.list() {
+		if route.mounted != nil {
+			routes = append(routes, route.mounted.Routes()...)
+			continue
+		}
+		routes = append(routes, route)
+	}
+	return routes
 }
 
 // ServeHTTP implements http.Handler interface.
 func (g *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	g.mux.ServeHTTP(w, r)
+	root := g.root()
+	if root.disableTrace && r.Method == http.MethodTrace {
+		root.rejectTrace(w, r)
+		return
+	}
+	h := root.serveMux
+	if root.preHandler != nil {
+		h = root.serveFunnel
+	}
+	if root.serverTiming {
+		serveWithTiming(w, r, h, root.Clock())
+		return
+	}
+	h(w, r)
+}
+
+// serveMux dispatches r to its route. Once error handlers are set, requests
+// no route matches are answered through them rather than with the mux's
+// plain-text 404 and 405 responses.
+func (g *Router) serveMux(w http.ResponseWriter, r *http.Request) {
+	if g.preHandler != nil {
+		markRouted(r)
+	}
+	// Unmatched requests go through the error handlers if there are any,
+	// or to include the request ID in the response.
+	if !g.routes.errorHandlers.Load() && GetRequestID(r) == "" {
+		g.mux.ServeHTTP(w, r)
+		return
+	}
+	// Matching once, the mux either calls a route, which takes w back from
+	// uw, or answers with its own 404 or 405 response, captured in uw.
+	uw := &unmatchedWriter{w: w}
+	g.mux.ServeHTTP(uw, r)
+	if uw.matched {
+		return
+	}
+	var err error
+	switch uw.status {
+	case http.StatusNotFound:
+		err = NewStatusError(http.StatusNotFound, "404 page not found")
+	case http.StatusMethodNotAllowed:
+		w.Header().Set("Allow", uw.Header().Get("Allow"))
+		err = NewStatusError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+	default:
+		// Redirects to the canonical path pass through.
+		maps.Copy(w.Header(), uw.Header())
+		w.WriteHeader(uw.status)
+		_, _ = w.Write(uw.body)
+		return
+	}
+	g.errorHandlerForPath(r.URL.Path)(w, r, err)
+}
+
+// unmatchedWriter is the http.ResponseWriter serveMux passes to the mux. A
+// route unwraps it before doing anything else; otherwise the response the
+// mux writes for an unmatched request is recorded instead of sent.
+type unmatchedWriter struct {
+	w       http.ResponseWriter
+	matched bool
+	header  http.Header
+	status  int
+	body    []byte
+}
+
+// routeWriter returns the writer a route should use for w, marking an
+// unmatchedWriter as matched.
+func routeWriter(w http.ResponseWriter) http.ResponseWriter {
+	if uw, ok := w.(*unmatchedWriter); ok {
+		uw.matched = true
+		return uw.w
+	}
+	return w
+}
+
+func (uw *unmatchedWriter) Header() http.Header {
+	if uw.header == nil {
+		uw.header = make(http.Header)
+	}
+	return uw.header
+}
+
+func (uw *unmatchedWriter) WriteHeader(code int) {
+	if uw.status == 0 {
+		uw.status = code
+	}
+}
+
+func (uw *unmatchedWriter) Write(b []byte) (int, error) {
+	uw.WriteHeader(http.StatusOK)
+	uw.body = append(uw.body, b...)
+	return len(b), nil
 }
 
 // Group creates a sub-group with additional prefix and middleware.
 func (g *Router) Group(prefix string) *Router {
+	subGroup := g.newGroup(prefix)
+	g.routes.addGroup(subGroup)
+	return subGroup
+}
+
+// newGroup creates a sub-group without recording it in the route table.
+func (g *Router) newGroup(prefix string) *Router {
 	subGroupPrefix := strings.TrimRight(g.prefix, "/") + "/" + strings.TrimLeft(prefix, "/")
 
 	subGroup := &Router{
 		prefix:      subGroupPrefix,
 		mux:         g.mux,
 		middlewares: make([]Middleware, len(g.middlewares)),
+		routes:      g.routes,
+		parent:      g,
+		inherited:   len(g.middlewares),
+		conditions:  g.conditions,
 	}
 	// Copy parent middlewares
 	copy(subGroup.middlewares, g.middlewares)
@@ -105,6 +275,10 @@ func (g *Router) Group(prefix string) *Router {
 }
 
 // applyMiddlewares applies all middlewares to a handler.
+// The chain is composed once, when the route is registered, so dispatch is a
+// series of direct calls without allocations. An interceptor-style loop over
+// the middleware slice at dispatch time needs per-request state for its next
+// function and is several times slower; see BenchmarkMiddlewareChain.
 func (g *Router) applyMiddlewares(handler http.Handler) http.Handler {
 	// Apply middlewares in reverse order (first added = outermost)
 	// This ensures the first middleware added executes first.
@@ -1,6 +1,7 @@
 package groute
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -29,7 +30,7 @@ func TestHTTPMethods(t *testing.T) {
 	tests := []struct {
 		name           string
 		method         string
-		registerMethod func(*Router, string, http.HandlerFunc)
+		registerMethod func(*Router, string, http.HandlerFunc) *Route
 		expectedStatus int
 	}{
 		{"GET", "GET", (*Router).Get, http.StatusOK},
@@ -923,3 +924,92 @@ func TestRoutePriorityComplex(t *testing.T) {
 		})
 	}
 }
+
+// BenchmarkMiddlewareChain compares the chains built by applyMiddlewares,
+// composed once at registration, with an interceptor-style loop over the
+// middleware slice at dispatch time.
+func BenchmarkMiddlewareChain(b *testing.B) {
+	type interceptor func(w http.ResponseWriter, r *http.Request, next func())
+	loop := func(ics []interceptor, h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			var i int
+			var next func()
+			next = func() {
+				if i == len(ics) {
+					h(w, r)
+					return
+				}
+				i++
+				ics[i-1](w, r, next)
+			}
+			next()
+		}
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	for _, depth := range []int{1, 10, 20} {
+		g := NewRouter()
+		ics := make([]interceptor, depth)
+		for i := range depth {
+			g.Use(func(next http.HandlerFunc) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) { next(w, r) }
+			})
+			ics[i] = func(w http.ResponseWriter, r *http.Request, next func()) { next() }
+		}
+		chains := []struct {
+			name string
+			h    http.Handler
+		}{
+			{"nested", g.applyMiddlewares(http.HandlerFunc(handler))},
+			{"loop", loop(ics, handler)},
+		}
+		w, r := httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil)
+		for _, c := range chains {
+			b.Run(fmt.Sprintf("%s/depth=%d", c.name, depth), func(b *testing.B) {
+				b.ReportAllocs()
+				for b.Loop() {
+					c.h.ServeHTTP(w, r)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkServeHTTP(b *testing.B) {
+	for _, depth := range []int{0, 10, 20} {
+		g := NewRouter()
+		for range depth {
+			g.Use(func(next http.HandlerFunc) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) { next(w, r) }
+			})
+		}
+		g.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+		w, r := httptest.NewRecorder(), httptest.NewRequest("GET", "/users/1", nil)
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				g.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+func BenchmarkServeHTTPRequestID(b *testing.B) {
+	for _, requestID := range []bool{false, true} {
+		g := NewRouter()
+		if requestID {
+			g.Pre(RequestID())
+		}
+		g.SetErrorHandler(ProblemErrorHandler)
+		for i := range 100 {
+			g.Get(fmt.Sprintf("/tenant%d/users/{id}", i), func(w http.ResponseWriter, r *http.Request) {})
+		}
+		w, r := httptest.NewRecorder(), httptest.NewRequest("GET", "/tenant99/users/1", nil)
+		b.Run(fmt.Sprintf("requestID=%t", requestID), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				g.ServeHTTP(w, r)
+			}
+		})
+	}
+}
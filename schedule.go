@@ -0,0 +1,215 @@
+package groute
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ActiveBetween makes the route serve requests only from start until end,
+// e.g. for a launch embargo. A zero start or end leaves that side open.
+// Outside the window requests get 404 Not Found, or the WhenInactive
+// handler. Times are read from the router's Clock.
+func (rt *Route) ActiveBetween(start, end time.Time) *Route {
+	rt.updateSchedule(func(s *routeSchedule) {
+		s.start, s.end = start, end
+	})
+	return rt
+}
+
+// ActiveDuring makes the route serve requests only during the minutes
+// matching the cron expression spec, in the time zone of the router's
+// Clock. For example "* 9-17 * * 1-5" keeps the route up on weekdays from
+// 9:00 to 17:59.
+//
+// Specs have the five standard fields: minute, hour, day of month, month
+// and day of week (0-7, both 0 and 7 being Sunday). Fields accept "*",
+// numbers, ranges "a-b", steps "*/n" or "a-b/n" and comma-separated lists.
+// ActiveDuring panics if spec is invalid.
+func (rt *Route) ActiveDuring(spec string) *Route {
+	schedule := mustParseCron(spec)
+	rt.updateSchedule(func(s *routeSchedule) {
+		s.activeDuring = schedule
+	})
+	return rt
+}
+
+// InactiveDuring takes the route down during the minutes matching the cron
+// expression spec, e.g. "0-29 2 * * 0" for a maintenance window from 2:00
+// to 2:29 every Sunday. See ActiveDuring for the syntax. It panics if spec
+// is invalid.
+func (rt *Route) InactiveDuring(spec string) *Route {
+	schedule := mustParseCron(spec)
+	rt.updateSchedule(func(s *routeSchedule) {
+		s.inactiveDuring = schedule
+	})
+	return rt
+}
+
+// WhenInactive sets the handler answering requests while the route is
+// outside its schedule, such as a "coming soon" or maintenance page.
+// Route middlewares do not run for it.
+func (rt *Route) WhenInactive(handler http.HandlerFunc) *Route {
+	rt.updateSchedule(func(s *routeSchedule) {
+		s.placeholder = handler
+	})
+	return rt
+}
+
+// ActiveAt reports whether the route's schedule lets it serve requests at t.
+// Disabling a route with SetEnabled is independent of its schedule.
+func (rt *Route) ActiveAt(t time.Time) bool {
+	s := rt.info.Load().schedule
+	return s == nil || s.activeAt(t)
+}
+
+// updateSchedule publishes a modified copy of the route's schedule.
+func (rt *Route) updateSchedule(fn func(s *routeSchedule)) {
+	rt.update(func(info *routeInfo) {
+		var s routeSchedule
+		if info.schedule != nil {
+			s = *info.schedule
+		}
+		fn(&s)
+		info.schedule = &s
+	})
+}
+
+// now returns the current time according to the route's router.
+func (rt *Route) now() time.Time {
+	if rt != nil && rt.group != nil {
+		return rt.group.Clock().Now()
+	}
+	return time.Now()
+}
+
+// routeSchedule is the availability of a route.
+type routeSchedule struct {
+	start, end     time.Time
+	activeDuring   *cronSchedule
+	inactiveDuring *cronSchedule
+	placeholder    http.HandlerFunc
+}
+
+// activeAt reports whether the route is available at t.
+func (s *routeSchedule) activeAt(t time.Time) bool {
+	switch {
+	case !s.start.IsZero() && t.Before(s.start):
+		return false
+	case !s.end.IsZero() && !t.Before(s.end):
+		return false
+	case s.activeDuring != nil && !s.activeDuring.matches(t):
+		return false
+	case s.inactiveDuring != nil && s.inactiveDuring.matches(t):
+		return false
+	}
+	return true
+}
+
+// cronSchedule is a parsed cron expression, one bit per allowed value.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny record day fields covering their full range, such
+	// as "*", "*/1" or "?": as in cron, a day matches either field when
+	// both are restricted.
+	domAny, dowAny bool
+}
+
+// matches reports whether the minute of t matches the schedule.
+func (c *cronSchedule) matches(t time.Time) bool {
+	if c.minute&(1<<t.Minute()) == 0 || c.hour&(1<<t.Hour()) == 0 || c.month&(1<<int(t.Month())) == 0 {
+		return false
+	}
+	dom := c.dom&(1<<t.Day()) != 0
+	dow := c.dow&(1<<int(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// mustParseCron parses spec, panicking if it is invalid.
+func mustParseCron(spec string) *cronSchedule {
+	c, err := parseCron(spec)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// parseCron parses a five-field cron expression. The day fields also
+// accept "?" for any day.
+func parseCron(spec string) (*cronSchedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("groute: cron spec %q must have 5 fields", spec)
+	}
+	var c cronSchedule
+	var err error
+	bounds := []struct {
+		bits     *uint64
+		min, max int
+	}{
+		{&c.minute, 0, 59},
+		{&c.hour, 0, 23},
+		{&c.dom, 1, 31},
+		{&c.month, 1, 12},
+		{&c.dow, 0, 7},
+	}
+	for i, b := range bounds {
+		field := fields[i]
+		if field == "?" && (i == 2 || i == 4) {
+			field = "*"
+		}
+		if *b.bits, err = parseCronField(field, b.min, b.max); err != nil {
+			return nil, fmt.Errorf("groute: cron spec %q: %w", spec, err)
+		}
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1 // 7 is Sunday too
+	}
+	const allDays, allWeekdays = 1<<32 - 2, 1<<7 - 1 // 1-31, 0-6
+	c.domAny = c.dom == allDays
+	c.dowAny = c.dow&allWeekdays == allWeekdays
+	return &c, nil
+}
+
+// parseCronField parses one field into a bit set of values in [min, max].
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for part := range strings.SplitSeq(field, ",") {
+		rng, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepText); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", part)
+			}
+		}
+		lo, hi := min, max
+		if rng != "*" {
+			loText, hiText, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(loText); err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(hiText); err != nil {
+					return 0, fmt.Errorf("invalid value %q", part)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("value %q out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
@@ -0,0 +1,115 @@
+package groute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestActiveBetween(t *testing.T) {
+	clock := newFakeClock() // 2024-01-01 00:00 UTC
+	g := NewRouter()
+	g.SetClock(clock)
+	launch := g.Get("/launch", func(w http.ResponseWriter, r *http.Request) {}).
+		ActiveBetween(clock.Now().Add(time.Hour), clock.Now().Add(2*time.Hour))
+	g.Get("/promo", func(w http.ResponseWriter, r *http.Request) {}).
+		ActiveBetween(time.Time{}, clock.Now().Add(time.Hour)).
+		WhenInactive(func(w http.ResponseWriter, r *http.Request) {
+			if CurrentRoute(r) == nil {
+				t.Error("placeholder called without route")
+			}
+			http.Error(w, "promotion over", http.StatusGone)
+		})
+
+	status := func(path string) int {
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w.Code
+	}
+
+	if code := status("/launch"); code != http.StatusNotFound {
+		t.Errorf("before launch: status = %d, want 404", code)
+	}
+	if code := status("/promo"); code != http.StatusOK {
+		t.Errorf("during promo: status = %d, want 200", code)
+	}
+	clock.Advance(time.Hour)
+	if code := status("/launch"); code != http.StatusOK {
+		t.Errorf("at launch: status = %d, want 200", code)
+	}
+	if code := status("/promo"); code != http.StatusGone {
+		t.Errorf("after promo: status = %d, want 410", code)
+	}
+	clock.Advance(time.Hour)
+	if code := status("/launch"); code != http.StatusNotFound {
+		t.Errorf("after window: status = %d, want 404", code)
+	}
+	if launch.Hits() != 1 {
+		t.Errorf("hits = %d, want 1", launch.Hits())
+	}
+}
+
+func TestActiveDuring(t *testing.T) {
+	rt := NewRouter().Get("/", func(w http.ResponseWriter, r *http.Request) {}).
+		ActiveDuring("* 9-17 * * 1-5").
+		InactiveDuring("0-29 12 * * *")
+
+	tests := []struct {
+		time   string
+		active bool
+	}{
+		{"2024-01-01T09:00:00Z", true},  // Monday
+		{"2024-01-01T08:59:00Z", false}, // before hours
+		{"2024-01-05T17:59:00Z", true},  // Friday
+		{"2024-01-06T10:00:00Z", false}, // Saturday
+		{"2024-01-02T12:15:00Z", false}, // lunch maintenance
+		{"2024-01-02T12:30:00Z", true},
+	}
+	for _, tt := range tests {
+		at, _ := time.Parse(time.RFC3339, tt.time)
+		if got := rt.ActiveAt(at); got != tt.active {
+			t.Errorf("ActiveAt(%s) = %v, want %v", tt.time, got, tt.active)
+		}
+	}
+}
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		spec  string
+		time  string
+		match bool
+	}{
+		{"*/15 * * * *", "2024-01-01T10:30:00Z", true},
+		{"*/15 * * * *", "2024-01-01T10:31:00Z", false},
+		{"0 0 1 1 *", "2024-01-01T00:00:00Z", true},
+		{"5,10-12/2 * * * *", "2024-01-01T00:12:00Z", true},
+		{"5,10-12/2 * * * *", "2024-01-01T00:11:00Z", false},
+		{"* * * * 7", "2024-01-07T00:00:00Z", true},  // Sunday
+		{"* * 15 * 1", "2024-01-01T00:00:00Z", true}, // Monday, not the 15th
+		{"* * 15 * 1", "2024-01-15T00:00:00Z", true},
+		{"* * 15 * 1", "2024-01-16T00:00:00Z", false},
+		{"* * */1 * 1", "2024-01-16T00:00:00Z", false}, // a Tuesday
+		{"* * ? * 1", "2024-01-16T00:00:00Z", false},
+		{"* * ? * 1", "2024-01-15T00:00:00Z", true},
+		{"* * 15 * 0-7", "2024-01-16T00:00:00Z", false},
+		{"* * 15 * ?", "2024-01-15T00:00:00Z", true},
+	}
+	for _, tt := range tests {
+		c, err := parseCron(tt.spec)
+		if err != nil {
+			t.Errorf("parseCron(%q): %v", tt.spec, err)
+			continue
+		}
+		at, _ := time.Parse(time.RFC3339, tt.time)
+		if got := c.matches(at); got != tt.match {
+			t.Errorf("%q at %s = %v, want %v", tt.spec, tt.time, got, tt.match)
+		}
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "a * * * *", "5-1 * * * *", "* * * 13 *", "? * * * *"} {
+		if _, err := parseCron(spec); err == nil {
+			t.Errorf("parseCron(%q) should fail", spec)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package groute
+
+import (
+	"bufio"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+)
+
+// ErrResponseTooLarge is returned by Write once a response exceeds the
+// maximum size set with ResponseSizeLimit.
+var ErrResponseTooLarge = errors.New("groute: response too large")
+
+// SizeLimit bounds the size of response bodies.
+type SizeLimit struct {
+	// Soft is the size past which a warning is logged. Zero disables it.
+	Soft int64
+	// Max is the size responses are capped at. Zero means no cap.
+	Max int64
+	// Abort makes responses exceeding Max abort the connection instead of
+	// being truncated, so clients cannot mistake them for complete ones.
+	Abort bool
+}
+
+// SizeLimit overrides the limits of ResponseSizeLimit for the route.
+func (rt *Route) SizeLimit(limit SizeLimit) *Route {
+	rt.update(func(info *routeInfo) {
+		info.sizeLimit = &limit
+	})
+	return rt
+}
+
+// ResponseSizeLimit returns a middleware guarding against accidentally
+// unbounded responses, such as exports without pagination.
+//
+// Responses growing past the soft limit are logged once at warn level.
+// Responses that would exceed the maximum are cut off: if nothing has been
+// sent yet they are replaced with a 500 Internal Server Error through the
+// route's error handler, otherwise they are truncated at the maximum and
+// further writes fail with ErrResponseTooLarge, or the connection is
+// aborted if Abort is set. Either way the error is passed to the router's
+// ErrorReporter and logged.
+//
+// The response status is held back until the first body write so that the
+// limit can still be enforced with a proper status. Routes override the
+// defaults with Route.SizeLimit. If logger is nil, slog.Default() is used.
+func ResponseSizeLimit(defaults SizeLimit, logger *slog.Logger) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			limit := defaults
+			if route := CurrentRoute(r); route != nil {
+				if l := route.info.Load().sizeLimit; l != nil {
+					limit = *l
+				}
+			}
+			if limit.Soft <= 0 && limit.Max <= 0 {
+				next(w, r)
+				return
+			}
+			lw := &limitWriter{ResponseWriter: w, r: r, limit: limit, logger: logger}
+			next(lw, r)
+			lw.writePendingHeader()
+		}
+	}
+}
+
+// limitWriter enforces a SizeLimit on the response body.
+type limitWriter struct {
+	http.ResponseWriter
+	r      *http.Request
+	limit  SizeLimit
+	logger *slog.Logger
+
+	status   int // status held back until the first write
+	started  bool
+	size     int64
+	warned   bool
+	exceeded bool
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *limitWriter) WriteHeader(code int) {
+	switch {
+	case w.status != 0:
+		return
+	case code < 200 && code != http.StatusSwitchingProtocols:
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+	if code == http.StatusSwitchingProtocols {
+		w.writePendingHeader()
+	}
+}
+
+// writePendingHeader sends the status held back by WriteHeader, if any.
+func (w *limitWriter) writePendingHeader() {
+	if w.started || w.status == 0 {
+		return
+	}
+	w.started = true
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+// Write implements http.ResponseWriter.
+func (w *limitWriter) Write(b []byte) (int, error) {
+	if w.exceeded {
+		return 0, ErrResponseTooLarge
+	}
+	size := w.size + int64(len(b))
+	if w.limit.Max > 0 && size > w.limit.Max {
+		return w.exceed(b)
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.writePendingHeader()
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	if w.limit.Soft > 0 && w.size > w.limit.Soft && !w.warned {
+		w.warned = true
+		w.log(slog.LevelWarn, "response size above soft limit", w.limit.Soft)
+	}
+	return n, err
+}
+
+// exceed handles a write that would take the response past the maximum.
+func (w *limitWriter) exceed(b []byte) (int, error) {
+	w.exceeded = true
+	w.log(slog.LevelError, "response size limit exceeded", w.limit.Max)
+	if !w.started {
+		w.started = true
+		// The headers describe the response that was too large, not the
+		// error replacing it.
+		h := w.ResponseWriter.Header()
+		for _, key := range representationHeaders {
+			h.Del(key)
+		}
+		Error(w.ResponseWriter, w.r, &StatusError{Code: http.StatusInternalServerError, Err: ErrResponseTooLarge})
+		return 0, ErrResponseTooLarge
+	}
+	reportError(w.r, ErrResponseTooLarge, nil)
+	if w.limit.Abort {
+		panic(http.ErrAbortHandler)
+	}
+	n, _ := w.ResponseWriter.Write(b[:w.limit.Max-w.size])
+	w.size += int64(n)
+	return n, ErrResponseTooLarge
+}
+
+// representationHeaders describe a response body and are removed before
+// an error replaces it.
+var representationHeaders = []string{
+	"Content-Length", "Content-Type", "Content-Encoding", "Content-Disposition",
+	"Content-Range", "Content-Language", "ETag", "Last-Modified",
+}
+
+// log records a limit violation for the request.
+func (w *limitWriter) log(level slog.Level, msg string, limit int64) {
+	w.logger.LogAttrs(w.r.Context(), level, msg,
+		slog.String("method", w.r.Method),
+		slog.String("path", w.r.URL.Path),
+		slog.String("pattern", w.r.Pattern),
+		slog.Int64("limit", limit),
+	)
+}
+
+// Flush implements http.Flusher.
+func (w *limitWriter) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.writePendingHeader()
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker for websocket upgrades.
+func (w *limitWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.started = true
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap returns the underlying http.ResponseWriter.
+func (w *limitWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
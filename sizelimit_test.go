@@ -0,0 +1,123 @@
+package groute
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResponseSizeLimit(t *testing.T) {
+	var logs bytes.Buffer
+	var reported []error
+	g := NewRouter()
+	g.SetErrorReporter(ErrorReporterFunc(func(r *http.Request, event ErrorEvent) {
+		reported = append(reported, event.Err)
+	}))
+	g.Use(ResponseSizeLimit(SizeLimit{Soft: 4, Max: 8}, newTestLogger(&logs)))
+
+	writeChunks := func(chunks ...string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			for _, c := range chunks {
+				if _, err := w.Write([]byte(c)); err != nil {
+					if !errors.Is(err, ErrResponseTooLarge) {
+						t.Errorf("expected ErrResponseTooLarge, got %v", err)
+					}
+					return
+				}
+			}
+		}
+	}
+	g.Get("/small", writeChunks("abc"))
+	g.Get("/soft", writeChunks("abc", "def"))
+	g.Get("/huge", writeChunks("0123456789"))
+	g.Get("/truncated", writeChunks("abcdef", "ghijkl"))
+	g.Get("/override", writeChunks("0123456789")).SizeLimit(SizeLimit{Max: 16})
+
+	tests := []struct {
+		path     string
+		status   int
+		body     string
+		warning  bool
+		reported bool
+	}{
+		{"/small", http.StatusAccepted, "abc", false, false},
+		{"/soft", http.StatusAccepted, "abcdef", true, false},
+		{"/huge", http.StatusInternalServerError, "Internal Server Error\n", false, true},
+		{"/truncated", http.StatusAccepted, "abcdefgh", true, true},
+		{"/override", http.StatusAccepted, "0123456789", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			logs.Reset()
+			reported = nil
+			rec := httptest.NewRecorder()
+			g.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+
+			if rec.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, rec.Code)
+			}
+			if rec.Body.String() != tt.body {
+				t.Errorf("expected body %q, got %q", tt.body, rec.Body.String())
+			}
+			if got := strings.Contains(logs.String(), "soft limit"); got != tt.warning {
+				t.Errorf("expected soft limit warning %v, logs: %s", tt.warning, logs.String())
+			}
+			if got := len(reported) == 1 && errors.Is(reported[0], ErrResponseTooLarge); got != tt.reported {
+				t.Errorf("expected reported %v, got %v", tt.reported, reported)
+			}
+		})
+	}
+}
+
+func TestResponseSizeLimitDropsHandlerHeaders(t *testing.T) {
+	g := NewRouter()
+	g.SetErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		w.WriteHeader(ErrorStatus(err))
+		w.Write([]byte("too large"))
+	})
+	g.Use(ResponseSizeLimit(SizeLimit{Max: 8}, nil))
+	g.Get("/report.csv", func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Set("Content-Length", "10")
+		h.Set("Content-Type", "text/csv")
+		h.Set("Content-Disposition", `attachment; filename="report.csv"`)
+		h.Set("ETag", `"v1"`)
+		h.Set("X-Report", "1")
+		w.Write([]byte("0123456789"))
+	})
+
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest("GET", "/report.csv", nil))
+	if rec.Code != http.StatusInternalServerError || rec.Body.String() != "too large" {
+		t.Fatalf("got %d %q", rec.Code, rec.Body.String())
+	}
+	for _, key := range []string{"Content-Length", "Content-Type", "Content-Disposition", "ETag"} {
+		if v := rec.Header().Get(key); v != "" {
+			t.Errorf("%s = %q leaked onto the error response", key, v)
+		}
+	}
+	if rec.Header().Get("X-Report") != "1" {
+		t.Error("expected other headers to be kept")
+	}
+}
+
+func TestResponseSizeLimitAbort(t *testing.T) {
+	g := NewRouter()
+	g.Use(ResponseSizeLimit(SizeLimit{Max: 4, Abort: true}, newTestLogger(&bytes.Buffer{})))
+	g.Get("/export", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("abc"))
+		_, _ = w.Write([]byte("def"))
+		t.Error("expected the handler to be aborted")
+	})
+
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("expected http.ErrAbortHandler panic, got %v", v)
+		}
+	}()
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/export", nil))
+}
@@ -0,0 +1,193 @@
+package groute
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// timingKey is the context key for the request's *ServerTiming.
+type timingKey struct{}
+
+// SetServerTiming makes the router add a Server-Timing header to responses,
+// for inspection in browser developer tools. The router reports the time
+// spent matching the route ("match"), in middlewares before the handler
+// ("middleware") and in the handler until the response header was written
+// ("handler"); handlers can add their own metrics through Timing.
+//
+// The header exposes internal timings to clients, so it is off by default.
+// It applies to the whole router, even when set on a group, and should be
+// set before the router starts serving.
+func (g *Router) SetServerTiming(enabled bool) {
+	g.root().serverTiming = enabled
+}
+
+// ServerTiming collects the metrics of a request's Server-Timing header.
+// A nil *ServerTiming is valid and discards everything, so handlers can
+// record metrics whether or not the header is enabled.
+type ServerTiming struct {
+	mu           sync.Mutex
+	clock        Clock
+	start        time.Time
+	matched      time.Time
+	handlerStart time.Time
+	metrics      []timingMetric
+}
+
+// timingMetric is a handler-supplied Server-Timing entry.
+type timingMetric struct {
+	name string
+	dur  time.Duration
+}
+
+// Timing returns the Server-Timing collector of r, or nil if the header is
+// not enabled on the router:
+//
+//	start := time.Now()
+//	rows, err := db.Query(...)
+//	groute.Timing(r).Add("db", time.Since(start))
+func Timing(r *http.Request) *ServerTiming {
+	t, _ := r.Context().Value(timingKey{}).(*ServerTiming)
+	return t
+}
+
+// Add records a metric. The name must be an HTTP token, e.g. "db" or
+// "cache-miss"; metrics added after the response header was written are
+// not sent.
+func (t *ServerTiming) Add(name string, d time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.metrics = append(t.metrics, timingMetric{name: name, dur: d})
+}
+
+// Start starts timing a metric and returns a function recording it:
+//
+//	defer groute.Timing(r).Start("render")()
+func (t *ServerTiming) Start(name string) (stop func()) {
+	if t == nil {
+		return func() {}
+	}
+	start := t.clock.Now()
+	return func() { t.Add(name, t.clock.Now().Sub(start)) }
+}
+
+// markMatched records that a route was matched for the request.
+func (t *ServerTiming) markMatched() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.matched = t.clock.Now()
+}
+
+// markHandler records that the route's handler was called.
+func (t *ServerTiming) markHandler() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handlerStart = t.clock.Now()
+}
+
+// header formats the Server-Timing header value at the current time.
+func (t *ServerTiming) header() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.clock.Now()
+	var b strings.Builder
+	add := func(name string, d time.Duration) {
+		if b.Len() > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(name)
+		b.WriteString(";dur=")
+		b.WriteString(strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64))
+	}
+	if !t.matched.IsZero() {
+		add("match", t.matched.Sub(t.start))
+	}
+	if !t.handlerStart.IsZero() {
+		add("middleware", t.handlerStart.Sub(t.matched))
+		add("handler", now.Sub(t.handlerStart))
+	}
+	for _, m := range t.metrics {
+		add(m.name, m.dur)
+	}
+	return b.String()
+}
+
+// serveWithTiming serves r through next, adding the Server-Timing header.
+func serveWithTiming(w http.ResponseWriter, r *http.Request, next http.HandlerFunc, clock Clock) {
+	t := &ServerTiming{clock: clock, start: clock.Now()}
+	tw := &timingWriter{ResponseWriter: w, timing: t}
+	next(tw, r.WithContext(context.WithValue(r.Context(), timingKey{}, t)))
+	tw.writeTiming()
+}
+
+// timedHandler wraps a route's handler to mark when it starts.
+func timedHandler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Timing(r).markHandler()
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// timingWriter sets the Server-Timing header right before the response
+// header is written.
+type timingWriter struct {
+	http.ResponseWriter
+	timing  *ServerTiming
+	written bool
+}
+
+// writeTiming sets the header once, unless the response is already underway.
+func (w *timingWriter) writeTiming() {
+	if w.written {
+		return
+	}
+	w.written = true
+	if v := w.timing.header(); v != "" {
+		w.Header().Set("Server-Timing", v)
+	}
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *timingWriter) WriteHeader(code int) {
+	if code >= 200 || code == http.StatusSwitchingProtocols {
+		w.writeTiming()
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write implements http.ResponseWriter.
+func (w *timingWriter) Write(b []byte) (int, error) {
+	w.writeTiming()
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher.
+func (w *timingWriter) Flush() {
+	w.writeTiming()
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker for websocket upgrades.
+func (w *timingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.written = true
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap returns the underlying http.ResponseWriter.
+func (w *timingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,63 @@
+package groute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestServerTiming(t *testing.T) {
+	g := NewRouter()
+	g.SetServerTiming(true)
+	g.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			Timing(r).Add("auth", 1500*time.Microsecond)
+			next(w, r)
+		}
+	})
+	api := g.Group("/api")
+	api.Get("/users", func(w http.ResponseWriter, r *http.Request) {
+		stop := Timing(r).Start("db")
+		stop()
+		_, _ = w.Write([]byte("ok"))
+	})
+	api.Get("/empty", func(w http.ResponseWriter, r *http.Request) {})
+
+	want := regexp.MustCompile(`^match;dur=\d+\.\d{3}, middleware;dur=\d+\.\d{3}, handler;dur=\d+\.\d{3}, auth;dur=1\.500, db;dur=\d+\.\d{3}$`)
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest("GET", "/api/users", nil))
+	if got := rec.Header().Get("Server-Timing"); !want.MatchString(got) {
+		t.Errorf("unexpected Server-Timing header %q", got)
+	}
+
+	rec = httptest.NewRecorder()
+	api.ServeHTTP(rec, httptest.NewRequest("GET", "/api/empty", nil))
+	if rec.Header().Get("Server-Timing") == "" {
+		t.Error("expected Server-Timing header when the handler writes nothing")
+	}
+
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest("GET", "/missing", nil))
+	if got := rec.Header().Get("Server-Timing"); got != "" {
+		t.Errorf("expected no Server-Timing header without a route, got %q", got)
+	}
+}
+
+func TestServerTimingDisabled(t *testing.T) {
+	g := NewRouter()
+	g.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		if Timing(r) != nil {
+			t.Error("expected nil Timing when disabled")
+		}
+		Timing(r).Add("db", time.Millisecond)
+		Timing(r).Start("render")()
+	})
+
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if got := rec.Header().Get("Server-Timing"); got != "" {
+		t.Errorf("expected no Server-Timing header, got %q", got)
+	}
+}
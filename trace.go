@@ -0,0 +1,47 @@
+package groute
+
+import (
+	"net/http"
+	"strings"
+)
+
+// DisableTrace makes the router answer every TRACE request with 405 Method
+// Not Allowed, as security baselines commonly require, even for routes
+// registered without a method or with Trace. The response never echoes
+// the request's headers or body. It applies to the whole router, even when
+// called on a group, and takes effect before pre-routing middlewares run.
+//
+// The response is written by the error handler for the request path, like
+// other 405 responses, with an Allow header listing the methods the path
+// does accept.
+func (g *Router) DisableTrace() {
+	g.root().disableTrace = true
+}
+
+// probeMethods are the methods checked for the Allow header of a rejected
+// TRACE request.
+var probeMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodConnect, http.MethodOptions,
+}
+
+// rejectTrace answers a TRACE request on a router with TRACE disabled.
+func (g *Router) rejectTrace(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Allow", strings.Join(g.allowedMethods(r), ", "))
+	err := NewStatusError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+	g.errorHandlerForPath(r.URL.Path)(w, r, err)
+}
+
+// allowedMethods returns the methods other than TRACE that a route of g
+// accepts for the path of r.
+func (g *Router) allowedMethods(r *http.Request) []string {
+	var methods []string
+	for _, method := range probeMethods {
+		probe := *r
+		probe.Method = method
+		if _, pattern := g.mux.Handler(&probe); pattern != "" {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
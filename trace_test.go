@@ -0,0 +1,62 @@
+package groute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDisableTrace(t *testing.T) {
+	echo := func(w http.ResponseWriter, r *http.Request) {
+		_ = r.Header.Write(w)
+	}
+	g := NewRouter()
+	g.Handle("/any", http.HandlerFunc(echo))
+	api := g.Group("/api")
+	api.Trace("/debug", echo)
+	api.Get("/items", echo)
+	api.Post("/items", echo)
+	api.SetErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		w.WriteHeader(ErrorStatus(err))
+		w.Write([]byte("api error"))
+	})
+	api.DisableTrace()
+
+	tests := []struct {
+		path  string
+		allow string
+		body  string
+	}{
+		{"/any", "GET, HEAD, POST, PUT, PATCH, DELETE, CONNECT, OPTIONS", "Method Not Allowed\n"},
+		{"/api/debug", "", "api error"},
+		{"/api/items", "GET, HEAD, POST", "api error"},
+		{"/missing", "", "Method Not Allowed\n"},
+	}
+	for _, tt := range tests {
+		path := tt.path
+		req := httptest.NewRequest("TRACE", path, strings.NewReader("secret body"))
+		req.Header.Set("Cookie", "session=secret")
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, req)
+
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s: expected status 405, got %d", path, w.Code)
+		}
+		if strings.Contains(w.Body.String(), "secret") {
+			t.Errorf("%s: expected request not to be reflected, got %q", path, w.Body.String())
+		}
+		if allow, ok := w.Header()["Allow"]; !ok || allow[0] != tt.allow {
+			t.Errorf("%s: Allow = %q, want %q", path, allow, tt.allow)
+		}
+		if w.Body.String() != tt.body {
+			t.Errorf("%s: body = %q, want %q", path, w.Body.String(), tt.body)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/any", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected other methods to pass, got %d", w.Code)
+	}
+}
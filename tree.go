@@ -0,0 +1,156 @@
+package groute
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// Tree writes the groups and routes of g as an indented tree, for docs and
+// code reviews:
+//
+//	/ [grouter.RequestID grouter.AccessLog]
+//	├── GET /healthz
+//	└── /api [main.auth]
+//	    ├── GET /users/{id} name=user where=header(X-Beta=1)
+//	    └── POST /users
+//
+// Each group lists the middlewares it added to those of its parent, and
+// routes are shown relative to their group with their name, conditions
+// and state. The routes of a router mounted with Mount appear below the
+// mount. Unlike Routes, which lists routes flat, the tree shows where
+// middlewares apply.
+func (g *Router) Tree(w io.Writer) error {
+	var b strings.Builder
+	prefix := g.prefix
+	if prefix == "" {
+		prefix = "/"
+	}
+	b.WriteString(prefix + middlewareNames(g.middlewares[g.inherited:]) + "\n")
+	g.writeTree(&b, "")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeTree writes the children of g, each line starting with indent.
+func (g *Router) writeTree(b *strings.Builder, indent string) {
+	t := g.routes
+	t.mu.Lock()
+	routes := append([]*Route(nil), t.routes...)
+	groups := append([]*Router(nil), t.groups...)
+	t.mu.Unlock()
+
+	type node struct {
+		label string
+		group *Router
+		route *Route
+	}
+	var children []node
+	for _, route := range routes {
+		if treeParent(route.group, groups) == g {
+			children = append(children, node{label: g.routeLabel(route), route: route})
+		}
+	}
+	for _, group := range groups {
+		if treeParent(group.parent, groups) == g {
+			label := relativePath(g, group.prefix)
+			children = append(children, node{label: label + middlewareNames(group.middlewares[group.inherited:]), group: group})
+		}
+	}
+
+	for i, child := range children {
+		branch, next := "├── ", "│   "
+		if i == len(children)-1 {
+			branch, next = "└── ", "    "
+		}
+		b.WriteString(indent + branch + child.label + "\n")
+		switch {
+		case child.group != nil:
+			child.group.writeTree(b, indent+next)
+		case child.route.mounted != nil:
+			child.route.mounted.writeTree(b, indent+next)
+		}
+	}
+}
+
+// treeParent returns the closest of group and its ancestors that is listed
+// in groups or is the root, skipping unrecorded groups.
+func treeParent(group *Router, groups []*Router) *Router {
+	for g := group; g != nil; g = g.parent {
+		if g.parent == nil {
+			return g
+		}
+		for _, recorded := range groups {
+			if recorded == g {
+				return g
+			}
+		}
+	}
+	return nil
+}
+
+// routeLabel describes route, registered on g or one of its unrecorded
+// groups, for Tree.
+func (g *Router) routeLabel(route *Route) string {
+	path := relativePath(g, route.path)
+	label := path
+	if route.method != "" {
+		label = route.method + " " + path
+	}
+	if name := route.RouteName(); name != "" {
+		label += " name=" + name
+	}
+	if conditions := route.Conditions(); len(conditions) > 0 {
+		label += " where=" + strings.Join(conditions, ",")
+	}
+	if !route.Enabled() {
+		label += " (disabled)"
+	}
+	if route.mounted != nil {
+		label += " (mount)" + middlewareNames(route.mounted.middlewares)
+	}
+	return label
+}
+
+// relativePath returns path relative to the prefix of g.
+func relativePath(g *Router, path string) string {
+	path = strings.TrimPrefix(path, strings.TrimRight(g.prefix, "/"))
+	return "/" + strings.TrimPrefix(path, "/")
+}
+
+// middlewareNames formats the function names of middlewares as
+// " [a b]", or "" if there are none.
+func middlewareNames(middlewares []Middleware) string {
+	if len(middlewares) == 0 {
+		return ""
+	}
+	names := make([]string, len(middlewares))
+	for i, mw := range middlewares {
+		names[i] = funcName(mw)
+	}
+	return " [" + strings.Join(names, " ") + "]"
+}
+
+// funcName returns the name of the function that created f, such as
+// "grouter.AccessLog" for the middleware returned by AccessLog.
+func funcName(f any) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer())
+	if fn == nil {
+		return fmt.Sprintf("%T", f)
+	}
+	name := fn.Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+	// Strip the suffixes of closures: "AccessLog.func1.1" -> "AccessLog".
+	for {
+		i := strings.LastIndex(name, ".")
+		if i < 0 {
+			return name
+		}
+		if suffix := name[i+1:]; !strings.HasPrefix(suffix, "func") && strings.Trim(suffix, "0123456789") != "" {
+			return name
+		}
+		name = name[:i]
+	}
+}
@@ -0,0 +1,68 @@
+package groute
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func auth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) { next(w, r) }
+}
+
+func TestTree(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	g := NewRouter()
+	g.Use(RequestID())
+	g.Get("/healthz", ok)
+	api := g.Group("/api")
+	api.Use(auth)
+	api.Get("/users/{id}", ok).Name("user").Where(Header("X-Beta", "1"))
+	api.Post("/users", ok).SetEnabled(false)
+	api.Group("/admin").Delete("/cache", ok)
+
+	sub := NewRouter()
+	sub.Use(auth)
+	sub.Get("/status", ok)
+	g.Mount("/legacy", sub)
+
+	var b strings.Builder
+	if err := g.Tree(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `/ [grouter.RequestID]
+├── GET /healthz
+├── /legacy/ (mount) [grouter.auth]
+│   └── GET /status
+└── /api [grouter.auth]
+    ├── GET /users/{id} name=user where=header(X-Beta=1)
+    ├── POST /users (disabled)
+    └── /admin
+        └── DELETE /cache
+`
+	if b.String() != want {
+		t.Errorf("Tree() =\n%s\nwant:\n%s", b.String(), want)
+	}
+
+	b.Reset()
+	api.Tree(&b)
+	if !strings.HasPrefix(b.String(), "/api [grouter.auth]\n├── GET /users/{id}") {
+		t.Errorf("group Tree() =\n%s", b.String())
+	}
+}
+
+func TestFuncName(t *testing.T) {
+	tests := []struct {
+		f    any
+		want string
+	}{
+		{auth, "grouter.auth"},
+		{AccessLog(nil), "grouter.AccessLog"},
+		{NewMetrics().Middleware(), "grouter.(*Metrics).Middleware"},
+	}
+	for _, tt := range tests {
+		if got := funcName(tt.f); got != tt.want {
+			t.Errorf("funcName() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package groute
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrRouteNotFound is returned when generating a URL for an unknown route name.
+var ErrRouteNotFound = errors.New("groute: route not found")
+
+// Name names the route for URL generation with Router.URL.
+// Names are unique per router; Name panics if name is already taken,
+// like registering a duplicate pattern does.
+func (rt *Route) Name(name string) *Route {
+	t := rt.group.routes
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if other, ok := t.names[name]; ok && other != rt {
+		panic(fmt.Sprintf("groute: route name %q already used by %q", name, other.pattern))
+	}
+	if t.names == nil {
+		t.names = make(map[string]*Route)
+	}
+	if rt.name != "" {
+		delete(t.names, rt.name)
+	}
+	rt.name = name
+	t.names[name] = rt
+	return rt
+}
+
+// RouteName returns the name set with Name, or "".
+func (rt *Route) RouteName() string {
+	rt.group.routes.mu.Lock()
+	defer rt.group.routes.mu.Unlock()
+	return rt.name
+}
+
+// RouteByName returns the route registered under name, or nil.
+func (g *Router) RouteByName(name string) *Route {
+	g.routes.mu.Lock()
+	defer g.routes.mu.Unlock()
+	return g.routes.names[name]
+}
+
+// URL returns the path of the named route with its parameters filled in.
+// Parameters are given as name/value pairs:
+//
+//	r.Get("/users/{id}", showUser).Name("user")
+//	path, err := r.URL("user", "id", "42") // "/users/42"
+//
+// Values are path-escaped; wildcard values such as {path...} keep their
+// slashes. The path includes the prefix of a router mounted with Mount; the
+// host of a host-qualified pattern is left out.
+func (g *Router) URL(name string, params ...string) (string, error) {
+	route := g.RouteByName(name)
+	if route == nil {
+		return "", fmt.Errorf("%w: %q", ErrRouteNotFound, name)
+	}
+	_, path := splitHostPath(route.Path())
+	return buildPath(path, params)
+}
+
+// splitHostPath splits a pattern path such as "example.com/x" into its
+// host and path.
+func splitHostPath(pattern string) (host, path string) {
+	if i := strings.IndexByte(pattern, '/'); i > 0 {
+		return pattern[:i], pattern[i:]
+	}
+	return "", pattern
+}
+
+// SetTrustForwardedHeaders makes URL generation honor X-Forwarded-Prefix,
+// X-Forwarded-Host and X-Forwarded-Proto. Enable it only when the router
+// runs behind a proxy that sets or strips these headers, since clients can
+// forge them. It applies to the whole router, even when set on a group.
+func (g *Router) SetTrustForwardedHeaders(trust bool) {
+	g.root().trustForwarded = trust
+}
+
+// URLFor returns the path of the named route as seen by the client of r:
+// it includes the X-Forwarded-Prefix of a trusted proxy and any prefix
+// stripped by StripPrefix before the request reached the router.
+func (g *Router) URLFor(r *http.Request, name string, params ...string) (string, error) {
+	path, err := g.URL(name, params...)
+	if err != nil {
+		return "", err
+	}
+	// The mount prefix, stripped on the way in, is already part of path.
+	base := strings.TrimSuffix(BasePath(r), g.mountPath())
+	return g.forwardedPrefix(r) + base + path, nil
+}
+
+// Redirect replies to r with a redirect to the named route, using the
+// client-visible path from URLFor.
+func (g *Router) Redirect(w http.ResponseWriter, r *http.Request, code int, name string, params ...string) error {
+	location, err := g.URLFor(r, name, params...)
+	if err != nil {
+		return err
+	}
+	location, err = safeLocation(location)
+	if err != nil {
+		return err
+	}
+	http.Redirect(w, r, location, code)
+	return nil
+}
+
+// SetBaseURL sets the scheme, host and optional path prefix used by
+// AbsoluteURL instead of deriving them from the request, e.g.
+// "https://example.com" for links in emails and webhook payloads.
+// It applies to the whole router, even when set on a group.
+func (g *Router) SetBaseURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("groute: base URL %q must be absolute", rawURL)
+	}
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawQuery, u.Fragment = "", ""
+	g.root().baseURL = u
+	return nil
+}
+
+// AbsoluteURL returns the absolute URL of the named route, for emails,
+// Location headers and webhook payloads.
+//
+// With a base URL set by SetBaseURL, it is used as is. Otherwise scheme and
+// host are derived from r, honoring X-Forwarded-Proto, X-Forwarded-Host and
+// X-Forwarded-Prefix when SetTrustForwardedHeaders is enabled.
+// r may be nil when a base URL is set.
+func (g *Router) AbsoluteURL(r *http.Request, name string, params ...string) (string, error) {
+	if base := g.root().baseURL; base != nil {
+		path, err := g.URL(name, params...)
+		if err != nil {
+			return "", err
+		}
+		return base.Scheme + "://" + base.Host + base.Path + path, nil
+	}
+	if r == nil {
+		return "", errors.New("groute: AbsoluteURL needs a request or a base URL")
+	}
+	path, err := g.URLFor(r, name, params...)
+	if err != nil {
+		return "", err
+	}
+	return g.requestScheme(r) + "://" + g.requestHost(r) + path, nil
+}
+
+// requestScheme returns the scheme the client used for r.
+func (g *Router) requestScheme(r *http.Request) string {
+	if g.root().trustForwarded {
+		if proto := strings.ToLower(firstHeaderValue(r.Header.Get("X-Forwarded-Proto"))); proto == "http" || proto == "https" {
+			return proto
+		}
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// requestHost returns the host the client used for r.
+func (g *Router) requestHost(r *http.Request) string {
+	if g.root().trustForwarded {
+		if host := firstHeaderValue(r.Header.Get("X-Forwarded-Host")); host != "" && validHost(host) {
+			return host
+		}
+	}
+	return r.Host
+}
+
+// forwardedPrefix returns the normalized X-Forwarded-Prefix of r if
+// forwarded headers are trusted, or "".
+func (g *Router) forwardedPrefix(r *http.Request) string {
+	if !g.root().trustForwarded {
+		return ""
+	}
+	prefix := strings.Trim(strings.TrimSpace(firstHeaderValue(r.Header.Get("X-Forwarded-Prefix"))), "/")
+	if prefix == "" {
+		return ""
+	}
+	return (&url.URL{Path: "/" + prefix}).EscapedPath()
+}
+
+// validHost reports whether host is a plausible host[:port] value, so a
+// forged header cannot smuggle a path, user info or control characters
+// into generated URLs.
+func validHost(host string) bool {
+	for i := 0; i < len(host); i++ {
+		c := host[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case strings.IndexByte(".-_:[]", c) >= 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// firstHeaderValue returns the first element of a comma-separated header.
+func firstHeaderValue(v string) string {
+	first, _, _ := strings.Cut(v, ",")
+	return strings.TrimSpace(first)
+}
+
+// buildPath fills the wildcards of a ServeMux path pattern from name/value pairs.
+func buildPath(pattern string, params []string) (string, error) {
+	if len(params)%2 != 0 {
+		return "", errors.New("groute: URL parameters must be name/value pairs")
+	}
+	values := make(map[string]string, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		values[params[i]] = params[i+1]
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			b.WriteString(pattern)
+			return b.String(), nil
+		}
+		end := strings.IndexByte(pattern[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("groute: malformed pattern %q", pattern)
+		}
+		b.WriteString(pattern[:start])
+		name := pattern[start+1 : start+end]
+		pattern = pattern[start+end+1:]
+
+		if name == "$" {
+			continue
+		}
+		wildcard := strings.HasSuffix(name, "...")
+		name = strings.TrimSuffix(name, "...")
+		value, ok := values[name]
+		if !ok {
+			return "", fmt.Errorf("groute: missing URL parameter %q", name)
+		}
+		if wildcard {
+			segments := strings.Split(value, "/")
+			for i, segment := range segments {
+				segments[i] = url.PathEscape(segment)
+			}
+			b.WriteString(strings.Join(segments, "/"))
+		} else {
+			b.WriteString(url.PathEscape(value))
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package groute
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestURL(t *testing.T) {
+	g := NewRouter()
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	api := g.Group("/api")
+	api.Get("/users/{id}", handler).Name("user")
+	api.Get("/users/{userId}/posts/{postId}", handler).Name("post")
+	g.Get("/files/{path...}", handler).Name("file")
+	g.Get("/{$}", handler).Name("home")
+
+	tests := []struct {
+		name     string
+		route    string
+		params   []string
+		expected string
+	}{
+		{"single param", "user", []string{"id", "42"}, "/api/users/42"},
+		{"multiple params", "post", []string{"userId", "1", "postId", "2"}, "/api/users/1/posts/2"},
+		{"escaped value", "user", []string{"id", "a b/c"}, "/api/users/a%20b%2Fc"},
+		{"wildcard keeps slashes", "file", []string{"path", "docs/a b.txt"}, "/files/docs/a%20b.txt"},
+		{"end marker", "home", nil, "/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := g.URL(tt.route, tt.params...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+
+	if _, err := g.URL("missing"); !errors.Is(err, ErrRouteNotFound) {
+		t.Errorf("expected ErrRouteNotFound, got %v", err)
+	}
+	if _, err := g.URL("user"); err == nil {
+		t.Error("expected error for missing parameter")
+	}
+	if _, err := g.URL("user", "id"); err == nil {
+		t.Error("expected error for odd parameter list")
+	}
+}
+
+func TestURLMountedAndHostPatterns(t *testing.T) {
+	g := NewRouter()
+	billing := NewRouter()
+	billing.Get("/invoices/{id}", func(w http.ResponseWriter, r *http.Request) {}).Name("invoice")
+	billing.Get("/pay", func(w http.ResponseWriter, r *http.Request) {
+		_ = billing.Redirect(w, r, http.StatusFound, "invoice", "id", "7")
+	})
+	g.Group("/v1").Mount("/billing", billing)
+
+	if got, err := billing.URL("invoice", "id", "7"); err != nil || got != "/v1/billing/invoices/7" {
+		t.Errorf("URL = %q, %v; want the mount prefix", got, err)
+	}
+	outer := StripPrefix("/app")(g.ServeHTTP)
+	w := httptest.NewRecorder()
+	outer(w, httptest.NewRequest("GET", "/app/v1/billing/pay", nil))
+	if location := w.Header().Get("Location"); location != "/app/v1/billing/invoices/7" {
+		t.Errorf("Location = %q, want /app/v1/billing/invoices/7", location)
+	}
+
+	// Handle joins patterns to the group prefix, so add a host-qualified
+	// route to the table directly.
+	route := newRoute("GET example.com/x/{id}")
+	route.group = g
+	g.routes.routes = append(g.routes.routes, route)
+	route.Name("host")
+	if got, err := g.URL("host", "id", "1"); err != nil || got != "/x/1" {
+		t.Errorf("URL = %q, %v; want /x/1", got, err)
+	}
+}
+
+func TestRouteName(t *testing.T) {
+	g := NewRouter()
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	route := g.Get("/a", handler).Name("a")
+
+	if route.RouteName() != "a" || g.RouteByName("a") != route {
+		t.Error("expected route to be registered under its name")
+	}
+	route.Name("renamed")
+	if g.RouteByName("a") != nil || g.RouteByName("renamed") != route {
+		t.Error("expected rename to replace the old name")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected duplicate route name to panic")
+		}
+	}()
+	g.Get("/b", handler).Name("renamed")
+}
+
+func TestURLForForwardedPrefix(t *testing.T) {
+	g := NewRouter()
+	g.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {}).Name("user")
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Forwarded-Prefix", "/service/")
+
+	path, _ := g.URLFor(req, "user", "id", "1")
+	if path != "/users/1" {
+		t.Errorf("expected untrusted prefix to be ignored, got %q", path)
+	}
+
+	g.Group("/api").SetTrustForwardedHeaders(true)
+	path, _ = g.URLFor(req, "user", "id", "1")
+	if path != "/service/users/1" {
+		t.Errorf("expected forwarded prefix, got %q", path)
+	}
+}
+
+func TestRedirectBehindStripPrefix(t *testing.T) {
+	g := NewRouter()
+	g.Get("/login", func(w http.ResponseWriter, r *http.Request) {}).Name("login")
+	g.Get("/private", func(w http.ResponseWriter, r *http.Request) {
+		if err := g.Redirect(w, r, http.StatusFound, "login"); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	// The router is mounted below /app by an outer handler.
+	outer := StripPrefix("/app")(g.ServeHTTP)
+	w := httptest.NewRecorder()
+	outer(w, httptest.NewRequest("GET", "/app/private", nil))
+
+	if w.Code != http.StatusFound {
+		t.Errorf("expected status 302, got %d", w.Code)
+	}
+	if location := w.Header().Get("Location"); location != "/app/login" {
+		t.Errorf("expected Location '/app/login', got %q", location)
+	}
+}
+
+func TestAbsoluteURL(t *testing.T) {
+	newRouter := func() *Router {
+		g := NewRouter()
+		g.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {}).Name("user")
+		return g
+	}
+
+	t.Run("from request", func(t *testing.T) {
+		g := newRouter()
+		req := httptest.NewRequest("GET", "http://api.example.com/", nil)
+		got, err := g.AbsoluteURL(req, "user", "id", "1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != "http://api.example.com/users/1" {
+			t.Errorf("unexpected URL %q", got)
+		}
+	})
+
+	t.Run("forwarded headers", func(t *testing.T) {
+		g := newRouter()
+		req := httptest.NewRequest("GET", "http://internal:8080/", nil)
+		req.Header.Set("X-Forwarded-Proto", "https")
+		req.Header.Set("X-Forwarded-Host", "example.com, proxy.local")
+		req.Header.Set("X-Forwarded-Prefix", "/svc")
+
+		got, _ := g.AbsoluteURL(req, "user", "id", "1")
+		if got != "http://internal:8080/users/1" {
+			t.Errorf("expected forwarded headers to be ignored when untrusted, got %q", got)
+		}
+
+		g.SetTrustForwardedHeaders(true)
+		got, _ = g.AbsoluteURL(req, "user", "id", "1")
+		if got != "https://example.com/svc/users/1" {
+			t.Errorf("expected forwarded URL, got %q", got)
+		}
+
+		req.Header.Set("X-Forwarded-Host", "evil.com/phish?")
+		req.Header.Set("X-Forwarded-Prefix", "/a b?#")
+		got, _ = g.AbsoluteURL(req, "user", "id", "1")
+		if got != "https://internal:8080/a%20b%3F%23/users/1" {
+			t.Errorf("expected forged forwarded values to be rejected or escaped, got %q", got)
+		}
+	})
+
+	t.Run("base URL", func(t *testing.T) {
+		g := newRouter()
+		if err := g.SetBaseURL("https://example.com/app/"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got, err := g.AbsoluteURL(nil, "user", "id", "1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != "https://example.com/app/users/1" {
+			t.Errorf("unexpected URL %q", got)
+		}
+		if err := g.SetBaseURL("/relative"); err == nil {
+			t.Error("expected error for relative base URL")
+		}
+	})
+
+	t.Run("no request", func(t *testing.T) {
+		if _, err := newRouter().AbsoluteURL(nil, "user", "id", "1"); err == nil {
+			t.Error("expected error without request or base URL")
+		}
+	})
+}
@@ -0,0 +1,200 @@
+package groute
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Usage accounts request and response body bytes per route and per key,
+// such as a tenant or API key, for usage-based billing:
+//
+//	usage := groute.NewUsage(groute.KeyByHeader("X-API-Key"))
+//	api.Use(usage.Middleware())
+//	admin.Get("/usage", usage.Handler().ServeHTTP) // GET /usage?key=acme
+//
+// Like Metrics, records are updated with atomic operations and never take
+// a lock on the hot path.
+//
+// Keys often come from clients, so the number of keys tracked is bounded
+// by MaxKeys: once it is reached, requests with new keys are accounted
+// under UsageOverflowKey. Records are never expired, since they feed
+// billing.
+type Usage struct {
+	// MaxKeys is the maximum number of distinct keys tracked, 10000 if
+	// zero. Set it before the middleware serves requests.
+	MaxKeys int
+
+	key     KeyFunc
+	keys    sync.Map // key -> struct{}
+	nkeys   atomic.Int64
+	records sync.Map // usageKey -> *usageRecord
+}
+
+// UsageOverflowKey is the key under which Usage accounts requests with
+// keys beyond its MaxKeys.
+const UsageOverflowKey = "(overflow)"
+
+// defaultUsageMaxKeys is the default of Usage.MaxKeys.
+const defaultUsageMaxKeys = 10000
+
+// usageKey identifies a usage record.
+type usageKey struct {
+	pattern, key string
+}
+
+type usageRecord struct {
+	requests atomic.Uint64
+	bytesIn  atomic.Uint64
+	bytesOut atomic.Uint64
+}
+
+// UsageStats is a snapshot of the usage of a route by a key.
+type UsageStats struct {
+	Pattern  string `json:"pattern"`
+	Key      string `json:"key"`
+	Requests uint64 `json:"requests"`
+	// BytesIn counts request body bytes read by the handler.
+	BytesIn uint64 `json:"bytes_in"`
+	// BytesOut counts response body bytes written.
+	BytesOut uint64 `json:"bytes_out"`
+}
+
+// NewUsage creates a Usage collector keying requests with key. Requests
+// for which key returns "" are accounted under the empty key.
+func NewUsage(key KeyFunc) *Usage {
+	return &Usage{key: key}
+}
+
+// Middleware returns a middleware accounting the requests of matched routes.
+func (u *Usage) Middleware() Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			key := u.admit(u.key(r))
+			var body *countingBody
+			if r.Body != nil && r.Body != http.NoBody {
+				body = &countingBody{ReadCloser: r.Body}
+				r = shallowCopyRequest(r)
+				r.Body = body
+			}
+			rw := WrapResponseWriter(w)
+			next(rw, r)
+
+			_, pattern := matchedRoute(r)
+			rec := u.lookup(usageKey{pattern, key})
+			rec.requests.Add(1)
+			rec.bytesOut.Add(uint64(rw.Size()))
+			if body != nil {
+				rec.bytesIn.Add(uint64(body.n.Load()))
+			}
+		}
+	}
+}
+
+// admit returns key if it is tracked or there is room for it, and
+// UsageOverflowKey otherwise.
+func (u *Usage) admit(key string) string {
+	if _, ok := u.keys.Load(key); ok {
+		return key
+	}
+	limit := u.MaxKeys
+	if limit <= 0 {
+		limit = defaultUsageMaxKeys
+	}
+	if u.nkeys.Add(1) > int64(limit) {
+		u.nkeys.Add(-1)
+		return UsageOverflowKey
+	}
+	if _, loaded := u.keys.LoadOrStore(key, struct{}{}); loaded {
+		u.nkeys.Add(-1)
+	}
+	return key
+}
+
+// lookup returns the record for k, creating it on first use.
+func (u *Usage) lookup(k usageKey) *usageRecord {
+	if rec, ok := u.records.Load(k); ok {
+		return rec.(*usageRecord)
+	}
+	rec, _ := u.records.LoadOrStore(k, &usageRecord{})
+	return rec.(*usageRecord)
+}
+
+// Stats returns the usage of every route and key seen, sorted by key and
+// pattern. If keys are given, only their usage is returned.
+func (u *Usage) Stats(keys ...string) []UsageStats {
+	var stats []UsageStats
+	u.records.Range(func(k, v any) bool {
+		uk, rec := k.(usageKey), v.(*usageRecord)
+		if len(keys) > 0 && !slices.Contains(keys, uk.key) {
+			return true
+		}
+		stats = append(stats, UsageStats{
+			Pattern:  uk.pattern,
+			Key:      uk.key,
+			Requests: rec.requests.Load(),
+			BytesIn:  rec.bytesIn.Load(),
+			BytesOut: rec.bytesOut.Load(),
+		})
+		return true
+	})
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Key != stats[j].Key {
+			return stats[i].Key < stats[j].Key
+		}
+		return stats[i].Pattern < stats[j].Pattern
+	})
+	return stats
+}
+
+// Handler returns a handler serving Stats as JSON. The key query parameter,
+// which may be repeated, restricts the result to the given keys.
+func (u *Usage) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stats := u.Stats(r.URL.Query()["key"]...)
+		if stats == nil {
+			stats = []UsageStats{}
+		}
+		_ = JSON(w, r, http.StatusOK, stats)
+	})
+}
+
+// WritePrometheus writes the usage in the Prometheus text format, labeled
+// by pattern and key. Every key becomes a series, so keep keys bounded,
+// e.g. tenants rather than users.
+func (u *Usage) WritePrometheus(w io.Writer) error {
+	stats := u.Stats()
+	var b strings.Builder
+	family := func(name, help string, value func(s UsageStats) uint64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+		for _, s := range stats {
+			fmt.Fprintf(&b, "%s{pattern=\"%s\",key=\"%s\"} %d\n", name, escapeLabel(s.Pattern), escapeLabel(s.Key), value(s))
+		}
+	}
+	family("groute_usage_requests_total", "Requests per route and key.",
+		func(s UsageStats) uint64 { return s.Requests })
+	family("groute_usage_bytes_in_total", "Request body bytes read per route and key.",
+		func(s UsageStats) uint64 { return s.BytesIn })
+	family("groute_usage_bytes_out_total", "Response body bytes written per route and key.",
+		func(s UsageStats) uint64 { return s.BytesOut })
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// countingBody counts the bytes read from a request body.
+type countingBody struct {
+	io.ReadCloser
+	n atomic.Int64
+}
+
+// Read implements io.Reader.
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,111 @@
+package groute
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUsage(t *testing.T) {
+	usage := NewUsage(KeyByHeader("X-API-Key"))
+	g := NewRouter()
+	api := g.Group("/api")
+	api.Use(usage.Middleware())
+	api.Post("/upload", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		w.Write([]byte("stored " + string(data)))
+	})
+	api.Get("/items", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("[]"))
+	})
+	g.Get("/usage", usage.Handler().ServeHTTP)
+
+	send := func(method, path, key, body string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-API-Key", key)
+		g.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	send("POST", "/api/upload", "acme", "12345")
+	send("POST", "/api/upload", "acme", "123")
+	send("GET", "/api/items", "acme", "")
+	send("GET", "/api/items", "globex", "")
+
+	want := []UsageStats{
+		{Pattern: "GET /api/items", Key: "acme", Requests: 1, BytesOut: 2},
+		{Pattern: "POST /api/upload", Key: "acme", Requests: 2, BytesIn: 8, BytesOut: 22},
+		{Pattern: "GET /api/items", Key: "globex", Requests: 1, BytesOut: 2},
+	}
+	got := usage.Stats()
+	if len(got) != len(want) {
+		t.Fatalf("Stats = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Stats[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/usage?key=globex", nil))
+	var queried []UsageStats
+	if err := json.Unmarshal(w.Body.Bytes(), &queried); err != nil {
+		t.Fatal(err)
+	}
+	if len(queried) != 1 || queried[0] != want[2] {
+		t.Errorf("queried = %+v, want %+v", queried, want[2:])
+	}
+
+	var b strings.Builder
+	if err := usage.WritePrometheus(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`groute_usage_bytes_in_total{pattern="POST /api/upload",key="acme"} 8`,
+		`groute_usage_bytes_out_total{pattern="GET /api/items",key="globex"} 2`,
+	} {
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("expected output to contain %q, got:\n%s", line, b.String())
+		}
+	}
+}
+
+func TestUsageMaxKeys(t *testing.T) {
+	usage := NewUsage(KeyByHeader("X-API-Key"))
+	usage.MaxKeys = 2
+	outer := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			body := r.Body
+			next(w, r)
+			if r.Body != body {
+				t.Errorf("the caller's request body was replaced")
+			}
+		}
+	}
+	g := NewRouter()
+	g.Use(outer, usage.Middleware())
+	g.Post("/upload", func(w http.ResponseWriter, r *http.Request) { io.ReadAll(r.Body) })
+
+	for _, key := range []string{"a", "b", "c", "a", "d"} {
+		req := httptest.NewRequest("POST", "/upload", strings.NewReader("x"))
+		req.Header.Set("X-API-Key", key)
+		g.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	want := []UsageStats{
+		{Pattern: "POST /upload", Key: UsageOverflowKey, Requests: 2, BytesIn: 2},
+		{Pattern: "POST /upload", Key: "a", Requests: 2, BytesIn: 2},
+		{Pattern: "POST /upload", Key: "b", Requests: 1, BytesIn: 1},
+	}
+	got := usage.Stats()
+	if len(got) != len(want) {
+		t.Fatalf("Stats() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Stats()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package groute
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// User-agent classes assigned by ClassifyUserAgent.
+const (
+	UserAgentBot     = "bot"
+	UserAgentMobile  = "mobile"
+	UserAgentDesktop = "desktop"
+)
+
+// UserAgentAnnotation is the annotation UserAgentIs reads. Install a custom
+// classifier under this name to replace ClassifyUserAgent:
+//
+//	r.Classify(groute.UserAgentAnnotation, myClassifier)
+const UserAgentAnnotation = "user-agent"
+
+// botTokens and mobileTokens are lowercase User-Agent substrings.
+var (
+	botTokens = []string{
+		"bot", "crawl", "spider", "slurp", "facebookexternalhit", "preview",
+		"headless", "lighthouse", "curl/", "wget/", "python-", "go-http-client", "java/",
+	}
+	mobileTokens = []string{"mobi", "android", "iphone", "ipod", "ipad", "windows phone", "blackberry"}
+)
+
+// ClassifyUserAgent is a RequestClassifier sorting requests into
+// UserAgentBot, UserAgentMobile or UserAgentDesktop by their User-Agent
+// header. Crawlers, link previewers, headless browsers, HTTP libraries and
+// requests without a User-Agent count as bots. It is a heuristic; plug in a
+// dedicated parser for finer classes.
+func ClassifyUserAgent(r *http.Request) string {
+	ua := strings.ToLower(r.Header.Get("User-Agent"))
+	switch {
+	case ua == "" || containsAny(ua, botTokens):
+		return UserAgentBot
+	case containsAny(ua, mobileTokens):
+		return UserAgentMobile
+	default:
+		return UserAgentDesktop
+	}
+}
+
+// UserAgentIs returns a condition holding for requests in one of classes,
+// e.g. to serve prerendered pages to crawlers on the pattern of a
+// single-page app:
+//
+//	r.When(groute.UserAgentIs(groute.UserAgentBot)).Get("/app/{path...}", prerendered)
+//	r.Get("/app/{path...}", spa)
+//
+// The class is the UserAgentAnnotation if a classifier set one, and
+// ClassifyUserAgent's otherwise.
+func UserAgentIs(classes ...string) Condition {
+	annotated := Annotated(UserAgentAnnotation, classes...)
+	return func(r *http.Request) bool {
+		if Annotation(r, UserAgentAnnotation) != "" {
+			return annotated(r)
+		}
+		return slices.Contains(classes, ClassifyUserAgent(r))
+	}
+}
+
+// containsAny reports whether s contains any of substrs.
+func containsAny(s string, substrs []string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package groute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClassifyUserAgent(t *testing.T) {
+	tests := []struct {
+		ua    string
+		class string
+	}{
+		{"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", UserAgentBot},
+		{"facebookexternalhit/1.1", UserAgentBot},
+		{"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) HeadlessChrome/120.0 Safari/537.36", UserAgentBot},
+		{"curl/8.4.0", UserAgentBot},
+		{"", UserAgentBot},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148", UserAgentMobile},
+		{"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 Chrome/120.0 Mobile Safari/537.36", UserAgentMobile},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36", UserAgentDesktop},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15 Version/17.0 Safari/605.1.15", UserAgentDesktop},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("User-Agent", tt.ua)
+		if got := ClassifyUserAgent(req); got != tt.class {
+			t.Errorf("ClassifyUserAgent(%q) = %q, want %q", tt.ua, got, tt.class)
+		}
+	}
+}
+
+func TestUserAgentIs(t *testing.T) {
+	g := NewRouter()
+	g.Get("/app/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("prerendered"))
+	}).When(UserAgentIs(UserAgentBot))
+	g.Get("/app/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("spa"))
+	})
+
+	serve := func(ua string) string {
+		req := httptest.NewRequest("GET", "/app/docs/intro", nil)
+		req.Header.Set("User-Agent", ua)
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+	if got := serve("Googlebot/2.1"); got != "prerendered" {
+		t.Errorf("crawler got %q, want prerendered", got)
+	}
+	if got := serve("Mozilla/5.0 (Windows NT 10.0) Chrome/120.0"); got != "spa" {
+		t.Errorf("browser got %q, want spa", got)
+	}
+
+	// A custom classifier replaces the built-in one.
+	g.Classify(UserAgentAnnotation, func(r *http.Request) string {
+		if strings.Contains(r.Header.Get("User-Agent"), "InternalRenderer") {
+			return UserAgentBot
+		}
+		return UserAgentDesktop
+	})
+	if got := serve("InternalRenderer/1.0"); got != "prerendered" {
+		t.Errorf("custom bot got %q, want prerendered", got)
+	}
+	if got := serve("Googlebot/2.1"); got != "spa" {
+		t.Errorf("custom classifier: Googlebot got %q, want spa", got)
+	}
+}
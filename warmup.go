@@ -0,0 +1,136 @@
+package groute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Warmup gates readiness on startup tasks such as pinging the database or
+// filling a cache. Serve it as the readiness probe; it reports 503 Service
+// Unavailable until every task has succeeded:
+//
+//	var warmup groute.Warmup
+//	warmup.Add("db", db.PingContext)
+//	warmup.Add("cache", fillCache)
+//	r.Get("/readyz", warmup.ServeHTTP)
+//	go warmup.Run(ctx)
+//
+// To not accept traffic at all before warming up, call Run before starting
+// the server instead. The zero value is ready to use.
+type Warmup struct {
+	// Timeout bounds a Run. Zero means no timeout.
+	Timeout time.Duration
+	// Logger receives progress messages. If nil, slog.Default() is used.
+	Logger *slog.Logger
+
+	run   sync.Mutex // serializes Run
+	mu    sync.Mutex
+	tasks []*warmupTask
+}
+
+// warmupTask is a task registered with Warmup.Add.
+type warmupTask struct {
+	name string
+	fn   func(ctx context.Context) error
+	done bool
+}
+
+// WarmupStatus is the body of the readiness probe.
+type WarmupStatus struct {
+	Ready   bool     `json:"ready"`
+	Pending []string `json:"pending,omitempty"`
+}
+
+// Add registers a task that must succeed before the server is ready.
+func (w *Warmup) Add(name string, task func(ctx context.Context) error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.tasks = append(w.tasks, &warmupTask{name: name, fn: task})
+}
+
+// Run runs the pending tasks concurrently and returns the errors of those
+// that failed. Failed tasks stay pending, so Run may be called again to
+// retry them. Concurrent calls run one after another, so a task is never
+// run twice at once and a task that succeeded is not run again.
+func (w *Warmup) Run(ctx context.Context) error {
+	w.run.Lock()
+	defer w.run.Unlock()
+	if w.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.Timeout)
+		defer cancel()
+	}
+	logger := w.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	w.mu.Lock()
+	var pending []*warmupTask
+	for _, task := range w.tasks {
+		if !task.done {
+			pending = append(pending, task)
+		}
+	}
+	w.mu.Unlock()
+
+	errs := make([]error, len(pending))
+	var wg sync.WaitGroup
+	for i, task := range pending {
+		wg.Go(func() {
+			start := time.Now()
+			if err := task.fn(ctx); err != nil {
+				errs[i] = fmt.Errorf("groute: warmup %q: %w", task.name, err)
+				logger.LogAttrs(ctx, slog.LevelError, "warmup task failed",
+					slog.String("task", task.name), slog.Duration("duration", time.Since(start)), slog.Any("error", err))
+				return
+			}
+			w.mu.Lock()
+			task.done = true
+			w.mu.Unlock()
+			logger.LogAttrs(ctx, slog.LevelInfo, "warmup task done",
+				slog.String("task", task.name), slog.Duration("duration", time.Since(start)))
+		})
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	logger.LogAttrs(ctx, slog.LevelInfo, "warmup complete", slog.Int("tasks", len(pending)))
+	return nil
+}
+
+// Status reports whether all tasks succeeded, and which are pending.
+func (w *Warmup) Status() WarmupStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var s WarmupStatus
+	for _, task := range w.tasks {
+		if !task.done {
+			s.Pending = append(s.Pending, task.name)
+		}
+	}
+	s.Ready = len(s.Pending) == 0
+	return s
+}
+
+// Ready reports whether all tasks succeeded.
+func (w *Warmup) Ready() bool {
+	return w.Status().Ready
+}
+
+// ServeHTTP serves the readiness probe: the Status as JSON, with 200 OK
+// once ready and 503 Service Unavailable before.
+func (w *Warmup) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	s := w.Status()
+	code := http.StatusOK
+	if !s.Ready {
+		code = http.StatusServiceUnavailable
+	}
+	_ = JSON(rw, r, code, s)
+}
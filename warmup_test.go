@@ -0,0 +1,97 @@
+package groute
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWarmup(t *testing.T) {
+	var logs strings.Builder
+	w := &Warmup{Logger: slog.New(slog.NewTextHandler(&logs, nil))}
+	cacheErr := errors.New("cache unavailable")
+	w.Add("db", func(ctx context.Context) error { return nil })
+	w.Add("cache", func(ctx context.Context) error { return cacheErr })
+
+	g := NewRouter()
+	g.Get("/readyz", w.ServeHTTP)
+	probe := func() (int, string) {
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+		return rec.Code, strings.TrimSpace(rec.Body.String())
+	}
+
+	if code, body := probe(); code != http.StatusServiceUnavailable || body != `{"ready":false,"pending":["db","cache"]}` {
+		t.Errorf("before Run: %d %s", code, body)
+	}
+
+	if err := w.Run(context.Background()); !errors.Is(err, cacheErr) {
+		t.Errorf("Run = %v, want %v", err, cacheErr)
+	}
+	if code, body := probe(); code != http.StatusServiceUnavailable || body != `{"ready":false,"pending":["cache"]}` {
+		t.Errorf("after failed Run: %d %s", code, body)
+	}
+
+	// A retry only runs the pending task.
+	w.tasks[1].fn = func(ctx context.Context) error { return nil }
+	w.tasks[0].fn = func(ctx context.Context) error { return errors.New("ran twice") }
+	if err := w.Run(context.Background()); err != nil {
+		t.Errorf("Run = %v", err)
+	}
+	if code, body := probe(); code != http.StatusOK || body != `{"ready":true}` {
+		t.Errorf("after Run: %d %s", code, body)
+	}
+	for _, msg := range []string{`msg="warmup task done" task=db`, `msg="warmup task failed" task=cache`, `msg="warmup complete" tasks=1`} {
+		if !strings.Contains(logs.String(), msg) {
+			t.Errorf("logs missing %q:\n%s", msg, logs.String())
+		}
+	}
+}
+
+func TestWarmupTimeout(t *testing.T) {
+	w := &Warmup{Timeout: 10 * time.Millisecond, Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	w.Add("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err := w.Run(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if w.Ready() {
+		t.Error("Ready after a timed out task")
+	}
+}
+
+func TestWarmupConcurrentRun(t *testing.T) {
+	w := &Warmup{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	var calls atomic.Int32
+	w.Add("db", func(ctx context.Context) error {
+		calls.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			if err := w.Run(context.Background()); err != nil {
+				t.Errorf("Run = %v", err)
+			}
+		})
+	}
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Errorf("task ran %d times, want 1", n)
+	}
+	if !w.Ready() {
+		t.Error("not Ready after Run")
+	}
+}
@@ -0,0 +1,114 @@
+package groute
+
+import (
+	"io/fs"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Robots registers GET /robots.txt serving content as text/plain. Routes
+// marked with NoIndex are appended as Disallow rules for all user agents.
+//
+// Like the other site-level helpers, it is always registered at the site
+// root, even when called on a group, so it is not shadowed by wildcard routes.
+func (g *Router) Robots(content string) *Route {
+	root := g.root()
+	return root.Get("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte(content + root.disallowRules()))
+	})
+}
+
+// NoIndex keeps the route out of search engines: responses carry an
+// X-Robots-Tag: noindex header, and the robots.txt served by Robots
+// disallows the route's path.
+func (rt *Route) NoIndex() *Route {
+	rt.update(func(info *routeInfo) {
+		info.noIndex = true
+	})
+	return rt
+}
+
+// disallowRules returns a robots.txt group disallowing the enabled GET
+// routes marked with NoIndex, or "" if there are none.
+func (g *Router) disallowRules() string {
+	var paths []string
+	for _, route := range g.Routes() {
+		info := route.info.Load()
+		if !info.noIndex || info.disabled || route.method != "" && route.method != http.MethodGet && route.method != http.MethodHead {
+			continue
+		}
+		if path := robotsPath(route.Path()); path != "" && !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return ""
+	}
+	sort.Strings(paths)
+	var b strings.Builder
+	b.WriteString("\nUser-agent: *\n")
+	for _, path := range paths {
+		b.WriteString("Disallow: " + path + "\n")
+	}
+	return b.String()
+}
+
+// robotsPath converts a route path into a robots.txt path rule: wildcards
+// become "*" and paths matched exactly end with "$". Since "*" also matches
+// slashes, the rule may cover slightly more than the route. Paths with a
+// host are skipped, as robots.txt is per host.
+func robotsPath(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		return ""
+	}
+	var b strings.Builder
+	exact := !strings.HasSuffix(path, "/")
+	for {
+		start := strings.IndexByte(path, '{')
+		end := strings.IndexByte(path, '}')
+		if start < 0 || end < start {
+			b.WriteString(path)
+			break
+		}
+		b.WriteString(path[:start])
+		switch name := path[start+1 : end]; {
+		case name == "$":
+			b.WriteString("$")
+			exact = false
+		case strings.HasSuffix(name, "..."):
+			exact = false
+		default:
+			b.WriteString("*")
+		}
+		path = path[end+1:]
+	}
+	if exact {
+		b.WriteString("$")
+	}
+	return b.String()
+}
+
+// Favicon registers GET /favicon.ico serving the file name from fsys.
+func (g *Router) Favicon(fsys fs.FS, name string) *Route {
+	return g.root().Get("/favicon.ico", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFileFS(w, r, fsys, name)
+	})
+}
+
+// WellKnown registers a GET handler under /.well-known/, e.g.
+// WellKnown("security.txt", h) serves /.well-known/security.txt.
+// The name may contain path parameters.
+func (g *Router) WellKnown(name string, handler http.HandlerFunc) *Route {
+	return g.root().Get("/.well-known/"+strings.TrimLeft(name, "/"), handler)
+}
+
+// root returns the top-level router g belongs to.
+func (g *Router) root() *Router {
+	for g.parent != nil {
+		g = g.parent
+	}
+	return g
+}
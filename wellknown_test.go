@@ -0,0 +1,106 @@
+package groute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSiteHelpersWithWildcard(t *testing.T) {
+	g := NewRouter()
+	api := g.Group("/app")
+
+	fsys := fstest.MapFS{"icons/favicon.ico": {Data: []byte("ICON")}}
+	g.Get("/{pathname...}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("spa"))
+	})
+	api.Robots("User-agent: *\nDisallow:\n")
+	g.Favicon(fsys, "icons/favicon.ico")
+	g.WellKnown("security.txt", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("Contact: mailto:security@example.com"))
+	})
+	g.WellKnown("/acme-challenge/{token}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.PathValue("token")))
+	})
+
+	tests := []struct {
+		path         string
+		expectedBody string
+	}{
+		{"/robots.txt", "User-agent: *\nDisallow:\n"},
+		{"/favicon.ico", "ICON"},
+		{"/.well-known/security.txt", "Contact: mailto:security@example.com"},
+		{"/.well-known/acme-challenge/abc", "abc"},
+		{"/other", "spa"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("expected status 200, got %d", w.Code)
+			}
+			if w.Body.String() != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRobotsContentType(t *testing.T) {
+	g := NewRouter()
+	g.Robots("User-agent: *\n")
+
+	req := httptest.NewRequest("GET", "/robots.txt", nil)
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+		t.Errorf("expected text/plain content type, got %q", ct)
+	}
+}
+
+func TestNoIndex(t *testing.T) {
+	g := NewRouter()
+	g.Robots("User-agent: *\nAllow: /\n")
+	g.Get("/public", func(w http.ResponseWriter, r *http.Request) {})
+	g.Get("/drafts/{id}", func(w http.ResponseWriter, r *http.Request) {}).NoIndex()
+	g.Get("/preview/", func(w http.ResponseWriter, r *http.Request) {}).NoIndex()
+	g.Get("/admin/{path...}", func(w http.ResponseWriter, r *http.Request) {}).NoIndex()
+	g.Get("/internal", func(w http.ResponseWriter, r *http.Request) {}).NoIndex().SetEnabled(false)
+	g.Post("/search", func(w http.ResponseWriter, r *http.Request) {}).NoIndex()
+	g.Get("/{$}", func(w http.ResponseWriter, r *http.Request) {}).NoIndex()
+
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/robots.txt", nil))
+	want := "User-agent: *\nAllow: /\n" +
+		"\nUser-agent: *\n" +
+		"Disallow: /$\n" +
+		"Disallow: /admin/\n" +
+		"Disallow: /drafts/*$\n" +
+		"Disallow: /preview/\n"
+	if w.Body.String() != want {
+		t.Errorf("robots.txt = %q, want %q", w.Body.String(), want)
+	}
+
+	tests := []struct {
+		path string
+		tag  string
+	}{
+		{"/drafts/1", "noindex"},
+		{"/admin/users", "noindex"},
+		{"/public", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+			if got := w.Header().Get("X-Robots-Tag"); got != tt.tag {
+				t.Errorf("X-Robots-Tag = %q, want %q", got, tt.tag)
+			}
+		})
+	}
+}